@@ -1,25 +1,44 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/address"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models/cart"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models/category"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/coupon"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/currency"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/idempotency"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/invoice"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models/item"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/notification"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models/order"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/outbox"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/payment"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models/product"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/promotion"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/reservation"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models/response"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/scheduler"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/shipping"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/tax"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models/user"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/webhook"
 	"github.com/cagrikilicoglu/shopping-basket/pkg/auth"
 	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
 	"github.com/cagrikilicoglu/shopping-basket/pkg/database"
 	"github.com/cagrikilicoglu/shopping-basket/pkg/graceful"
 	"github.com/cagrikilicoglu/shopping-basket/pkg/logging"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/mailer"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/pagination"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"golang.org/x/crypto/bcrypt"
@@ -49,6 +68,20 @@ func Execute() {
 		log.Fatalf("Loadconfig failed, %v", err)
 	}
 
+	// Reload the business rules when the configuration file changes
+	rules := config.NewRules(cfg.BusinessRulesConfig)
+	rules.OnChange(func(b config.BusinessRulesConfig) {
+		pagination.SetMaxPageSize(b.MaxPageSize)
+	})
+	if err := rules.Watch(configFile); err != nil {
+		log.Fatalf("Business rules cannot be watched, %v", err)
+	}
+
+	// Set the base currency that the prices are kept in
+	if cfg.CurrencyConfig.Base != "" {
+		money.DefaultCurrency = strings.ToUpper(cfg.CurrencyConfig.Base)
+	}
+
 	// Set a globalLogger
 	logging.NewZapLogger(cfg)
 	defer logging.Close()
@@ -65,9 +98,22 @@ func Execute() {
 
 	log.Println("Postgress connected")
 
+	// Deliver the domain events recorded in the outbox
+	outboxRepo := outbox.NewOutboxRepository(db)
+	outboxRepo.Migration()
+	dispatcher := outbox.NewDispatcher(outboxRepo, cfg.OutboxConfig)
+	dispatcher.Register("log", outbox.LogConsumer)
+
+	// Run the periodic jobs, one instance at a time
+	schedulerRepo := scheduler.NewSchedulerRepository(db)
+	schedulerRepo.Migration()
+	jobs := scheduler.NewScheduler(schedulerRepo, cfg.SchedulerConfig)
+
 	router := gin.Default()
 	logging.NewGinLogger(router)
-	InitializeRoutes(router, cfg, db)
+	InitializeRoutes(router, cfg, rules, db, dispatcher, jobs)
+	dispatcher.Start()
+	jobs.Start()
 
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.ServerConfig.Port),
@@ -82,33 +128,51 @@ func Execute() {
 		}
 	}()
 
-	graceful.Shutdown(srv, time.Duration(int64(cfg.ServerConfig.ShutdownTimeoutSecs)*int64(time.Second)))
+	graceful.Shutdown(srv, time.Duration(int64(cfg.ServerConfig.ShutdownTimeoutSecs)*int64(time.Second)), jobs.Stop, dispatcher.Stop)
 }
 
 // InitializeRoutes initialize routers, handlers and repos
-func InitializeRoutes(router *gin.Engine, cfg *config.Config, db *gorm.DB) {
+func InitializeRoutes(router *gin.Engine, cfg *config.Config, rules *config.Rules, db *gorm.DB, dispatcher *outbox.Dispatcher, jobs *scheduler.Scheduler) {
 
 	logging.NewGinLogger(router)
 
 	baseRouter := router.Group(cfg.ServerConfig.RoutePrefix)
+
+	rateRepo := currency.NewExchangeRateRepository(db)
+	rateRepo.Migration()
+	baseRouter.Use(currency.Middleware(rateRepo))
+	currency.NewExchangeRateHandler(baseRouter, rateRepo, cfg)
+
 	productRouter := baseRouter.Group("/products")
 	categoryRouter := baseRouter.Group("/categories")
 	cartRouter := baseRouter.Group("/cart")
 	baseRouter.GET("/health", checkHealth)
 
+	taxCalculator, err := tax.NewCalculator(cfg.TaxConfig)
+	if err != nil {
+		log.Fatalf("Tax calculator cannot be created, %v", err)
+	}
+
+	reservationRepo := reservation.NewReservationRepository(db)
+	reservationRepo.Migration()
+	reservations := reservation.NewReservations(reservationRepo, cfg.ReservationConfig)
+
 	productRepo := product.NewProductRepository(db)
 	productRepo.Migration()
-	product.NewProductHandler(productRouter, productRepo, cfg)
+	product.NewProductHandler(productRouter, productRepo, taxCalculator, reservations, cfg)
 
 	categoryRepo := category.NewCategoryRepository(db)
 	categoryRepo.Migration()
-	category.NewCategoryHandler(categoryRouter, categoryRepo, cfg)
+	category.NewCategoryHandler(categoryRouter, categoryRepo, taxCalculator, cfg)
 
 	auth := auth.NewAuthenticator(cfg)
 
 	userRepo := user.NewUserRepository(db)
 	userRepo.Migration()
-	user.NewUserHandler(baseRouter, userRepo, auth)
+
+	addressRepo := address.NewAddressRepository(db)
+	addressRepo.Migration()
+	address.NewAddressHandler(baseRouter, addressRepo, cfg)
 
 	cartRepo := cart.NewCartRepository(db)
 	orderRepo := order.NewOrderRepository(db)
@@ -116,14 +180,105 @@ func InitializeRoutes(router *gin.Engine, cfg *config.Config, db *gorm.DB) {
 	cartRepo.Migration()
 	orderRepo.Migration()
 	itemRepo.Migration()
-	itemService := item.NewItemService(itemRepo, *productRepo)
-	cart.NewCartHandler(cartRouter, cartRepo, itemService, cfg)
-	order.NewOrderHandler(baseRouter, orderRepo, cartRepo, itemService, cfg)
+
+	couponRepo := coupon.NewCouponRepository(db)
+	couponRepo.Migration()
+	coupons := coupon.NewCoupons(couponRepo)
+	coupon.NewCouponHandler(baseRouter, couponRepo, cfg)
+
+	promotionRepo := promotion.NewPromotionRepository(db)
+	promotionRepo.Migration()
+	promotions := promotion.NewPromotions(promotionRepo)
+	promotion.NewPromotionHandler(baseRouter, promotionRepo, cfg)
+
+	itemService := item.NewItemService(itemRepo, *productRepo, taxCalculator, reservations, coupons, promotions)
+
+	idempotencyRepo := idempotency.NewIdempotencyRepository(db)
+	idempotencyRepo.Migration()
+	idempotent := idempotency.Middleware(idempotencyRepo, cfg.IdempotencyConfig)
+
+	shippingCalculator, err := shipping.NewCalculator(cfg.ShippingConfig)
+	if err != nil {
+		log.Fatalf("Shipping calculator cannot be created, %v", err)
+	}
+
+	guestCarts := cart.NewGuestCarts(cartRepo, itemService, reservations, rules, cfg.GuestCartConfig)
+	cart.NewCartHandler(cartRouter, cartRepo, itemService, addressRepo, shippingCalculator, taxCalculator, rules, reservations, guestCarts, coupons, idempotent, cfg)
+	user.NewUserHandler(baseRouter, userRepo, auth, rules, guestCarts)
+	paymentRepo := payment.NewPaymentRepository(db)
+	paymentRepo.Migration()
+	paymentGateway, err := payment.NewPaymentGateway(cfg, os.Getenv("APP_ENV"))
+	if err != nil {
+		log.Fatalf("Payment gateway cannot be created, %v", err)
+	}
+	paymentService := payment.NewPaymentService(paymentRepo, paymentGateway)
+	dispatcher.Register("payment", paymentService.Consume)
+
+	invoiceRepo := invoice.NewInvoiceRepository(db, cfg.InvoiceConfig.NumberPrefix)
+	invoiceRepo.Migration()
+
+	orderLifecycle := order.NewLifecycle(orderRepo, productRepo, paymentService)
+	order.NewOrderHandler(baseRouter, orderRepo, cartRepo, itemService, orderLifecycle, paymentService, addressRepo, shippingCalculator, taxCalculator, invoiceRepo, coupons, rules, idempotent, cfg)
+
+	notificationRepo := notification.NewNotificationRepository(db)
+	notificationRepo.Migration()
+	mail, err := mailer.New(cfg.MailerConfig)
+	if err != nil {
+		log.Fatalf("Mailer cannot be created, %v", err)
+	}
+	// emails are registered before webhooks, so a failing webhook does not hold back the emails of the customers
+	dispatcher.Register("email", notification.NewNotifier(notificationRepo, mail, cfg.InvoiceConfig.SellerName).Consume)
+
+	webhookRepo := webhook.NewWebhookRepository(db)
+	webhookRepo.Migration()
+	webhookNotifier := webhook.NewNotifier(webhookRepo, webhook.NewSender(cfg.WebhookConfig))
+	webhook.NewWebhookHandler(baseRouter, webhookRepo, webhookNotifier, cfg)
+	dispatcher.Register("webhook", webhookNotifier.Consume)
+
+	registerJobs(jobs, cfg.SchedulerConfig, orderLifecycle, cartRepo, itemService, reservations)
+	scheduler.NewSchedulerHandler(baseRouter, jobs, cfg)
 
 	// Remove after first usage
 	CreateAdmin(userRepo)
 }
 
+// registerJobs registers the periodic jobs to the scheduler
+// note that the jobs without a schedule in SchedulerConfig are disabled
+func registerJobs(jobs *scheduler.Scheduler, cfg config.SchedulerConfig, lifecycle *order.Lifecycle, cartRepo *cart.CartRepository, itemService item.Service, reservations *reservation.Reservations) {
+	register := func(name string, run scheduler.JobFunc) {
+		if err := jobs.Register(name, run); err != nil {
+			log.Fatalf("Job cannot be registered, %v", err)
+		}
+	}
+
+	// a missing limit would expire every order and cart, so the limits fall back to a day and a month
+	unpaidTimeout := time.Duration(cfg.UnpaidOrderTimeoutMins) * time.Minute
+	if unpaidTimeout <= 0 {
+		unpaidTimeout = 24 * time.Hour
+	}
+	staleCartDays := cfg.StaleCartDays
+	if staleCartDays <= 0 {
+		staleCartDays = 30
+	}
+
+	register("expire-unpaid-orders", func(ctx context.Context) (string, error) {
+		n, err := lifecycle.ExpireUnpaid(ctx, time.Now().Add(-unpaidTimeout))
+		return fmt.Sprintf("%d unpaid orders canceled", n), err
+	})
+	register("purge-stale-carts", func(ctx context.Context) (string, error) {
+		n, err := cartRepo.PurgeStale(time.Now().AddDate(0, 0, -staleCartDays))
+		return fmt.Sprintf("%d stale carts emptied", n), err
+	})
+	register("recompute-cart-totals", func(ctx context.Context) (string, error) {
+		n, err := cartRepo.RecomputeTotals(ctx, itemService.Price)
+		return fmt.Sprintf("%d cart totals corrected", n), err
+	})
+	register("release-expired-reservations", func(ctx context.Context) (string, error) {
+		n, err := reservations.ReleaseExpired()
+		return fmt.Sprintf("%d expired reservations released", n), err
+	})
+}
+
 func checkHealth(c *gin.Context) {
 	response.RespondWithJson(c, http.StatusOK, nil)
 }
@@ -20,27 +20,57 @@ import (
 // swagger:model Cart
 type Cart struct {
 
+	// reason why the coupon applied to the cart gives no discount, such as an expired coupon or a cart below its minimum basket
+	CouponMessage string `json:"couponMessage,omitempty"`
+
+	// discounts of the promotions and the coupon applied to the cart, already taken off the total price except free shipping
+	Discounts []*DiscountLine `json:"discounts"`
+
 	// items
 	// Required: true
 	Items []*Item `json:"items"`
 
+	// estimated shipping to the default shipping address of the user, not included in the total price
+	Shipping *ShippingQuote `json:"shipping,omitempty"`
+
+	// tax of the items for the country of the default shipping address of the user
+	Tax *Money `json:"tax,omitempty"`
+
+	// whether the prices of the items and the total price include their tax
+	TaxIncluded bool `json:"taxIncluded,omitempty"`
+
 	// total price
 	// Required: true
-	TotalPrice *float32 `json:"totalPrice"`
+	TotalPrice *Money `json:"totalPrice"`
 
 	// user ID
 	// Required: true
 	UserID *string `json:"userID"`
+
+	// lines of the cart that have changed since they are added, such as a new price, a deleted product or a product without enough stock
+	Warnings []*CartWarning `json:"warnings"`
 }
 
 // Validate validates this cart
 func (m *Cart) Validate(formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.validateDiscounts(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateItems(formats); err != nil {
 		res = append(res, err)
 	}
 
+	if err := m.validateShipping(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateTax(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateTotalPrice(formats); err != nil {
 		res = append(res, err)
 	}
@@ -49,12 +79,42 @@ func (m *Cart) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateWarnings(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
 	return nil
 }
 
+func (m *Cart) validateDiscounts(formats strfmt.Registry) error {
+	if swag.IsZero(m.Discounts) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Discounts); i++ {
+		if swag.IsZero(m.Discounts[i]) { // not required
+			continue
+		}
+
+		if m.Discounts[i] != nil {
+			if err := m.Discounts[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("discounts" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("discounts" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
 func (m *Cart) validateItems(formats strfmt.Registry) error {
 
 	if err := validate.Required("items", "body", m.Items); err != nil {
@@ -82,12 +142,61 @@ func (m *Cart) validateItems(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Cart) validateShipping(formats strfmt.Registry) error {
+	if swag.IsZero(m.Shipping) { // not required
+		return nil
+	}
+
+	if m.Shipping != nil {
+		if err := m.Shipping.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("shipping")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("shipping")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Cart) validateTax(formats strfmt.Registry) error {
+	if swag.IsZero(m.Tax) { // not required
+		return nil
+	}
+
+	if m.Tax != nil {
+		if err := m.Tax.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("tax")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("tax")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (m *Cart) validateTotalPrice(formats strfmt.Registry) error {
 
 	if err := validate.Required("totalPrice", "body", m.TotalPrice); err != nil {
 		return err
 	}
 
+	if m.TotalPrice != nil {
+		if err := m.TotalPrice.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("totalPrice")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("totalPrice")
+			}
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -100,20 +209,86 @@ func (m *Cart) validateUserID(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Cart) validateWarnings(formats strfmt.Registry) error {
+	if swag.IsZero(m.Warnings) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Warnings); i++ {
+		if swag.IsZero(m.Warnings[i]) { // not required
+			continue
+		}
+
+		if m.Warnings[i] != nil {
+			if err := m.Warnings[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("warnings" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("warnings" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
 // ContextValidate validate this cart based on the context it is used
 func (m *Cart) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.contextValidateDiscounts(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.contextValidateItems(ctx, formats); err != nil {
 		res = append(res, err)
 	}
 
+	if err := m.contextValidateShipping(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateTax(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateTotalPrice(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateWarnings(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
 	return nil
 }
 
+func (m *Cart) contextValidateDiscounts(ctx context.Context, formats strfmt.Registry) error {
+
+	for i := 0; i < len(m.Discounts); i++ {
+
+		if m.Discounts[i] != nil {
+			if err := m.Discounts[i].ContextValidate(ctx, formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("discounts" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("discounts" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
 func (m *Cart) contextValidateItems(ctx context.Context, formats strfmt.Registry) error {
 
 	for i := 0; i < len(m.Items); i++ {
@@ -134,6 +309,74 @@ func (m *Cart) contextValidateItems(ctx context.Context, formats strfmt.Registry
 	return nil
 }
 
+func (m *Cart) contextValidateShipping(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.Shipping != nil {
+		if err := m.Shipping.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("shipping")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("shipping")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Cart) contextValidateTax(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.Tax != nil {
+		if err := m.Tax.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("tax")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("tax")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Cart) contextValidateTotalPrice(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.TotalPrice != nil {
+		if err := m.TotalPrice.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("totalPrice")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("totalPrice")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Cart) contextValidateWarnings(ctx context.Context, formats strfmt.Registry) error {
+
+	for i := 0; i < len(m.Warnings); i++ {
+
+		if m.Warnings[i] != nil {
+			if err := m.Warnings[i].ContextValidate(ctx, formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("warnings" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("warnings" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
 // MarshalBinary interface implementation
 func (m *Cart) MarshalBinary() ([]byte, error) {
 	if m == nil {
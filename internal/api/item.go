@@ -7,6 +7,7 @@ package api
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/go-openapi/errors"
 	"github.com/go-openapi/strfmt"
@@ -19,6 +20,12 @@ import (
 // swagger:model Item
 type Item struct {
 
+	// discount of the item, taken off its total price before its tax
+	Discount *Money `json:"discount,omitempty"`
+
+	// promotions and coupon that make up the discount of the item in the cart
+	Discounts []*DiscountLine `json:"discounts"`
+
 	// product
 	// Required: true
 	Product *Product `json:"product"`
@@ -27,15 +34,29 @@ type Item struct {
 	// Required: true
 	Quantity *uint32 `json:"quantity"`
 
+	// tax
+	Tax *Money `json:"tax,omitempty"`
+
+	// tax rate of the item as a percentage
+	TaxRate string `json:"taxRate,omitempty"`
+
 	// total price
 	// Required: true
-	TotalPrice *float32 `json:"totalPrice"`
+	TotalPrice *Money `json:"totalPrice"`
 }
 
 // Validate validates this item
 func (m *Item) Validate(formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.validateDiscount(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateDiscounts(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateProduct(formats); err != nil {
 		res = append(res, err)
 	}
@@ -44,6 +65,10 @@ func (m *Item) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateTax(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateTotalPrice(formats); err != nil {
 		res = append(res, err)
 	}
@@ -54,6 +79,51 @@ func (m *Item) Validate(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Item) validateDiscount(formats strfmt.Registry) error {
+	if swag.IsZero(m.Discount) { // not required
+		return nil
+	}
+
+	if m.Discount != nil {
+		if err := m.Discount.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("discount")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("discount")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Item) validateDiscounts(formats strfmt.Registry) error {
+	if swag.IsZero(m.Discounts) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Discounts); i++ {
+		if swag.IsZero(m.Discounts[i]) { // not required
+			continue
+		}
+
+		if m.Discounts[i] != nil {
+			if err := m.Discounts[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("discounts" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("discounts" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
 func (m *Item) validateProduct(formats strfmt.Registry) error {
 
 	if err := validate.Required("product", "body", m.Product); err != nil {
@@ -83,12 +153,42 @@ func (m *Item) validateQuantity(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Item) validateTax(formats strfmt.Registry) error {
+	if swag.IsZero(m.Tax) { // not required
+		return nil
+	}
+
+	if m.Tax != nil {
+		if err := m.Tax.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("tax")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("tax")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (m *Item) validateTotalPrice(formats strfmt.Registry) error {
 
 	if err := validate.Required("totalPrice", "body", m.TotalPrice); err != nil {
 		return err
 	}
 
+	if m.TotalPrice != nil {
+		if err := m.TotalPrice.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("totalPrice")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("totalPrice")
+			}
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -96,16 +196,68 @@ func (m *Item) validateTotalPrice(formats strfmt.Registry) error {
 func (m *Item) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.contextValidateDiscount(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateDiscounts(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.contextValidateProduct(ctx, formats); err != nil {
 		res = append(res, err)
 	}
 
+	if err := m.contextValidateTax(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateTotalPrice(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
 	return nil
 }
 
+func (m *Item) contextValidateDiscount(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.Discount != nil {
+		if err := m.Discount.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("discount")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("discount")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Item) contextValidateDiscounts(ctx context.Context, formats strfmt.Registry) error {
+
+	for i := 0; i < len(m.Discounts); i++ {
+
+		if m.Discounts[i] != nil {
+			if err := m.Discounts[i].ContextValidate(ctx, formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("discounts" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("discounts" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
 func (m *Item) contextValidateProduct(ctx context.Context, formats strfmt.Registry) error {
 
 	if m.Product != nil {
@@ -122,6 +274,38 @@ func (m *Item) contextValidateProduct(ctx context.Context, formats strfmt.Regist
 	return nil
 }
 
+func (m *Item) contextValidateTax(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.Tax != nil {
+		if err := m.Tax.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("tax")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("tax")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Item) contextValidateTotalPrice(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.TotalPrice != nil {
+		if err := m.TotalPrice.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("totalPrice")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("totalPrice")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 // MarshalBinary interface implementation
 func (m *Item) MarshalBinary() ([]byte, error) {
 	if m == nil {
@@ -20,36 +20,94 @@ import (
 // swagger:model Order
 type Order struct {
 
+	// billing address
+	BillingAddress *PostalAddress `json:"billingAddress,omitempty"`
+
+	// currency that the order is placed in
+	Currency string `json:"currency,omitempty"`
+
+	// customer
+	Customer *Customer `json:"customer,omitempty"`
+
 	// date
 	// Required: true
 	// Format: date
 	Date *strfmt.Date `json:"date"`
 
+	// discount of the order, included in the total price
+	Discount *Money `json:"discount,omitempty"`
+
+	// discounts
+	Discounts []*DiscountLine `json:"discounts"`
+
+	// exchange rate from the base currency to the currency of the order at checkout
+	ExchangeRate string `json:"exchangeRate,omitempty"`
+
 	// id
 	// Required: true
 	ID *string `json:"id"`
 
+	// number of the invoice issued for the order
+	InvoiceNumber string `json:"invoiceNumber,omitempty"`
+
 	// items
 	// Required: true
 	Items []*Item `json:"items"`
 
+	// payments
+	Payments []*Payment `json:"payments"`
+
+	// shipping of the order, included in the total price
+	Shipping *ShippingQuote `json:"shipping,omitempty"`
+
+	// shipping address
+	ShippingAddress *PostalAddress `json:"shippingAddress,omitempty"`
+
 	// status
 	// Required: true
 	Status *string `json:"status"`
 
+	// status history
+	StatusHistory []*OrderStatusChange `json:"statusHistory"`
+
+	// tax of the order, included in the total price
+	Tax *Money `json:"tax,omitempty"`
+
+	// whether the prices of the items include their tax
+	TaxIncluded bool `json:"taxIncluded,omitempty"`
+
+	// tax lines
+	TaxLines []*TaxLine `json:"taxLines"`
+
 	// total price
 	// Required: true
-	TotalPrice *float32 `json:"totalPrice"`
+	TotalPrice *Money `json:"totalPrice"`
 }
 
 // Validate validates this order
 func (m *Order) Validate(formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.validateBillingAddress(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateCustomer(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateDate(formats); err != nil {
 		res = append(res, err)
 	}
 
+	if err := m.validateDiscount(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateDiscounts(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateID(formats); err != nil {
 		res = append(res, err)
 	}
@@ -58,10 +116,34 @@ func (m *Order) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validatePayments(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateShipping(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateShippingAddress(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateStatus(formats); err != nil {
 		res = append(res, err)
 	}
 
+	if err := m.validateStatusHistory(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateTax(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateTaxLines(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateTotalPrice(formats); err != nil {
 		res = append(res, err)
 	}
@@ -72,6 +154,44 @@ func (m *Order) Validate(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Order) validateBillingAddress(formats strfmt.Registry) error {
+	if swag.IsZero(m.BillingAddress) { // not required
+		return nil
+	}
+
+	if m.BillingAddress != nil {
+		if err := m.BillingAddress.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("billingAddress")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("billingAddress")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Order) validateCustomer(formats strfmt.Registry) error {
+	if swag.IsZero(m.Customer) { // not required
+		return nil
+	}
+
+	if m.Customer != nil {
+		if err := m.Customer.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("customer")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("customer")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (m *Order) validateDate(formats strfmt.Registry) error {
 
 	if err := validate.Required("date", "body", m.Date); err != nil {
@@ -85,6 +205,51 @@ func (m *Order) validateDate(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Order) validateDiscount(formats strfmt.Registry) error {
+	if swag.IsZero(m.Discount) { // not required
+		return nil
+	}
+
+	if m.Discount != nil {
+		if err := m.Discount.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("discount")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("discount")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Order) validateDiscounts(formats strfmt.Registry) error {
+	if swag.IsZero(m.Discounts) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Discounts); i++ {
+		if swag.IsZero(m.Discounts[i]) { // not required
+			continue
+		}
+
+		if m.Discounts[i] != nil {
+			if err := m.Discounts[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("discounts" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("discounts" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
 func (m *Order) validateID(formats strfmt.Registry) error {
 
 	if err := validate.Required("id", "body", m.ID); err != nil {
@@ -121,6 +286,70 @@ func (m *Order) validateItems(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Order) validatePayments(formats strfmt.Registry) error {
+	if swag.IsZero(m.Payments) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Payments); i++ {
+		if swag.IsZero(m.Payments[i]) { // not required
+			continue
+		}
+
+		if m.Payments[i] != nil {
+			if err := m.Payments[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("payments" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("payments" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+func (m *Order) validateShipping(formats strfmt.Registry) error {
+	if swag.IsZero(m.Shipping) { // not required
+		return nil
+	}
+
+	if m.Shipping != nil {
+		if err := m.Shipping.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("shipping")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("shipping")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Order) validateShippingAddress(formats strfmt.Registry) error {
+	if swag.IsZero(m.ShippingAddress) { // not required
+		return nil
+	}
+
+	if m.ShippingAddress != nil {
+		if err := m.ShippingAddress.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("shippingAddress")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("shippingAddress")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (m *Order) validateStatus(formats strfmt.Registry) error {
 
 	if err := validate.Required("status", "body", m.Status); err != nil {
@@ -130,12 +359,94 @@ func (m *Order) validateStatus(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Order) validateStatusHistory(formats strfmt.Registry) error {
+	if swag.IsZero(m.StatusHistory) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.StatusHistory); i++ {
+		if swag.IsZero(m.StatusHistory[i]) { // not required
+			continue
+		}
+
+		if m.StatusHistory[i] != nil {
+			if err := m.StatusHistory[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("statusHistory" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("statusHistory" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+func (m *Order) validateTax(formats strfmt.Registry) error {
+	if swag.IsZero(m.Tax) { // not required
+		return nil
+	}
+
+	if m.Tax != nil {
+		if err := m.Tax.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("tax")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("tax")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Order) validateTaxLines(formats strfmt.Registry) error {
+	if swag.IsZero(m.TaxLines) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.TaxLines); i++ {
+		if swag.IsZero(m.TaxLines[i]) { // not required
+			continue
+		}
+
+		if m.TaxLines[i] != nil {
+			if err := m.TaxLines[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("taxLines" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("taxLines" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
 func (m *Order) validateTotalPrice(formats strfmt.Registry) error {
 
 	if err := validate.Required("totalPrice", "body", m.TotalPrice); err != nil {
 		return err
 	}
 
+	if m.TotalPrice != nil {
+		if err := m.TotalPrice.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("totalPrice")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("totalPrice")
+			}
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -143,16 +454,128 @@ func (m *Order) validateTotalPrice(formats strfmt.Registry) error {
 func (m *Order) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.contextValidateBillingAddress(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateCustomer(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateDiscount(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateDiscounts(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.contextValidateItems(ctx, formats); err != nil {
 		res = append(res, err)
 	}
 
+	if err := m.contextValidatePayments(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateShipping(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateShippingAddress(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateStatusHistory(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateTax(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateTaxLines(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateTotalPrice(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
 	return nil
 }
 
+func (m *Order) contextValidateBillingAddress(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.BillingAddress != nil {
+		if err := m.BillingAddress.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("billingAddress")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("billingAddress")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Order) contextValidateCustomer(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.Customer != nil {
+		if err := m.Customer.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("customer")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("customer")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Order) contextValidateDiscount(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.Discount != nil {
+		if err := m.Discount.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("discount")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("discount")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Order) contextValidateDiscounts(ctx context.Context, formats strfmt.Registry) error {
+
+	for i := 0; i < len(m.Discounts); i++ {
+
+		if m.Discounts[i] != nil {
+			if err := m.Discounts[i].ContextValidate(ctx, formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("discounts" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("discounts" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
 func (m *Order) contextValidateItems(ctx context.Context, formats strfmt.Registry) error {
 
 	for i := 0; i < len(m.Items); i++ {
@@ -173,6 +596,130 @@ func (m *Order) contextValidateItems(ctx context.Context, formats strfmt.Registr
 	return nil
 }
 
+func (m *Order) contextValidatePayments(ctx context.Context, formats strfmt.Registry) error {
+
+	for i := 0; i < len(m.Payments); i++ {
+
+		if m.Payments[i] != nil {
+			if err := m.Payments[i].ContextValidate(ctx, formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("payments" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("payments" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+func (m *Order) contextValidateShipping(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.Shipping != nil {
+		if err := m.Shipping.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("shipping")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("shipping")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Order) contextValidateShippingAddress(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.ShippingAddress != nil {
+		if err := m.ShippingAddress.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("shippingAddress")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("shippingAddress")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Order) contextValidateStatusHistory(ctx context.Context, formats strfmt.Registry) error {
+
+	for i := 0; i < len(m.StatusHistory); i++ {
+
+		if m.StatusHistory[i] != nil {
+			if err := m.StatusHistory[i].ContextValidate(ctx, formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("statusHistory" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("statusHistory" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+func (m *Order) contextValidateTax(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.Tax != nil {
+		if err := m.Tax.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("tax")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("tax")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Order) contextValidateTaxLines(ctx context.Context, formats strfmt.Registry) error {
+
+	for i := 0; i < len(m.TaxLines); i++ {
+
+		if m.TaxLines[i] != nil {
+			if err := m.TaxLines[i].ContextValidate(ctx, formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("taxLines" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("taxLines" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+func (m *Order) contextValidateTotalPrice(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.TotalPrice != nil {
+		if err := m.TotalPrice.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("totalPrice")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("totalPrice")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 // MarshalBinary interface implementation
 func (m *Order) MarshalBinary() ([]byte, error) {
 	if m == nil {
@@ -23,17 +23,23 @@ type Product struct {
 	// Required: true
 	CategoryName *string `json:"categoryName"`
 
+	// dimensions
+	Dimensions *Dimensions `json:"dimensions,omitempty"`
+
 	// name
 	// Required: true
 	Name *string `json:"name"`
 
 	// price
 	// Required: true
-	Price *float32 `json:"price"`
+	Price *Money `json:"price"`
 
 	// stock
 	// Required: true
 	Stock *Stock `json:"stock"`
+
+	// weight of the product in grams
+	Weight uint32 `json:"weight,omitempty"`
 }
 
 // Validate validates this product
@@ -44,6 +50,10 @@ func (m *Product) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateDimensions(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateName(formats); err != nil {
 		res = append(res, err)
 	}
@@ -71,6 +81,25 @@ func (m *Product) validateCategoryName(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Product) validateDimensions(formats strfmt.Registry) error {
+	if swag.IsZero(m.Dimensions) { // not required
+		return nil
+	}
+
+	if m.Dimensions != nil {
+		if err := m.Dimensions.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("dimensions")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("dimensions")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (m *Product) validateName(formats strfmt.Registry) error {
 
 	if err := validate.Required("name", "body", m.Name); err != nil {
@@ -86,6 +115,17 @@ func (m *Product) validatePrice(formats strfmt.Registry) error {
 		return err
 	}
 
+	if m.Price != nil {
+		if err := m.Price.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("price")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("price")
+			}
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -113,6 +153,14 @@ func (m *Product) validateStock(formats strfmt.Registry) error {
 func (m *Product) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.contextValidateDimensions(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidatePrice(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.contextValidateStock(ctx, formats); err != nil {
 		res = append(res, err)
 	}
@@ -123,6 +171,38 @@ func (m *Product) ContextValidate(ctx context.Context, formats strfmt.Registry)
 	return nil
 }
 
+func (m *Product) contextValidateDimensions(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.Dimensions != nil {
+		if err := m.Dimensions.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("dimensions")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("dimensions")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Product) contextValidatePrice(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.Price != nil {
+		if err := m.Price.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("price")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("price")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (m *Product) contextValidateStock(ctx context.Context, formats strfmt.Registry) error {
 
 	if m.Stock != nil {
@@ -19,6 +19,9 @@ import (
 // swagger:model Stock
 type Stock struct {
 
+	// stock that is not held by the carts, shown when stock reservations are enabled
+	Available *uint32 `json:"available,omitempty"`
+
 	// number
 	Number uint32 `json:"number,omitempty"`
 
@@ -32,6 +32,7 @@ var (
 	CannotBindGivenData = errors.New("Could not bind given data")
 	ValidationError     = errors.New("Validation failed for given payload")
 	ForbiddenError      = errors.New("Forbidden")
+	ConstraintError     = errors.New("Request violates a data constraint")
 )
 
 func (a ApiError) Status() int {
@@ -52,7 +53,12 @@ func NewApiError(code int, err string, causes interface{}) ApiError {
 }
 
 // ParseErrors : parses error to a specific structure (ApiError)
+// note that a typed ApiErr keeps its own status, and only the untyped errors are matched by their message
 func ParseErrors(err error) ApiErr {
+	var apiErr ApiErr
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
 	switch {
 	case strings.Contains(err.Error(), "json: unsupported"):
 		return NewApiError(http.StatusBadRequest, CannotMarshal.Error(), err)
@@ -73,9 +79,6 @@ func ParseErrors(err error) ApiErr {
 	case strings.Contains(err.Error(), "not allowed"):
 		return NewApiError(http.StatusForbidden, ForbiddenError.Error(), err)
 	default:
-		if apiErr, ok := err.(ApiErr); ok {
-			return apiErr
-		}
 		return NewInternalServerError(err)
 	}
 }
@@ -85,6 +88,9 @@ func parseSqlErrors(err error) ApiErr {
 	if strings.Contains(err.Error(), "23505") {
 		return NewApiError(http.StatusBadRequest, ExistsObjectIDError.Error(), err)
 	}
+	if strings.Contains(err.Error(), "23514") {
+		return NewApiError(http.StatusConflict, ConstraintError.Error(), err)
+	}
 	return NewApiError(http.StatusBadRequest, BadRequest.Error(), err)
 }
 
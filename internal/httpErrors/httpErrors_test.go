@@ -0,0 +1,29 @@
+package httpErrors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"typed error with a matching message", NewApiError(http.StatusConflict, "Product not found in the warehouse", nil), http.StatusConflict},
+		{"wrapped typed error", fmt.Errorf("checkout: %w", NewApiError(http.StatusBadRequest, "Coupon is not allowed", nil)), http.StatusBadRequest},
+		{"untyped not found", errors.New("record not found"), http.StatusNotFound},
+		{"untyped not allowed", errors.New("You are not allowed to use this cart"), http.StatusForbidden},
+		{"untyped error", errors.New("connection reset"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.status, ParseErrors(tt.err).Status())
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package address
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/api"
+	"github.com/cagrikilicoglu/shopping-basket/internal/httpErrors"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/response"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/strfmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var countryCode = regexp.MustCompile(`^[A-Z]{2}$`)
+
+type addressHandler struct {
+	repo *AddressRepository
+}
+
+func NewAddressHandler(r *gin.RouterGroup, repo *AddressRepository, cfg *config.Config) {
+	h := &addressHandler{repo: repo}
+
+	r.GET("/addresses", middleware.UserAuthMiddleware(cfg.JWTConfig.SecretKey), h.getAll)
+	r.POST("/addresses", middleware.UserAuthMiddleware(cfg.JWTConfig.SecretKey), h.create)
+	r.PUT("/addresses/id/:id", middleware.UserAuthMiddleware(cfg.JWTConfig.SecretKey), h.update)
+	r.DELETE("/addresses/id/:id", middleware.UserAuthMiddleware(cfg.JWTConfig.SecretKey), h.delete)
+}
+
+// getAll fetches the address book of the user
+func (ah *addressHandler) getAll(c *gin.Context) {
+	userID, err := parsedUserIDFromCtx(c)
+	zap.L().Debug("address.handler.getAll", zap.Reflect("userID", userID))
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	addresses, err := ah.repo.getAllWithUserID(userID)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	response.RespondWithJson(c, http.StatusOK, addressesToResponse(addresses))
+}
+
+// create adds an address from the given data to the address book of the user
+func (ah *addressHandler) create(c *gin.Context) {
+	userID, err := parsedUserIDFromCtx(c)
+	zap.L().Debug("address.handler.create", zap.Reflect("userID", userID))
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	address, err := bindAddress(c, userID)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	created, err := ah.repo.create(address)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	response.RespondWithJson(c, http.StatusCreated, addressToResponse(created))
+}
+
+// update updates an address of the user with the given data
+func (ah *addressHandler) update(c *gin.Context) {
+	id := c.Param("id")
+	zap.L().Debug("address.handler.update", zap.Reflect("id", id))
+
+	idParsed, err := uuid.Parse(id)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	userID, err := parsedUserIDFromCtx(c)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	address, err := bindAddress(c, userID)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	address.ID = idParsed
+
+	updated, err := ah.repo.update(address)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	response.RespondWithJson(c, http.StatusOK, addressToResponse(updated))
+}
+
+// delete deletes an address of the user
+// note that the orders placed with the address keep their own copy of it
+func (ah *addressHandler) delete(c *gin.Context) {
+	id := c.Param("id")
+	zap.L().Debug("address.handler.delete", zap.Reflect("id", id))
+
+	idParsed, err := uuid.Parse(id)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	userID, err := parsedUserIDFromCtx(c)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	if err := ah.repo.delete(idParsed, userID); err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	response.RespondWithJson(c, http.StatusOK, "Address successfully deleted")
+}
+
+// bindAddress binds and validates the address in the request body
+func bindAddress(c *gin.Context, userID uuid.UUID) (*models.Address, error) {
+	addressBody := &api.Address{}
+	if err := c.Bind(&addressBody); err != nil {
+		return nil, err
+	}
+	if err := addressBody.Validate(strfmt.NewFormats()); err != nil {
+		return nil, err
+	}
+
+	address := responseToAddress(addressBody, userID)
+	if err := validateAddress(&address.PostalAddress); err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// validateAddress checks that the address can be delivered to
+func validateAddress(p *models.PostalAddress) error {
+	if p.FullName == "" || p.Line1 == "" || p.City == "" || p.ZipCode == "" {
+		return httpErrors.NewApiError(http.StatusBadRequest, "Full name, address line, city and zip code of the address cannot be empty", nil)
+	}
+	if !countryCode.MatchString(p.Country) {
+		return httpErrors.NewApiError(http.StatusBadRequest, "Country should be a 2 letter ISO 3166-1 code", nil)
+	}
+	return nil
+}
+
+// parsedUserIDFromCtx gets userID from context and parse it to uuid
+func parsedUserIDFromCtx(c *gin.Context) (uuid.UUID, error) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		zap.L().Error("address.handler.parsedUserIDFromCtx failed to fetch userID", zap.Error(errors.New("UserID can not be fetched from context")))
+		return uuid.Nil, errors.New("User data not found")
+	}
+	return uuid.Parse(fmt.Sprintf("%v", userID))
+}
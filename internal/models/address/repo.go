@@ -0,0 +1,147 @@
+package address
+
+import (
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type AddressRepository struct {
+	db *gorm.DB
+}
+
+func (ar *AddressRepository) Migration() {
+	ar.db.AutoMigrate(&models.Address{})
+}
+
+func NewAddressRepository(db *gorm.DB) *AddressRepository {
+	return &AddressRepository{db: db}
+}
+
+// getAllWithUserID fetches the address book of a user, default addresses first
+func (ar *AddressRepository) getAllWithUserID(userID uuid.UUID) (*[]models.Address, error) {
+	zap.L().Debug("address.repo.getAllWithUserID", zap.Reflect("userID", userID))
+
+	var addresses []models.Address
+	if err := ar.db.Where("user_id = ?", userID).Order("is_default_shipping desc, is_default_billing desc, created_at").Find(&addresses).Error; err != nil {
+		zap.L().Error("address.repo.getAllWithUserID failed to get addresses", zap.Error(err))
+		return nil, err
+	}
+	return &addresses, nil
+}
+
+// GetWithIDAndUserID fetches an address of a user by its id
+// note that the address of another user is not found
+func (ar *AddressRepository) GetWithIDAndUserID(id, userID uuid.UUID) (*models.Address, error) {
+	zap.L().Debug("address.repo.GetWithIDAndUserID", zap.Reflect("id", id), zap.Reflect("userID", userID))
+
+	var address models.Address
+	if err := ar.db.Where("id = ? AND user_id = ?", id, userID).First(&address).Error; err != nil {
+		zap.L().Error("address.repo.GetWithIDAndUserID failed to get address", zap.Error(err))
+		return nil, err
+	}
+	return &address, nil
+}
+
+// GetDefaultShipping fetches the default shipping address of a user
+func (ar *AddressRepository) GetDefaultShipping(userID uuid.UUID) (*models.Address, error) {
+	return ar.getDefault(userID, "is_default_shipping")
+}
+
+// GetDefaultBilling fetches the default billing address of a user
+func (ar *AddressRepository) GetDefaultBilling(userID uuid.UUID) (*models.Address, error) {
+	return ar.getDefault(userID, "is_default_billing")
+}
+
+// getDefault fetches the address of a user that is marked as default in the given column
+func (ar *AddressRepository) getDefault(userID uuid.UUID, column string) (*models.Address, error) {
+	zap.L().Debug("address.repo.getDefault", zap.Reflect("userID", userID), zap.Reflect("column", column))
+
+	var address models.Address
+	if err := ar.db.Where("user_id = ?", userID).Where(column+" = ?", true).First(&address).Error; err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+// create adds an address to the address book of its user
+// note that the first address of a user becomes the default shipping and billing address
+func (ar *AddressRepository) create(a *models.Address) (*models.Address, error) {
+	zap.L().Debug("address.repo.create", zap.Reflect("address", a))
+
+	err := ar.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Address{}).Where("user_id = ?", a.UserID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			a.IsDefaultShipping = true
+			a.IsDefaultBilling = true
+		}
+		if err := clearDefaults(tx, a); err != nil {
+			return err
+		}
+		return tx.Create(a).Error
+	})
+	if err != nil {
+		zap.L().Error("address.repo.create failed to create address", zap.Error(err))
+		return nil, err
+	}
+	return a, nil
+}
+
+// update updates an address of a user with the given data
+func (ar *AddressRepository) update(a *models.Address) (*models.Address, error) {
+	zap.L().Debug("address.repo.update", zap.Reflect("address", a))
+
+	err := ar.db.Transaction(func(tx *gorm.DB) error {
+		if err := clearDefaults(tx, a); err != nil {
+			return err
+		}
+		result := tx.Model(a).Where("user_id = ?", a.UserID).Select("*").Omit("id", "user_id", "created_at", "deleted_at").Updates(a)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected < 1 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		zap.L().Error("address.repo.update failed to update address", zap.Error(err))
+		return nil, err
+	}
+	return ar.GetWithIDAndUserID(a.ID, a.UserID)
+}
+
+// delete deletes an address of a user
+func (ar *AddressRepository) delete(id, userID uuid.UUID) error {
+	zap.L().Debug("address.repo.delete", zap.Reflect("id", id), zap.Reflect("userID", userID))
+
+	result := ar.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Address{})
+	if result.Error != nil {
+		zap.L().Error("address.repo.delete failed to delete address", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// clearDefaults unmarks the other default addresses of the user when the given address becomes a default
+// note that a user has at most one default shipping and one default billing address
+func clearDefaults(tx *gorm.DB, a *models.Address) error {
+	if a.IsDefaultShipping {
+		if err := tx.Model(&models.Address{}).Where("user_id = ? AND id <> ?", a.UserID, a.ID).Update("is_default_shipping", false).Error; err != nil {
+			return err
+		}
+	}
+	if a.IsDefaultBilling {
+		if err := tx.Model(&models.Address{}).Where("user_id = ? AND id <> ?", a.UserID, a.ID).Update("is_default_billing", false).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
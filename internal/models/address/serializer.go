@@ -0,0 +1,80 @@
+package address
+
+import (
+	"strings"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/api"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// responseToAddress converts address response model to database model of the given user
+func responseToAddress(a *api.Address, userID uuid.UUID) *models.Address {
+	zap.L().Debug("address.serializer.responseToAddress", zap.Reflect("address", a))
+
+	return &models.Address{
+		UserID: userID,
+		Label:  a.Label,
+		PostalAddress: models.PostalAddress{
+			FullName: strings.TrimSpace(*a.FullName),
+			Line1:    strings.TrimSpace(*a.Line1),
+			Line2:    strings.TrimSpace(a.Line2),
+			City:     strings.TrimSpace(*a.City),
+			State:    strings.TrimSpace(a.State),
+			ZipCode:  strings.TrimSpace(*a.ZipCode),
+			Country:  strings.ToUpper(strings.TrimSpace(*a.Country)),
+			Phone:    strings.TrimSpace(a.Phone),
+		},
+		IsDefaultShipping: a.IsDefaultShipping,
+		IsDefaultBilling:  a.IsDefaultBilling,
+	}
+}
+
+// addressToResponse converts address database model to response model
+func addressToResponse(a *models.Address) *api.Address {
+	return &api.Address{
+		ID:                a.ID.String(),
+		Label:             a.Label,
+		FullName:          &a.PostalAddress.FullName,
+		Line1:             &a.PostalAddress.Line1,
+		Line2:             a.PostalAddress.Line2,
+		City:              &a.PostalAddress.City,
+		State:             a.PostalAddress.State,
+		ZipCode:           &a.PostalAddress.ZipCode,
+		Country:           &a.PostalAddress.Country,
+		Phone:             a.PostalAddress.Phone,
+		IsDefaultShipping: a.IsDefaultShipping,
+		IsDefaultBilling:  a.IsDefaultBilling,
+	}
+}
+
+// addressesToResponse converts address database model to response model as a batch
+func addressesToResponse(as *[]models.Address) []*api.Address {
+	zap.L().Debug("address.serializer.addressesToResponse", zap.Reflect("addresses", as))
+	addresses := make([]*api.Address, 0)
+	for i := range *as {
+		asDeref := *as
+		addresses = append(addresses, addressToResponse(&asDeref[i]))
+	}
+	return addresses
+}
+
+// PostalAddressToResponse converts postal address database model to response model
+// note that it returns nil for an empty address, such as the address of an order placed before address books are introduced
+func PostalAddressToResponse(p *models.PostalAddress) *api.PostalAddress {
+	if *p == (models.PostalAddress{}) {
+		return nil
+	}
+	postal := *p
+	return &api.PostalAddress{
+		FullName: &postal.FullName,
+		Line1:    &postal.Line1,
+		Line2:    postal.Line2,
+		City:     &postal.City,
+		State:    postal.State,
+		ZipCode:  &postal.ZipCode,
+		Country:  &postal.Country,
+		Phone:    postal.Phone,
+	}
+}
@@ -0,0 +1,53 @@
+package address
+
+import (
+	"testing"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/api"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseToAddress(t *testing.T) {
+	fullName, line1, city, zipCode, country := " Jane Doe ", "Main Street 1", "Istanbul", "34000", "tr"
+	userID := uuid.New()
+
+	a := responseToAddress(&api.Address{
+		Label:            "home",
+		FullName:         &fullName,
+		Line1:            &line1,
+		City:             &city,
+		ZipCode:          &zipCode,
+		Country:          &country,
+		IsDefaultBilling: true,
+	}, userID)
+
+	assert.Equal(t, userID, a.UserID)
+	assert.Equal(t, "Jane Doe", a.PostalAddress.FullName)
+	assert.Equal(t, "TR", a.PostalAddress.Country)
+	assert.True(t, a.IsDefaultBilling)
+	assert.False(t, a.IsDefaultShipping)
+	assert.NoError(t, validateAddress(&a.PostalAddress))
+}
+
+func TestValidateAddress(t *testing.T) {
+	valid := models.PostalAddress{FullName: "Jane Doe", Line1: "Main Street 1", City: "Istanbul", ZipCode: "34000", Country: "TR"}
+	assert.NoError(t, validateAddress(&valid))
+
+	missingCity := valid
+	missingCity.City = ""
+	assert.Error(t, validateAddress(&missingCity))
+
+	invalidCountry := valid
+	invalidCountry.Country = "TUR"
+	assert.Error(t, validateAddress(&invalidCountry))
+}
+
+func TestPostalAddressToResponse(t *testing.T) {
+	assert.Nil(t, PostalAddressToResponse(&models.PostalAddress{}))
+
+	res := PostalAddressToResponse(&models.PostalAddress{FullName: "Jane Doe", Line1: "Main Street 1", City: "Istanbul", ZipCode: "34000", Country: "TR"})
+	assert.Equal(t, "Jane Doe", *res.FullName)
+	assert.Equal(t, "TR", *res.Country)
+}
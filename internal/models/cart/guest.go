@@ -0,0 +1,177 @@
+package cart
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/item"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/reservation"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/response"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/jwtHelper"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// HeaderCartToken is the header that carries the token of a guest cart
+const HeaderCartToken = "X-Cart-Token"
+
+// GuestCarts manages the carts of the visitors who are not logged in
+// note that a guest cart is identified by a signed token instead of a user, and it is merged into the cart of the user at login or signup
+type GuestCarts struct {
+	repo          *CartRepository
+	itemService   item.Service
+	reservations  *reservation.Reservations
+	rules         *config.Rules
+	secret        string
+	tokenDuration time.Duration
+}
+
+func NewGuestCarts(repo *CartRepository, is item.Service, reservations *reservation.Reservations, rules *config.Rules, cfg config.GuestCartConfig) *GuestCarts {
+	return &GuestCarts{repo: repo,
+		itemService:   is,
+		reservations:  reservations,
+		rules:         rules,
+		secret:        cfg.SecretKey,
+		tokenDuration: time.Duration(cfg.TokenDurationHours) * time.Hour}
+}
+
+// create creates an empty guest cart and returns it with its token
+func (g *GuestCarts) create() (*models.Cart, string, error) {
+	c, err := g.repo.createGuest()
+	if err != nil {
+		return nil, "", err
+	}
+	token, err := g.newToken(c.ID, time.Now())
+	if err != nil {
+		return nil, "", err
+	}
+	return c, token, nil
+}
+
+// newToken signs a token for the guest cart
+func (g *GuestCarts) newToken(cartID uuid.UUID, now time.Time) (string, error) {
+	claims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"cartId": cartID.String(),
+		"iat":    now.Unix(),
+		"exp":    now.Add(g.tokenDuration).Unix(),
+	})
+	token, err := jwtHelper.GenerateToken(claims, g.secret)
+	if err != nil {
+		return "", err
+	}
+	return *token, nil
+}
+
+// parseToken verifies a guest cart token and returns the ID of its cart
+// note that a token signed by another method or key, or an expired token, is rejected
+func (g *GuestCarts) parseToken(token string) (uuid.UUID, error) {
+	decoded, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		return []byte(g.secret), nil
+	})
+	if err != nil || !decoded.Valid {
+		return uuid.Nil, errors.New("Cart token is not valid")
+	}
+	claims, ok := decoded.Claims.(jwt.MapClaims)
+	if !ok {
+		return uuid.Nil, errors.New("Cart token is not valid")
+	}
+	cartID, err := uuid.Parse(fmt.Sprintf("%v", claims["cartId"]))
+	if err != nil {
+		return uuid.Nil, errors.New("Cart token is not valid")
+	}
+	return cartID, nil
+}
+
+// Middleware checks the guest cart token of the request and sets the ID of its cart
+func (g *GuestCarts) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader(HeaderCartToken)
+		if token == "" {
+			c.Abort()
+			response.RespondWithError(c, errors.New("Missing cart token"))
+			return
+		}
+		cartID, err := g.parseToken(token)
+		if err != nil {
+			zap.L().Debug("cart.guest.Middleware", zap.Error(err))
+			c.Abort()
+			response.RespondWithError(c, errors.New("You are not allowed to use this cart"))
+			return
+		}
+		c.Set("cartID", cartID)
+		c.Next()
+	}
+}
+
+// Merge moves the items of a guest cart into the cart of the user and deletes the guest cart
+// note that the quantity of a product that is already in the user cart is added to it up to the stock, and the products beyond the maximum number of items are left out,
+// the user cart is priced with its promotions and coupon once the merge is committed
+func (g *GuestCarts) Merge(token string, userID uuid.UUID) error {
+	guestID, err := g.parseToken(token)
+	if err != nil {
+		return err
+	}
+
+	var report []item.ReorderLine
+	var userCart *models.Cart
+	err = g.repo.Transaction(func(tx *gorm.DB) error {
+		repo := g.repo.WithTx(tx)
+
+		guest, err := repo.getGuestForUpdate(guestID)
+		if err != nil {
+			return err
+		}
+		userCart, err = repo.GetByUserID(userID.String())
+		if err != nil {
+			return err
+		}
+
+		// the holds of the guest cart are released first, so that its items can be held for the user cart
+		if err := g.reservations.WithTx(tx).ReleaseCart(guest.ID); err != nil {
+			return err
+		}
+		report, err = g.itemService.Reorder(tx, userCart.ID, mergeLines(guest.Items), g.rules.Get().MaxItemsForCart)
+		if err != nil {
+			return err
+		}
+		return repo.deleteGuest(guest.ID)
+	})
+	if err != nil {
+		zap.L().Error("cart.guest.Merge failed to merge guest cart", zap.Error(err))
+		return err
+	}
+	// the merge is committed by now, so a total that cannot be priced is only logged and left to the recompute-cart-totals job
+	totalPrice, err := g.itemService.Price(userCart.ID)
+	if err == nil {
+		err = g.repo.UpdateTotalPrice(userCart, totalPrice)
+	}
+	if err != nil {
+		zap.L().Error("cart.guest.Merge failed to price the merged cart", zap.Reflect("cartID", userCart.ID), zap.Error(err))
+	}
+	zap.L().Info("cart.guest.Merge guest cart merged", zap.Reflect("guestCartID", guestID), zap.Reflect("userID", userID), zap.Reflect("lines", report))
+	return nil
+}
+
+// mergeLines converts the items of a guest cart into lines that are added to the user cart
+// note that the lines are identified by the sku of their product, as the lines of a past order are
+func mergeLines(items []models.Item) []models.Item {
+	lines := make([]models.Item, 0, len(items))
+	for _, i := range items {
+		line := models.Item{Quantity: i.Quantity}
+		line.Snapshot.SKU = i.Product.Stock.SKU
+		if i.Product.Name != nil {
+			line.Snapshot.Name = *i.Product.Name
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
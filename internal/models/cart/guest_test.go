@@ -0,0 +1,71 @@
+package cart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestGuestCarts(secret string) *GuestCarts {
+	return NewGuestCarts(nil, nil, nil, nil, config.GuestCartConfig{SecretKey: secret, TokenDurationHours: 1})
+}
+
+func TestGuestCartToken(t *testing.T) {
+	g := newTestGuestCarts("guestCartKey")
+	cartID := uuid.New()
+
+	token, err := g.newToken(cartID, time.Now())
+	require.NoError(t, err)
+
+	parsed, err := g.parseToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, cartID, parsed)
+}
+
+func TestGuestCartToken_Rejected(t *testing.T) {
+	g := newTestGuestCarts("guestCartKey")
+	cartID := uuid.New()
+
+	expired, err := g.newToken(cartID, time.Now().Add(-2*time.Hour))
+	require.NoError(t, err)
+	otherKey, err := newTestGuestCarts("otherKey").newToken(cartID, time.Now())
+	require.NoError(t, err)
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"cartId": cartID.String()}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+	valid, err := g.newToken(cartID, time.Now())
+	require.NoError(t, err)
+
+	for name, token := range map[string]string{
+		"expired":   expired,
+		"other key": otherKey,
+		"unsigned":  unsigned,
+		"tampered":  valid[:len(valid)-2] + "xx",
+		"garbage":   "not-a-token",
+	} {
+		_, err := g.parseToken(token)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestMergeLines(t *testing.T) {
+	name := "Phone"
+	items := []models.Item{
+		{Quantity: 2, Product: models.Product{Name: &name, Stock: models.Stock{SKU: "SKU-1"}}},
+		{Quantity: 1, Product: models.Product{Stock: models.Stock{SKU: "SKU-2"}}},
+	}
+
+	lines := mergeLines(items)
+
+	require.Len(t, lines, 2)
+	assert.Equal(t, "SKU-1", lines[0].Snapshot.SKU)
+	assert.Equal(t, "Phone", lines[0].Snapshot.Name)
+	assert.Equal(t, uint(2), lines[0].Quantity)
+	assert.Equal(t, "SKU-2", lines[1].Snapshot.SKU)
+	assert.Equal(t, "", lines[1].Snapshot.Name)
+}
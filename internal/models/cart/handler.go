@@ -5,36 +5,77 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/cagrikilicoglu/shopping-basket/internal/api"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/address"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/coupon"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/currency"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models/item"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/reservation"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models/response"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/shipping"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/tax"
 	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
 	"github.com/cagrikilicoglu/shopping-basket/pkg/middleware"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
 	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/strfmt"
 	"go.uber.org/zap"
 )
 
-var maxItemsForCart = 20
-
 type cartHandler struct {
 	repo        *CartRepository
-	itemService item.Service
+	itemService  item.Service
+	addressRepo  *address.AddressRepository
+	shipping     *shipping.Calculator
+	taxes        *tax.Calculator
+	rules        *config.Rules
+	reservations *reservation.Reservations
+	guests       *GuestCarts
+	coupons      *coupon.Coupons
 }
 
-func NewCartHandler(r *gin.RouterGroup, repo *CartRepository, is item.Service, cfg *config.Config) {
+func NewCartHandler(r *gin.RouterGroup, repo *CartRepository, is item.Service, addressRepo *address.AddressRepository, calculator *shipping.Calculator, taxes *tax.Calculator, rules *config.Rules, reservations *reservation.Reservations, guests *GuestCarts, coupons *coupon.Coupons, idempotent gin.HandlerFunc, cfg *config.Config) {
 	h := &cartHandler{repo: repo,
-		itemService: is}
+		itemService:  is,
+		addressRepo:  addressRepo,
+		shipping:     calculator,
+		taxes:        taxes,
+		rules:        rules,
+		reservations: reservations,
+		guests:       guests,
+		coupons:      coupons}
 
 	r.GET("/", middleware.UserAuthMiddleware(cfg.JWTConfig.SecretKey), h.getCart)
-	r.POST("/add/sku/:sku/quantity/:quantity", middleware.UserAuthMiddleware(cfg.JWTConfig.SecretKey), h.addItem)
-	r.DELETE("/delete/sku/:sku", middleware.UserAuthMiddleware(cfg.JWTConfig.SecretKey), h.deleteItem)
-	r.PUT("/update/sku/:sku/quantity/:quantity", middleware.UserAuthMiddleware(cfg.JWTConfig.SecretKey), h.updateItem)
+	r.POST("/add/sku/:sku/quantity/:quantity", middleware.UserAuthMiddleware(cfg.JWTConfig.SecretKey), idempotent, h.addItem)
+	r.DELETE("/delete/sku/:sku", middleware.UserAuthMiddleware(cfg.JWTConfig.SecretKey), idempotent, h.deleteItem)
+	r.PUT("/update/sku/:sku/quantity/:quantity", middleware.UserAuthMiddleware(cfg.JWTConfig.SecretKey), idempotent, h.updateItem)
+	r.POST("/coupon", middleware.UserAuthMiddleware(cfg.JWTConfig.SecretKey), idempotent, h.applyCoupon)
+	r.DELETE("/coupon", middleware.UserAuthMiddleware(cfg.JWTConfig.SecretKey), idempotent, h.removeCoupon)
+
+	r.POST("/guest", h.createGuestCart)
+	r.GET("/guest", guests.Middleware(), h.getCart)
+	r.POST("/guest/add/sku/:sku/quantity/:quantity", guests.Middleware(), h.addItem)
+	r.DELETE("/guest/delete/sku/:sku", guests.Middleware(), h.deleteItem)
+	r.PUT("/guest/update/sku/:sku/quantity/:quantity", guests.Middleware(), h.updateItem)
+}
+
+// createGuestCart creates an empty cart for a visitor who is not logged in and returns it with its token
+func (cr *cartHandler) createGuestCart(c *gin.Context) {
+	cart, token, err := cr.guests.create()
+	zap.L().Debug("cart.handler.createGuestCart", zap.Reflect("cart", cart))
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	c.Header(HeaderCartToken, token)
+	response.RespondWithJson(c, http.StatusCreated, guestCartToResponse(cart, token, currency.RateFromCtx(c), cr.taxes))
 }
 
 // getCart fetches cart data from user id
 func (cr *cartHandler) getCart(c *gin.Context) {
 
-	cart, err := cr.getCartFromUserID(c)
+	cart, err := cr.currentCart(c)
 	zap.L().Debug("cart.handler.getCart", zap.Reflect("cart", cart))
 
 	if err != nil {
@@ -53,13 +94,14 @@ func (cr *cartHandler) getCart(c *gin.Context) {
 		response.RespondWithError(c, err)
 		return
 	}
-	response.RespondWithJson(c, http.StatusOK, cartToResponse(cart))
+	cart.TotalPrice = totalPrice
+	cr.respondWithCart(c, cart)
 }
 
 // addItem adds a product to the cart and returns updated cart
 func (cr *cartHandler) addItem(c *gin.Context) {
 
-	cart, err := cr.getCartFromUserID(c)
+	cart, err := cr.currentCart(c)
 	zap.L().Debug("cart.handler.addItem", zap.Reflect("cart", cart))
 
 	if err != nil {
@@ -67,7 +109,7 @@ func (cr *cartHandler) addItem(c *gin.Context) {
 		return
 	}
 
-	err = checkItemNumber(cart)
+	err = checkItemNumber(cart, cr.rules.Get().MaxItemsForCart)
 	if err != nil {
 		response.RespondWithError(c, err)
 		return
@@ -91,13 +133,13 @@ func (cr *cartHandler) addItem(c *gin.Context) {
 		return
 	}
 
-	response.RespondWithJson(c, http.StatusOK, cartToResponse(updatedCart))
+	cr.respondWithCart(c, updatedCart)
 }
 
 // deleteItem deletes a product from the cart
 func (cr *cartHandler) deleteItem(c *gin.Context) {
 
-	cart, err := cr.getCartFromUserID(c)
+	cart, err := cr.currentCart(c)
 	zap.L().Debug("cart.handler.deleteItem", zap.Reflect("cart", cart))
 
 	totalPrice, err := cr.itemService.Delete(c)
@@ -118,7 +160,7 @@ func (cr *cartHandler) deleteItem(c *gin.Context) {
 // updateItem updates quantity of a product that is already in the cart
 func (cr *cartHandler) updateItem(c *gin.Context) {
 
-	cart, err := cr.getCartFromUserID(c)
+	cart, err := cr.currentCart(c)
 	zap.L().Debug("cart.handler.updateItem", zap.Reflect("cart", cart))
 
 	totalPrice, err := cr.itemService.Update(c)
@@ -138,8 +180,99 @@ func (cr *cartHandler) updateItem(c *gin.Context) {
 		response.RespondWithError(c, err)
 		return
 	}
-	response.RespondWithJson(c, http.StatusOK, cartToResponse(updatedCart))
+	cr.respondWithCart(c, updatedCart)
+
+}
+
+// applyCoupon applies the coupon code in the request body to the cart and returns the discounted cart
+func (cr *cartHandler) applyCoupon(c *gin.Context) {
+
+	cart, err := cr.currentCart(c)
+	zap.L().Debug("cart.handler.applyCoupon", zap.Reflect("cart", cart))
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	couponBody := &api.CouponCode{}
+	if err := c.Bind(&couponBody); err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	if err := couponBody.Validate(strfmt.NewFormats()); err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	if _, err := cr.coupons.Apply(cart.ID, cart.UserID, *couponBody.Code, cart.Items); err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
 
+	totalPrice, err := cr.itemService.CalculatePrice(c)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	err = cr.repo.UpdateTotalPrice(cart, totalPrice)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	cart.TotalPrice = totalPrice
+	cr.respondWithCart(c, cart)
+}
+
+// removeCoupon removes the coupon from the cart
+func (cr *cartHandler) removeCoupon(c *gin.Context) {
+
+	cart, err := cr.currentCart(c)
+	zap.L().Debug("cart.handler.removeCoupon", zap.Reflect("cart", cart))
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	if err := cr.coupons.Remove(cart.ID); err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	totalPrice, err := cr.itemService.CalculatePrice(c)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	err = cr.repo.UpdateTotalPrice(cart, totalPrice)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	response.RespondWithJson(c, http.StatusOK, "Coupon successfully removed")
+}
+
+// currentCart fetches the guest cart of the request, or the cart of the user when the request has no guest cart
+// note that any activity on the cart extends the stock reservations of its items,
+// and the items are revalidated against their products with the warnings set to the context as cartWarnings
+func (cr *cartHandler) currentCart(c *gin.Context) (*models.Cart, error) {
+	var cart *models.Cart
+	var err error
+	if cartID, ok := c.Get("cartID"); ok {
+		cart, err = cr.repo.GetByCartID(fmt.Sprintf("%v", cartID))
+	} else {
+		cart, err = cr.getCartFromUserID(c)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := cr.reservations.Extend(cart.ID); err != nil {
+		zap.L().Error("cart.handler.currentCart failed to extend reservations", zap.Error(err))
+	}
+	warnings, err := cr.itemService.Revalidate(cart.ID, cart.Items)
+	if err != nil {
+		return nil, err
+	}
+	c.Set("cartWarnings", warnings)
+	return cart, nil
 }
 
 // getCartFromUserID fetches cart of the user by ID
@@ -161,10 +294,74 @@ func (cr *cartHandler) getCartFromUserID(c *gin.Context) (*models.Cart, error) {
 
 }
 
+// respondWithCart prices the discounts, the tax and the shipping of the cart for the default shipping address of its user and responds with it
+// note that the default tax region is used when the user has no default shipping address
+func (cr *cartHandler) respondWithCart(c *gin.Context, cart *models.Cart) {
+	// the items are discounted before their tax is calculated
+	discounts, err := cr.itemService.ApplyDiscounts(cart.ID, cart.Items)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	// the promotions may have started or ended since the total was saved
+	if total, err := discountedTotal(cart.Items, discounts.Total); err == nil {
+		cart.TotalPrice = total
+	}
+
+	region := ""
+	var quote *models.ShippingQuote
+	if a, err := cr.addressRepo.GetDefaultShipping(cart.UserID); err == nil {
+		region = a.PostalAddress.Country
+		quote = cr.estimateShipping(cart, a.PostalAddress.ZipCode)
+	}
+	waived := discounts.Coupon.WaiveShipping(quote)
+
+	_, taxTotal, err := cr.itemService.CalculateTax(cart.Items, region)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	cartResponse := cartToResponse(cart, currency.RateFromCtx(c), cr.taxes, taxTotal, quote)
+	cartResponse.Discounts = coupon.DiscountsToResponse(discounts.OrderLines(waived), currency.RateFromCtx(c))
+	if discounts.Coupon != nil {
+		cartResponse.CouponMessage = discounts.Coupon.Reason
+	}
+	warnings, _ := c.Value("cartWarnings").([]item.CartWarning)
+	cartResponse.Warnings = warningsToResponse(warnings)
+	response.RespondWithJson(c, http.StatusOK, cartResponse)
+}
+
+// discountedTotal sums the prices of the items and takes the discount off
+func discountedTotal(items []models.Item, discount money.Money) (money.Money, error) {
+	var total money.Money
+	for _, i := range items {
+		var err error
+		if total, err = total.Add(i.TotalPrice); err != nil {
+			return money.Money{}, err
+		}
+	}
+	return total.Sub(discount)
+}
+
+// estimateShipping prices the shipping of the cart to the given zip code
+// note that the cart is shown without shipping when it cannot be shipped to the zip code
+func (cr *cartHandler) estimateShipping(c *models.Cart, zipCode string) *models.ShippingQuote {
+	subtotal := c.TotalPrice
+	if subtotal.Currency == "" {
+		subtotal = money.New(subtotal.Amount, money.DefaultCurrency)
+	}
+	quote, err := cr.shipping.Quote(c.Items, subtotal, zipCode)
+	if err != nil {
+		zap.L().Debug("cart.handler.estimateShipping", zap.Error(err))
+		return nil
+	}
+	return &quote
+}
+
 //checkItemNumber checks if item number in the cart is below maximum
-func checkItemNumber(c *models.Cart) error {
-	if len(c.Items) >= maxItemsForCart {
-		return errors.New("You exceed maximum number of items")
+func checkItemNumber(c *models.Cart, maxItems int) error {
+	if len(c.Items) >= maxItems {
+		return fmt.Errorf("You exceed maximum number of %d items", maxItems)
 	}
 	return nil
 }
@@ -1,9 +1,16 @@
 package cart
 
 import (
+	"context"
+	"time"
+
 	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/database"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CartRepository struct {
@@ -12,6 +19,9 @@ type CartRepository struct {
 
 func (cr *CartRepository) Migration() {
 	cr.db.AutoMigrate(&models.Cart{})
+	if err := database.MigrateMoneyColumn(cr.db, "carts", "total_price", "total_price_"); err != nil {
+		zap.L().Fatal("cart.repo.Migration failed to migrate money column", zap.Error(err))
+	}
 }
 
 func NewCartRepository(db *gorm.DB) *CartRepository {
@@ -41,12 +51,130 @@ func (cr *CartRepository) GetByCartID(id string) (*models.Cart, error) {
 }
 
 // UpdateTotalPrice updates totalPrice of the cart
-func (cr *CartRepository) UpdateTotalPrice(c *models.Cart, totalPrice float32) error {
+func (cr *CartRepository) UpdateTotalPrice(c *models.Cart, totalPrice money.Money) error {
 	zap.L().Debug("cart.update.updateTotalPrice", zap.Reflect("cart", c), zap.Reflect("totalPrice", totalPrice))
 
-	if result := cr.db.Model(&c).Select("TotalPrice").Update("total_price", totalPrice); result.Error != nil {
+	if result := cr.db.Model(&c).Select("total_price_amount", "total_price_currency").Updates(map[string]interface{}{"total_price_amount": totalPrice.Amount, "total_price_currency": totalPrice.Currency}); result.Error != nil {
 		zap.L().Error("cart.update.updateTotalPrice failed to get update total price", zap.Error(result.Error))
 		return result.Error
 	}
 	return nil
 }
+
+// WithTx returns a copy of the repository that runs its queries in the given transaction
+func (cr *CartRepository) WithTx(tx *gorm.DB) *CartRepository {
+	return &CartRepository{db: tx}
+}
+
+// Transaction runs the given function in a database transaction
+// note that the transaction is rolled back if the function returns an error
+func (cr *CartRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	if err := cr.db.Transaction(fn); err != nil {
+		zap.L().Error("cart.repo.Transaction rolled back", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// createGuest creates an empty cart without a user
+func (cr *CartRepository) createGuest() (*models.Cart, error) {
+	c := &models.Cart{ID: uuid.New()}
+	if err := cr.db.Create(c).Error; err != nil {
+		zap.L().Error("cart.repo.createGuest failed to create cart", zap.Error(err))
+		return nil, err
+	}
+	return c, nil
+}
+
+// getGuestForUpdate fetches a guest cart with its items and locks it until the surrounding transaction ends
+func (cr *CartRepository) getGuestForUpdate(id uuid.UUID) (*models.Cart, error) {
+	var c *models.Cart
+	if err := cr.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND user_id IS NULL", id).First(&c).Error; err != nil {
+		zap.L().Error("cart.repo.getGuestForUpdate failed to get cart", zap.Error(err))
+		return nil, err
+	}
+	if err := cr.db.Preload("Product").Where("cart_id = ? AND is_ordered = ?", id, false).Order("created_at").Find(&c.Items).Error; err != nil {
+		zap.L().Error("cart.repo.getGuestForUpdate failed to get items", zap.Error(err))
+		return nil, err
+	}
+	return c, nil
+}
+
+// deleteGuest deletes a guest cart with its items
+func (cr *CartRepository) deleteGuest(id uuid.UUID) error {
+	if err := cr.db.Where("cart_id = ? AND is_ordered = ?", id, false).Delete(&models.Item{}).Error; err != nil {
+		zap.L().Error("cart.repo.deleteGuest failed to delete items", zap.Error(err))
+		return err
+	}
+	if err := cr.db.Where("id = ? AND user_id IS NULL", id).Delete(&models.Cart{}).Error; err != nil {
+		zap.L().Error("cart.repo.deleteGuest failed to delete cart", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// PurgeStale empties the carts whose items are all added before the given time and returns the number of emptied carts
+// note that the guest carts that are not changed since the given time are deleted as well
+func (cr *CartRepository) PurgeStale(before time.Time) (int64, error) {
+	zap.L().Debug("cart.repo.PurgeStale", zap.Reflect("before", before))
+
+	var purged int64
+	err := cr.db.Transaction(func(tx *gorm.DB) error {
+		var cartIDs []uuid.UUID
+		if err := tx.Model(&models.Item{}).Where("is_ordered = ?", false).Group("cart_id").Having("MAX(updated_at) < ?", before).Pluck("cart_id", &cartIDs).Error; err != nil {
+			return err
+		}
+		if len(cartIDs) == 0 {
+			return nil
+		}
+		if err := tx.Where("is_ordered = ?", false).Where("cart_id IN ?", cartIDs).Delete(&models.Item{}).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&models.Cart{}).Where("id IN ?", cartIDs).Select("total_price_amount", "total_price_currency").Updates(map[string]interface{}{"total_price_amount": 0, "total_price_currency": money.DefaultCurrency})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	if err == nil {
+		// guest carts cannot be used again once they are stale, so they are deleted instead of kept empty
+		err = cr.db.Where("user_id IS NULL AND updated_at < ?", before).Delete(&models.Cart{}).Error
+	}
+	if err != nil {
+		zap.L().Error("cart.repo.PurgeStale failed to purge carts", zap.Error(err))
+		return 0, err
+	}
+	return purged, nil
+}
+
+// RecomputeTotals sets the total price of every cart to the price given by the pricing function and returns the number of corrected carts
+// note that the carts are priced with their promotions and coupons, so only the totals that are out of date change,
+// and the update time of a corrected cart is kept, so the correction does not postpone the purge of a stale cart
+func (cr *CartRepository) RecomputeTotals(ctx context.Context, price func(cartID uuid.UUID) (money.Money, error)) (int64, error) {
+	var carts []models.Cart
+	if err := cr.db.Select("id", "total_price_amount", "total_price_currency").Find(&carts).Error; err != nil {
+		zap.L().Error("cart.repo.RecomputeTotals failed to get carts", zap.Error(err))
+		return 0, err
+	}
+
+	var corrected int64
+	for i := range carts {
+		if ctx.Err() != nil {
+			return corrected, ctx.Err()
+		}
+		total, err := price(carts[i].ID)
+		if err != nil {
+			return corrected, err
+		}
+		if total == carts[i].TotalPrice {
+			continue
+		}
+		if err := cr.db.Model(&carts[i]).UpdateColumns(map[string]interface{}{
+			"total_price_amount":   total.Amount,
+			"total_price_currency": total.Currency,
+		}).Error; err != nil {
+			zap.L().Error("cart.repo.RecomputeTotals failed to update cart", zap.Reflect("cartID", carts[i].ID), zap.Error(err))
+			return corrected, err
+		}
+		corrected++
+	}
+	return corrected, nil
+}
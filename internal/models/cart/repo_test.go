@@ -1,6 +1,7 @@
 package cart
 
 import (
+	"context"
 	"database/sql"
 	"reflect"
 	"regexp"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -56,7 +58,7 @@ func TestInit(t *testing.T) {
 }
 
 var id = uuid.New()
-var updatedPrice = float32(60.0)
+var updatedPrice = money.New(6000, "USD")
 
 var cart = models.Cart{
 	CreatedAt:  time.Now(),
@@ -65,7 +67,7 @@ var cart = models.Cart{
 	ID:         id,
 	UserID:     uuid.New(),
 	Items:      []models.Item{item_1},
-	TotalPrice: float32(32.0),
+	TotalPrice: money.New(3200, "USD"),
 }
 var item_1 = models.Item{
 	CartID:    id,
@@ -76,8 +78,8 @@ func (s *Suite) TestCartRepository_GetByCartID() {
 	var (
 		query_1 = `SELECT * FROM "carts" WHERE id = $1 AND "carts"."deleted_at" IS NULL ORDER BY "carts"."id" LIMIT 1`
 		query_2 = `SELECT * FROM "items" WHERE "items"."cart_id" = $1 AND is_ordered = $2 AND "items"."deleted_at" IS NULL`
-		row_1   = sqlmock.NewRows([]string{"created_at", "updated_at", "deleted_at", "id", "user_id", "total_price"}).
-			AddRow(cart.CreatedAt, cart.UpdatedAt, cart.DeletedAt, cart.ID.String(), cart.UserID.String(), cart.TotalPrice)
+		row_1   = sqlmock.NewRows([]string{"created_at", "updated_at", "deleted_at", "id", "user_id", "total_price_amount", "total_price_currency"}).
+			AddRow(cart.CreatedAt, cart.UpdatedAt, cart.DeletedAt, cart.ID.String(), cart.UserID.String(), cart.TotalPrice.Amount, cart.TotalPrice.Currency)
 		row_2 = sqlmock.NewRows([]string{"cart_id", "is_ordered"}).
 			AddRow(item_1.CartID, item_1.IsOrdered)
 	)
@@ -102,7 +104,7 @@ func (s *Suite) TestCartRepository_GetByCartID_Error() {
 	var (
 		query_1 = `SELECT * FROM "carts" WHERE id = $1 AND "carts"."deleted_at" IS NULL ORDER BY "carts"."id" LIMIT 1`
 		query_2 = `SELECT * FROM "items" WHERE "items"."cart_id" = $1 AND is_ordered = $2 AND "items"."deleted_at" IS NULL`
-		row_1   = sqlmock.NewRows([]string{"created_at", "updated_at", "deleted_at", "id", "user_id", "total_price"})
+		row_1   = sqlmock.NewRows([]string{"created_at", "updated_at", "deleted_at", "id", "user_id", "total_price_amount", "total_price_currency"})
 		row_2   = sqlmock.NewRows([]string{"cart_id", "is_ordered"}).
 			AddRow(item_1.CartID, item_1.IsOrdered)
 	)
@@ -126,7 +128,7 @@ func (s *Suite) TestCartRepository_GetByUserID_Error() {
 	var (
 		query_1 = `SELECT * FROM "carts" WHERE user_id = $1 AND "carts"."deleted_at" IS NULL ORDER BY "carts"."id" LIMIT 1`
 		query_2 = `SELECT * FROM "items" WHERE "items"."cart_id" = $1 AND is_ordered = $2 AND "items"."deleted_at" IS NULL`
-		row_1   = sqlmock.NewRows([]string{"created_at", "updated_at", "deleted_at", "id", "user_id", "total_price"})
+		row_1   = sqlmock.NewRows([]string{"created_at", "updated_at", "deleted_at", "id", "user_id", "total_price_amount", "total_price_currency"})
 		row_2   = sqlmock.NewRows([]string{"cart_id", "is_ordered"}).
 			AddRow(item_1.CartID, item_1.IsOrdered)
 	)
@@ -149,8 +151,8 @@ func (s *Suite) TestCartRepository_GetByUserID_Error() {
 // 	var (
 // 		query_1 = `SELECT * FROM "carts" WHERE user_id = $1 AND "carts"."deleted_at" IS NULL ORDER BY "carts"."id" LIMIT 1`
 // 		query_2 = `SELECT * FROM "items" WHERE "items"."cart_id" = $1 AND is_ordered = $2 AND "items"."deleted_at" IS NULL`
-// 		row_1   = sqlmock.NewRows([]string{"created_at", "updated_at", "deleted_at", "id", "user_id", "total_price"}).
-// 			AddRow(cart.CreatedAt, cart.UpdatedAt, cart.DeletedAt, cart.ID.String(), cart.UserID.String(), cart.TotalPrice)
+// 		row_1   = sqlmock.NewRows([]string{"created_at", "updated_at", "deleted_at", "id", "user_id", "total_price_amount", "total_price_currency"}).
+// 			AddRow(cart.CreatedAt, cart.UpdatedAt, cart.DeletedAt, cart.ID.String(), cart.UserID.String(), cart.TotalPrice.Amount, cart.TotalPrice.Currency)
 // 		row_2 = sqlmock.NewRows([]string{"cart_id", "is_ordered"}).
 // 			AddRow(item_1.CartID, item_1.IsOrdered)
 // 	)
@@ -177,8 +179,8 @@ func (s *Suite) TestCartRepository_GetByUserID_Error() {
 // 	var (
 // 		query_1 = `UPDATE "carts" SET total_price = $1 WHERE id = $2 AND "carts"."deleted_at" IS NULL`
 
-// 		// row_1 = sqlmock.NewRows([]string{"created_at", "updated_at", "deleted_at", "id", "user_id", "total_price"}).
-// 		// 	AddRow(cart.CreatedAt, cart.UpdatedAt, cart.DeletedAt, cart.ID.String(), cart.UserID.String(), cart.TotalPrice)
+// 		// row_1 = sqlmock.NewRows([]string{"created_at", "updated_at", "deleted_at", "id", "user_id", "total_price_amount", "total_price_currency"}).
+// 		// 	AddRow(cart.CreatedAt, cart.UpdatedAt, cart.DeletedAt, cart.ID.String(), cart.UserID.String(), cart.TotalPrice.Amount, cart.TotalPrice.Currency)
 // 	)
 // 	prep := s.mock.ExpectPrepare(query_1)
 // 	prep.ExpectExec().
@@ -190,3 +192,31 @@ func (s *Suite) TestCartRepository_GetByUserID_Error() {
 // 	require.NoError(s.T(), err)
 
 // }
+
+func TestCartRepository_RecomputeTotals(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db, PreferSimpleProtocol: true}), &gorm.Config{})
+	require.NoError(t, err)
+	promoted := uuid.New()
+	prices := map[uuid.UUID]money.Money{cart.ID: cart.TotalPrice, promoted: money.New(4500, "USD")}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id","total_price_amount","total_price_currency" FROM "carts" WHERE "carts"."deleted_at" IS NULL`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "total_price_amount", "total_price_currency"}).
+			AddRow(cart.ID.String(), cart.TotalPrice.Amount, cart.TotalPrice.Currency).
+			AddRow(promoted.String(), 5000, "USD"))
+	// the update time is left as it is, so a corrected cart still goes stale
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "carts" SET "total_price_amount"=$1,"total_price_currency"=$2 WHERE "carts"."deleted_at" IS NULL AND "id" = $3`)).
+		WithArgs(int64(4500), "USD", promoted).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	n, err := NewCartRepository(gdb).RecomputeTotals(context.Background(), func(cartID uuid.UUID) (money.Money, error) {
+		return prices[cartID], nil
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
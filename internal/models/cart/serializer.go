@@ -5,23 +5,55 @@ import (
 	"github.com/cagrikilicoglu/shopping-basket/internal/models"
 
 	"github.com/cagrikilicoglu/shopping-basket/internal/models/item"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/response"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/shipping"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/tax"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
 
 	"go.uber.org/zap"
 )
 
 // cartToResponse converts cart database model to response model.
-func cartToResponse(c *models.Cart) *api.Cart {
+// note that the amounts are displayed in the tax mode of the given calculator and converted to the currency of the given rate
+func cartToResponse(c *models.Cart, rate money.Rate, taxes *tax.Calculator, taxTotal money.Money, quote *models.ShippingQuote) *api.Cart {
 	zap.L().Debug("Cart.serializer.cartToResponse", zap.Reflect("cart", c))
 	userIDstr := c.UserID.String()
 	apiItems := make([]*api.Item, 0)
 
 	for i := range c.Items {
-		apiItems = append(apiItems, item.ItemToResponse(&c.Items[i]))
+		apiItems = append(apiItems, item.ItemToResponse(&c.Items[i], rate, taxes))
 	}
 
 	return &api.Cart{
-		UserID:     &userIDstr,
-		Items:      apiItems,
-		TotalPrice: &c.TotalPrice,
+		UserID:      &userIDstr,
+		Items:       apiItems,
+		TotalPrice:  response.MoneyToResponse(rate.Apply(taxes.DisplayLine(c.TotalPrice, taxTotal))),
+		Shipping:    shipping.QuoteToResponse(quote, rate),
+		Tax:         response.MoneyToResponse(rate.Apply(taxTotal)),
+		TaxIncluded: taxes.Inclusive(),
 	}
 }
+
+// guestCartToResponse converts a new guest cart and its token to response model.
+func guestCartToResponse(c *models.Cart, token string, rate money.Rate, taxes *tax.Calculator) *api.GuestCart {
+	zap.L().Debug("Cart.serializer.guestCartToResponse", zap.Reflect("cart", c))
+	return &api.GuestCart{
+		Token: &token,
+		Cart:  cartToResponse(c, rate, taxes, money.New(0, money.DefaultCurrency), nil),
+	}
+}
+
+// warningsToResponse converts the warnings about the lines of a cart to response model
+func warningsToResponse(ws []item.CartWarning) []*api.CartWarning {
+	warnings := make([]*api.CartWarning, 0, len(ws))
+	for i := range ws {
+		kind, message := ws[i].Kind, ws[i].Message
+		warnings = append(warnings, &api.CartWarning{
+			Sku:     ws[i].SKU,
+			Name:    ws[i].Name,
+			Kind:    &kind,
+			Message: &message,
+		})
+	}
+	return warnings
+}
@@ -5,8 +5,10 @@ import (
 	"net/http"
 
 	"github.com/cagrikilicoglu/shopping-basket/internal/api"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/currency"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models/product"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models/response"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/tax"
 	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
 	"github.com/cagrikilicoglu/shopping-basket/pkg/middleware"
 	"github.com/cagrikilicoglu/shopping-basket/pkg/pagination"
@@ -16,11 +18,13 @@ import (
 )
 
 type categoryHandler struct {
-	repo *CategoryRepository
+	repo  *CategoryRepository
+	taxes *tax.Calculator
 }
 
-func NewCategoryHandler(r *gin.RouterGroup, repo *CategoryRepository, cfg *config.Config) {
-	h := &categoryHandler{repo: repo}
+func NewCategoryHandler(r *gin.RouterGroup, repo *CategoryRepository, taxes *tax.Calculator, cfg *config.Config) {
+	h := &categoryHandler{repo: repo,
+		taxes: taxes}
 
 	r.GET("/", h.getAll)
 	r.POST("/create", middleware.AdminAuthMiddleware(cfg.JWTConfig.SecretKey), h.create)
@@ -55,7 +59,7 @@ func (ch *categoryHandler) getProductsByCategoryName(c *gin.Context) {
 		response.RespondWithError(c, err)
 		return
 	}
-	response.RespondWithJson(c, http.StatusOK, product.ProductsToResponse(&category.Products))
+	response.RespondWithJson(c, http.StatusOK, product.ProductsToResponse(&category.Products, currency.RateFromCtx(c), ch.taxes))
 }
 
 // createFromFile reads data from a csv file and create categories from it
@@ -0,0 +1,133 @@
+package coupon
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/httpErrors"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Coupons applies the coupons to the carts and counts their usage by the orders
+type Coupons struct {
+	repo *CouponRepository
+	now  func() time.Time
+}
+
+func NewCoupons(repo *CouponRepository) *Coupons {
+	return &Coupons{repo: repo,
+		now: time.Now}
+}
+
+// WithTx returns a copy of the coupons that runs its queries in the given transaction
+func (cs *Coupons) WithTx(tx *gorm.DB) *Coupons {
+	if cs == nil {
+		return nil
+	}
+	return &Coupons{repo: cs.repo.WithTx(tx),
+		now: cs.now}
+}
+
+// Apply applies a coupon to a cart in place of the coupon it has and returns its discount for the items
+// note that the coupon is rejected when it does not apply to the items or the user has used it up
+func (cs *Coupons) Apply(cartID, userID uuid.UUID, code string, items []models.Item) (*Discount, error) {
+	zap.L().Debug("coupon.Apply", zap.Reflect("cartID", cartID), zap.Reflect("code", code))
+
+	c, err := cs.repo.getByCode(normalizeCode(code))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, httpErrors.NewApiError(http.StatusBadRequest, fmt.Sprintf("Coupon %s is not valid", code), err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	d, err := Evaluate(c, items, cs.now())
+	if err != nil {
+		return nil, httpErrors.NewApiError(http.StatusBadRequest, err.Error(), err)
+	}
+	if err := cs.checkUserLimit(c, userID); err != nil {
+		return nil, err
+	}
+	if err := cs.repo.attach(cartID, c.ID); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Remove removes the coupon of a cart
+func (cs *Coupons) Remove(cartID uuid.UUID) error {
+	return cs.repo.detach(cartID)
+}
+
+// Discount returns the discount of the coupon applied to a cart for its items
+// note that the discount is nil when the cart has no coupon, and it has a reason and no lines when the coupon does not apply to the items anymore
+// the coupon is kept on the cart in that case, so it applies again once the items change
+func (cs *Coupons) Discount(cartID uuid.UUID, items []models.Item) (*Discount, error) {
+	if cs == nil {
+		return nil, nil
+	}
+	c, err := cs.repo.getOfCart(cartID)
+	if err != nil || c == nil {
+		return nil, err
+	}
+	d, err := Evaluate(c, items, cs.now())
+	if err != nil {
+		return notApplied(c, err.Error()), nil
+	}
+	return d, nil
+}
+
+// Redeem counts the usage of a coupon by an order and removes it from the cart
+// note that the coupon is locked while its limits are checked again, so concurrent orders cannot use it beyond its limits
+// it should run in the transaction of the order, so the usage is not counted for an order that is not placed
+func (cs *Coupons) Redeem(d *Discount, cartID, userID, orderID uuid.UUID) error {
+	zap.L().Debug("coupon.Redeem", zap.Reflect("orderID", orderID))
+	if d == nil {
+		return nil
+	}
+	c, err := cs.repo.getForUpdate(d.Coupon.ID)
+	if err != nil {
+		return err
+	}
+	if c.UsageLimit > 0 && c.UsedCount >= c.UsageLimit {
+		return httpErrors.NewApiError(http.StatusBadRequest, fmt.Sprintf("Coupon %s is used up", c.Code), nil)
+	}
+	if err := cs.checkUserLimit(c, userID); err != nil {
+		return err
+	}
+	if err := cs.repo.recordUsage(c, &models.CouponUsage{CouponID: c.ID, UserID: userID, OrderID: orderID}); err != nil {
+		return err
+	}
+	return cs.repo.detach(cartID)
+}
+
+// checkUserLimit checks if a user can use a coupon once more
+func (cs *Coupons) checkUserLimit(c *models.Coupon, userID uuid.UUID) error {
+	if c.PerUserLimit <= 0 {
+		return nil
+	}
+	used, err := cs.repo.countUsages(c.ID, userID)
+	if err != nil {
+		return err
+	}
+	if used >= c.PerUserLimit {
+		return httpErrors.NewApiError(http.StatusBadRequest, fmt.Sprintf("You have already used coupon %s", c.Code), nil)
+	}
+	return nil
+}
+
+// notApplied returns the discount of a coupon that does not apply to the items for the given reason
+func notApplied(c *models.Coupon, reason string) *Discount {
+	return &Discount{Coupon: c, Lines: map[uuid.UUID]money.Money{}, Reason: reason}
+}
+
+// normalizeCode formats a coupon code as it is stored, so the codes are not case sensitive
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
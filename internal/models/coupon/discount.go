@@ -0,0 +1,173 @@
+package coupon
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/tax"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/google/uuid"
+)
+
+// kinds of coupons
+const (
+	KindPercentage   = "percentage"
+	KindFixed        = "fixed"
+	KindFreeShipping = "free_shipping"
+)
+
+// basisPoints is 100% in basis points
+const basisPoints = 10000
+
+// Discount is what a coupon takes off the items of a cart
+// note that the discount of the items is split over the items the coupon applies to, so that their tax is calculated after it
+// Reason explains why the coupon gives no discount when it does not apply to the items
+type Discount struct {
+	Coupon       *models.Coupon
+	Lines        map[uuid.UUID]money.Money
+	Total        money.Money
+	FreeShipping bool
+	Reason       string
+}
+
+// Description describes the discount of a coupon for the customers, such as "15% off" or "Free shipping"
+func Description(c *models.Coupon) string {
+	switch c.Kind {
+	case KindPercentage:
+		return fmt.Sprintf("%s%% off", tax.FormatRate(c.Percent))
+	case KindFixed:
+		return fmt.Sprintf("%s off", c.Amount)
+	case KindFreeShipping:
+		return "Free shipping"
+	}
+	return c.Kind
+}
+
+// Evaluate calculates the discount of a coupon for the items of a cart at the given time
+// note that the error explains why the coupon does not apply, while the per user limit is checked when the coupon is applied and used
+// the coupon applies to the prices of the items after the discounts they already have, such as their promotions
+func Evaluate(c *models.Coupon, items []models.Item, now time.Time) (*Discount, error) {
+	if c.StartsAt != nil && now.Before(*c.StartsAt) {
+		return nil, fmt.Errorf("Coupon %s is not active yet", c.Code)
+	}
+	if c.EndsAt != nil && !now.Before(*c.EndsAt) {
+		return nil, fmt.Errorf("Coupon %s is expired", c.Code)
+	}
+	if c.UsageLimit > 0 && c.UsedCount >= c.UsageLimit {
+		return nil, fmt.Errorf("Coupon %s is used up", c.Code)
+	}
+
+	var subtotal money.Money
+	var eligible []*models.Item
+	for i := range items {
+		var err error
+		if subtotal, err = subtotal.Add(items[i].Net()); err != nil {
+			return nil, err
+		}
+		if inScope(c, &items[i]) {
+			eligible = append(eligible, &items[i])
+		}
+	}
+	if !c.MinBasket.IsZero() {
+		below, err := subtotal.LessThan(c.MinBasket)
+		if err != nil {
+			return nil, err
+		}
+		if below {
+			return nil, fmt.Errorf("Coupon %s needs a cart of at least %s", c.Code, c.MinBasket)
+		}
+	}
+	if len(eligible) == 0 {
+		return nil, fmt.Errorf("Coupon %s does not apply to the items in your cart", c.Code)
+	}
+
+	d := &Discount{Coupon: c, Lines: make(map[uuid.UUID]money.Money, len(eligible))}
+	switch c.Kind {
+	case KindPercentage:
+		for _, i := range eligible {
+			d.Lines[i.ProductID] = i.Net().Percent(c.Percent)
+		}
+	case KindFixed:
+		allocate(d.Lines, eligible, c.Amount)
+	case KindFreeShipping:
+		d.FreeShipping = true
+	default:
+		return nil, errors.New("Coupon kind is not valid")
+	}
+
+	for _, line := range d.Lines {
+		var err error
+		if d.Total, err = d.Total.Add(line); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// WaiveShipping sets the cost of a shipping quote to zero when the discount gives free shipping, and returns the waived cost
+func (d *Discount) WaiveShipping(q *models.ShippingQuote) money.Money {
+	if d == nil || !d.FreeShipping || q == nil {
+		return money.Money{}
+	}
+	waived := q.Cost
+	q.Cost = money.New(0, q.Cost.Currency)
+	return waived
+}
+
+// OrderLines returns the lines of a discount to show on a cart or to keep on an order
+// note that the shipping cost that a free shipping coupon waives is given separately, since it is not part of the discount of the items
+func (d *Discount) OrderLines(waived money.Money) []models.OrderDiscount {
+	if d == nil || d.Reason != "" {
+		return nil
+	}
+	amount := d.Total
+	if d.FreeShipping {
+		amount = waived
+	}
+	return []models.OrderDiscount{{Code: d.Coupon.Code, Description: Description(d.Coupon), Amount: amount}}
+}
+
+// inScope checks if a coupon applies to an item by the category and the sku of its product
+func inScope(c *models.Coupon, i *models.Item) bool {
+	if len(c.Categories) == 0 && len(c.SKUs) == 0 {
+		return true
+	}
+	for _, sku := range c.SKUs {
+		if strings.EqualFold(sku, i.Product.Stock.SKU) {
+			return true
+		}
+	}
+	if i.Product.CategoryName != nil {
+		for _, category := range c.Categories {
+			if strings.EqualFold(category, *i.Product.CategoryName) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// allocate splits a fixed amount over the items in proportion to their prices
+// note that the amount is limited to the total of the items, and the last item takes the remainder of the rounding
+func allocate(lines map[uuid.UUID]money.Money, items []*models.Item, amount money.Money) {
+	var total int64
+	for _, i := range items {
+		total += i.Net().Amount
+	}
+	remaining := amount.Amount
+	if remaining > total {
+		remaining = total
+	}
+	left := remaining
+	for n, i := range items {
+		share := left
+		if n < len(items)-1 && total > 0 {
+			share = remaining * i.Net().Amount / total
+		}
+		lines[i.ProductID] = money.New(share, i.TotalPrice.Currency)
+		left -= share
+	}
+}
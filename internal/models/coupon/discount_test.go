@@ -0,0 +1,137 @@
+package coupon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2022, 4, 10, 10, 0, 0, 0, time.UTC)
+
+func testItem(sku, category string, total int64) models.Item {
+	return models.Item{
+		ProductID:  uuid.New(),
+		Product:    models.Product{CategoryName: &category, Stock: models.Stock{SKU: sku}},
+		TotalPrice: money.New(total, "USD"),
+	}
+}
+
+func TestEvaluate_Percentage(t *testing.T) {
+	items := []models.Item{testItem("BOOK-1", "Books", 1999), testItem("TOY-1", "Toys", 1000)}
+	c := &models.Coupon{Code: "BOOKS15", Kind: KindPercentage, Percent: 1500, Categories: []string{"books"}}
+
+	d, err := Evaluate(c, items, now)
+	require.NoError(t, err)
+	// 15% of 19.99 is 2.9985
+	assert.Equal(t, money.New(300, "USD"), d.Lines[items[0].ProductID])
+	assert.NotContains(t, d.Lines, items[1].ProductID)
+	assert.Equal(t, money.New(300, "USD"), d.Total)
+	assert.Equal(t, "15% off", Description(c))
+}
+
+func TestEvaluate_Fixed(t *testing.T) {
+	items := []models.Item{testItem("SKU-1", "Toys", 1000), testItem("SKU-2", "Toys", 2000), testItem("SKU-3", "Toys", 3000)}
+	c := &models.Coupon{Code: "TEN", Kind: KindFixed, Amount: money.New(1000, "USD"), SKUs: []string{"sku-1", "SKU-2"}}
+
+	d, err := Evaluate(c, items, now)
+	require.NoError(t, err)
+	assert.Equal(t, money.New(333, "USD"), d.Lines[items[0].ProductID])
+	assert.Equal(t, money.New(667, "USD"), d.Lines[items[1].ProductID])
+	assert.NotContains(t, d.Lines, items[2].ProductID)
+	assert.Equal(t, money.New(1000, "USD"), d.Total)
+}
+
+func TestEvaluate_FixedAboveItems(t *testing.T) {
+	items := []models.Item{testItem("SKU-1", "Toys", 500)}
+	c := &models.Coupon{Code: "TEN", Kind: KindFixed, Amount: money.New(1000, "USD")}
+
+	d, err := Evaluate(c, items, now)
+	require.NoError(t, err)
+	assert.Equal(t, money.New(500, "USD"), d.Total)
+}
+
+func TestEvaluate_FreeShipping(t *testing.T) {
+	items := []models.Item{testItem("SKU-1", "Toys", 500)}
+	c := &models.Coupon{Code: "SHIP", Kind: KindFreeShipping}
+
+	d, err := Evaluate(c, items, now)
+	require.NoError(t, err)
+	assert.True(t, d.FreeShipping)
+	assert.True(t, d.Total.IsZero())
+
+	quote := &models.ShippingQuote{Zone: "domestic", Cost: money.New(499, "USD")}
+	waived := d.WaiveShipping(quote)
+	assert.Equal(t, money.New(499, "USD"), waived)
+	assert.True(t, quote.Cost.IsZero())
+
+	lines := d.OrderLines(waived)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "SHIP", lines[0].Code)
+	assert.Equal(t, "Free shipping", lines[0].Description)
+	assert.Equal(t, money.New(499, "USD"), lines[0].Amount)
+}
+
+func TestEvaluate_NotApplicable(t *testing.T) {
+	before, after := now.Add(-time.Hour), now.Add(time.Hour)
+	items := []models.Item{testItem("SKU-1", "Toys", 1000)}
+
+	tests := []struct {
+		name   string
+		coupon models.Coupon
+		reason string
+	}{
+		{"not started", models.Coupon{StartsAt: &after}, "Coupon X is not active yet"},
+		{"expired", models.Coupon{EndsAt: &before}, "Coupon X is expired"},
+		{"ends now", models.Coupon{EndsAt: &now}, "Coupon X is expired"},
+		{"used up", models.Coupon{UsageLimit: 5, UsedCount: 5}, "Coupon X is used up"},
+		{"below minimum", models.Coupon{MinBasket: money.New(2000, "USD")}, "Coupon X needs a cart of at least 20.00 USD"},
+		{"out of scope", models.Coupon{Categories: []string{"Books"}}, "Coupon X does not apply to the items in your cart"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.coupon
+			c.Code, c.Kind, c.Percent = "X", KindPercentage, 1000
+			_, err := Evaluate(&c, items, now)
+			require.Error(t, err)
+			assert.Equal(t, tt.reason, err.Error())
+		})
+	}
+}
+
+func TestOrderLines_NotApplied(t *testing.T) {
+	d := notApplied(&models.Coupon{Code: "X"}, "Coupon X is expired")
+	assert.Nil(t, d.OrderLines(money.New(0, "")))
+
+	var none *Discount
+	assert.Nil(t, none.OrderLines(money.New(0, "")))
+	assert.True(t, none.WaiveShipping(&models.ShippingQuote{Cost: money.New(499, "USD")}).IsZero())
+}
+
+func TestValidateCoupon(t *testing.T) {
+	valid := models.Coupon{Code: "X", Kind: KindPercentage, Percent: 1500, Amount: money.New(0, ""), MinBasket: money.New(0, "")}
+	require.NoError(t, validateCoupon(&valid))
+
+	tests := []struct {
+		name   string
+		modify func(c *models.Coupon)
+	}{
+		{"unknown kind", func(c *models.Coupon) { c.Kind = "bogo" }},
+		{"percent above 100", func(c *models.Coupon) { c.Percent = 10001 }},
+		{"fixed without amount", func(c *models.Coupon) { c.Kind = KindFixed }},
+		{"foreign currency", func(c *models.Coupon) { c.MinBasket = money.New(1000, "EUR") }},
+		{"ends before it starts", func(c *models.Coupon) { c.StartsAt, c.EndsAt = &now, &now }},
+		{"negative limit", func(c *models.Coupon) { c.PerUserLimit = -1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid
+			tt.modify(&c)
+			assert.Error(t, validateCoupon(&c))
+		})
+	}
+}
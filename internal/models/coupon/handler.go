@@ -0,0 +1,146 @@
+package coupon
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/api"
+	"github.com/cagrikilicoglu/shopping-basket/internal/httpErrors"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/response"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/middleware"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/pagination"
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/strfmt"
+	"go.uber.org/zap"
+)
+
+type couponHandler struct {
+	repo *CouponRepository
+}
+
+func NewCouponHandler(r *gin.RouterGroup, repo *CouponRepository, cfg *config.Config) {
+	h := &couponHandler{repo: repo}
+
+	r.GET("/admin/coupons", middleware.AdminAuthMiddleware(cfg.JWTConfig.SecretKey), h.getAll)
+	r.POST("/admin/coupons", middleware.AdminAuthMiddleware(cfg.JWTConfig.SecretKey), h.create)
+	r.PUT("/admin/coupons/code/:code", middleware.AdminAuthMiddleware(cfg.JWTConfig.SecretKey), h.update)
+	r.DELETE("/admin/coupons/code/:code", middleware.AdminAuthMiddleware(cfg.JWTConfig.SecretKey), h.delete)
+}
+
+// getAll fetches the coupons and paginates the results
+func (ch *couponHandler) getAll(c *gin.Context) {
+	pageIndex, pageSize := pagination.GetPaginationParametersFromRequest(c)
+	zap.L().Debug("coupon.handler.getAll", zap.Reflect("pageIndex", pageIndex), zap.Reflect("pageSize", pageSize))
+
+	coupons, count, err := ch.repo.getAll(pageIndex, pagination.ClampPageSize(pageSize))
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	paginatedResult := pagination.NewFromGinRequest(c, count, couponsToResponse(coupons))
+	response.RespondWithJson(c, http.StatusOK, paginatedResult)
+}
+
+// create creates a coupon by the input in request body
+func (ch *couponHandler) create(c *gin.Context) {
+	zap.L().Debug("coupon.handler.create")
+
+	coupon, err := bindCoupon(c)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	created, err := ch.repo.create(coupon)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	response.RespondWithJson(c, http.StatusCreated, couponToResponse(created))
+}
+
+// update updates the terms of a coupon by the input in request body
+// note that the code in the path is kept, so a coupon cannot be renamed after customers have used it
+func (ch *couponHandler) update(c *gin.Context) {
+	code := normalizeCode(c.Param("code"))
+	zap.L().Debug("coupon.handler.update", zap.Reflect("code", code))
+
+	coupon, err := bindCoupon(c)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	coupon.Code = code
+
+	updated, err := ch.repo.update(code, coupon)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	response.RespondWithJson(c, http.StatusOK, couponToResponse(updated))
+}
+
+// delete deletes a coupon
+func (ch *couponHandler) delete(c *gin.Context) {
+	code := normalizeCode(c.Param("code"))
+	zap.L().Debug("coupon.handler.delete", zap.Reflect("code", code))
+
+	if err := ch.repo.deleteByCode(code); err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	response.RespondWithJson(c, http.StatusOK, "Coupon successfully deleted")
+}
+
+// bindCoupon binds and validates the coupon in the request body
+func bindCoupon(c *gin.Context) (*models.Coupon, error) {
+	couponBody := &api.Coupon{}
+	if err := c.Bind(&couponBody); err != nil {
+		return nil, err
+	}
+	if err := couponBody.Validate(strfmt.NewFormats()); err != nil {
+		return nil, err
+	}
+
+	coupon := responseToCoupon(couponBody)
+	if err := validateCoupon(coupon); err != nil {
+		return nil, err
+	}
+	return coupon, nil
+}
+
+// validateCoupon checks that the terms of a coupon can be applied to the carts
+// note that the amounts should be in the base currency, since the prices of the products are kept in it
+func validateCoupon(cp *models.Coupon) error {
+	if cp.Code == "" {
+		return httpErrors.NewApiError(http.StatusBadRequest, "Coupon code should not be empty", nil)
+	}
+	switch cp.Kind {
+	case KindPercentage:
+		if cp.Percent <= 0 || cp.Percent > basisPoints {
+			return httpErrors.NewApiError(http.StatusBadRequest, "Percent should be greater than 0 and at most 100", nil)
+		}
+	case KindFixed:
+		if cp.Amount.Amount <= 0 {
+			return httpErrors.NewApiError(http.StatusBadRequest, "Amount should be greater than 0", nil)
+		}
+	case KindFreeShipping:
+	default:
+		return httpErrors.NewApiError(http.StatusBadRequest, "Coupon kind should be one of percentage, fixed, free_shipping", nil)
+	}
+	if cp.Amount.Currency != money.DefaultCurrency || cp.MinBasket.Currency != money.DefaultCurrency {
+		return httpErrors.NewApiError(http.StatusBadRequest, fmt.Sprintf("Coupon amounts should be given in the base currency %s", money.DefaultCurrency), nil)
+	}
+	if cp.MinBasket.Amount < 0 {
+		return httpErrors.NewApiError(http.StatusBadRequest, "Minimum basket should not be negative", nil)
+	}
+	if cp.StartsAt != nil && cp.EndsAt != nil && !cp.EndsAt.After(*cp.StartsAt) {
+		return httpErrors.NewApiError(http.StatusBadRequest, "Coupon should end after it starts", nil)
+	}
+	if cp.UsageLimit < 0 || cp.PerUserLimit < 0 {
+		return httpErrors.NewApiError(http.StatusBadRequest, "Usage limits should not be negative", nil)
+	}
+	return nil
+}
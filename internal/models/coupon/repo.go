@@ -0,0 +1,164 @@
+package coupon
+
+import (
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CouponRepository struct {
+	db *gorm.DB
+}
+
+func (cr *CouponRepository) Migration() {
+	cr.db.AutoMigrate(&models.Coupon{}, &models.CartCoupon{}, &models.CouponUsage{})
+}
+
+func NewCouponRepository(db *gorm.DB) *CouponRepository {
+	return &CouponRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in the given transaction
+func (cr *CouponRepository) WithTx(tx *gorm.DB) *CouponRepository {
+	return &CouponRepository{db: tx}
+}
+
+// create creates a coupon
+func (cr *CouponRepository) create(c *models.Coupon) (*models.Coupon, error) {
+	if err := cr.db.Create(c).Error; err != nil {
+		zap.L().Error("coupon.repo.create failed to create coupon", zap.Error(err))
+		return nil, err
+	}
+	return c, nil
+}
+
+// getAll fetches the coupons with pagination parameters, latest first
+func (cr *CouponRepository) getAll(pageIndex, pageSize int) (*[]models.Coupon, int, error) {
+	var coupons *[]models.Coupon
+	var count int64
+
+	query := cr.db.Model(&models.Coupon{}).Session(&gorm.Session{})
+	if err := query.Count(&count).Error; err != nil {
+		zap.L().Error("coupon.repo.getAll failed to count coupons", zap.Error(err))
+		return nil, -1, err
+	}
+	if err := query.Order("created_at desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&coupons).Error; err != nil {
+		zap.L().Error("coupon.repo.getAll failed to get coupons", zap.Error(err))
+		return nil, -1, err
+	}
+	return coupons, int(count), nil
+}
+
+// getByCode fetches a coupon by its code
+func (cr *CouponRepository) getByCode(code string) (*models.Coupon, error) {
+	var c *models.Coupon
+	if err := cr.db.Where("code = ?", code).First(&c).Error; err != nil {
+		zap.L().Error("coupon.repo.getByCode failed to get coupon", zap.Error(err))
+		return nil, err
+	}
+	return c, nil
+}
+
+// getForUpdate fetches a coupon by ID and locks it until the surrounding transaction ends
+func (cr *CouponRepository) getForUpdate(id uuid.UUID) (*models.Coupon, error) {
+	var c *models.Coupon
+	if err := cr.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&c).Error; err != nil {
+		zap.L().Error("coupon.repo.getForUpdate failed to get coupon", zap.Error(err))
+		return nil, err
+	}
+	return c, nil
+}
+
+// update updates the terms of a coupon by its code
+// note that the usage count is not changed, so a coupon can be edited while it is being used
+func (cr *CouponRepository) update(code string, c *models.Coupon) (*models.Coupon, error) {
+	existing, err := cr.getByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	c.ID, c.CreatedAt, c.UsedCount = existing.ID, existing.CreatedAt, existing.UsedCount
+	if err := cr.db.Model(existing).Select("*").Omit("id", "created_at", "deleted_at", "used_count").Updates(c).Error; err != nil {
+		zap.L().Error("coupon.repo.update failed to update coupon", zap.Error(err))
+		return nil, err
+	}
+	return c, nil
+}
+
+// deleteByCode deletes a coupon and removes it from the carts
+func (cr *CouponRepository) deleteByCode(code string) error {
+	c, err := cr.getByCode(code)
+	if err != nil {
+		return err
+	}
+	if err := cr.db.Where("coupon_id = ?", c.ID).Delete(&models.CartCoupon{}).Error; err != nil {
+		zap.L().Error("coupon.repo.deleteByCode failed to remove coupon from carts", zap.Error(err))
+		return err
+	}
+	if err := cr.db.Delete(c).Error; err != nil {
+		zap.L().Error("coupon.repo.deleteByCode failed to delete coupon", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// attach applies a coupon to a cart in place of the coupon it has
+func (cr *CouponRepository) attach(cartID, couponID uuid.UUID) error {
+	err := cr.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cart_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"coupon_id", "created_at"}),
+	}).Create(&models.CartCoupon{CartID: cartID, CouponID: couponID}).Error
+	if err != nil {
+		zap.L().Error("coupon.repo.attach failed to apply coupon", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// detach removes the coupon of a cart
+func (cr *CouponRepository) detach(cartID uuid.UUID) error {
+	if err := cr.db.Where("cart_id = ?", cartID).Delete(&models.CartCoupon{}).Error; err != nil {
+		zap.L().Error("coupon.repo.detach failed to remove coupon", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// getOfCart fetches the coupon applied to a cart, or nil when the cart has no coupon
+func (cr *CouponRepository) getOfCart(cartID uuid.UUID) (*models.Coupon, error) {
+	var coupons []models.Coupon
+	err := cr.db.Joins("JOIN cart_coupons ON cart_coupons.coupon_id = coupons.id").
+		Where("cart_coupons.cart_id = ?", cartID).Limit(1).Find(&coupons).Error
+	if err != nil {
+		zap.L().Error("coupon.repo.getOfCart failed to get coupon", zap.Error(err))
+		return nil, err
+	}
+	if len(coupons) == 0 {
+		return nil, nil
+	}
+	return &coupons[0], nil
+}
+
+// countUsages counts the orders of a user that used a coupon
+func (cr *CouponRepository) countUsages(couponID, userID uuid.UUID) (int, error) {
+	var count int64
+	if err := cr.db.Model(&models.CouponUsage{}).Where("coupon_id = ? AND user_id = ?", couponID, userID).Count(&count).Error; err != nil {
+		zap.L().Error("coupon.repo.countUsages failed to count usages", zap.Error(err))
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// recordUsage records the usage of a coupon by an order and counts it
+func (cr *CouponRepository) recordUsage(c *models.Coupon, u *models.CouponUsage) error {
+	if err := cr.db.Create(u).Error; err != nil {
+		zap.L().Error("coupon.repo.recordUsage failed to record usage", zap.Error(err))
+		return err
+	}
+	if err := cr.db.Model(c).UpdateColumn("used_count", gorm.Expr("used_count + 1")).Error; err != nil {
+		zap.L().Error("coupon.repo.recordUsage failed to count usage", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package coupon
+
+import (
+	"math"
+	"strings"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/api"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/response"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"go.uber.org/zap"
+)
+
+// responseToCoupon converts coupon response model to database model
+// note that the percentage is converted to basis points, so 15 is kept as 1500
+func responseToCoupon(ac *api.Coupon) *models.Coupon {
+	zap.L().Debug("coupon.serializer.responseToCoupon", zap.Reflect("code", ac.Code), zap.Reflect("kind", ac.Kind))
+
+	return &models.Coupon{
+		Code:         normalizeCode(*ac.Code),
+		Kind:         strings.ToLower(strings.TrimSpace(*ac.Kind)),
+		Percent:      int64(math.Round(ac.Percent * 100)),
+		Amount:       response.ResponseToMoney(ac.Amount),
+		Categories:   trimAll(ac.Categories),
+		SKUs:         trimAll(ac.Skus),
+		MinBasket:    response.ResponseToMoney(ac.MinBasket),
+		StartsAt:     response.ResponseToTime(ac.StartsAt),
+		EndsAt:       response.ResponseToTime(ac.EndsAt),
+		UsageLimit:   int(ac.UsageLimit),
+		PerUserLimit: int(ac.PerUserLimit),
+	}
+}
+
+// couponToResponse converts coupon database model to response model
+func couponToResponse(c *models.Coupon) *api.Coupon {
+	return &api.Coupon{
+		ID:           c.ID.String(),
+		Code:         &c.Code,
+		Kind:         &c.Kind,
+		Percent:      float64(c.Percent) / 100,
+		Amount:       response.MoneyToResponse(c.Amount),
+		Categories:   c.Categories,
+		Skus:         c.SKUs,
+		MinBasket:    response.MoneyToResponse(c.MinBasket),
+		StartsAt:     response.TimeToResponse(c.StartsAt),
+		EndsAt:       response.TimeToResponse(c.EndsAt),
+		UsageLimit:   int32(c.UsageLimit),
+		PerUserLimit: int32(c.PerUserLimit),
+		UsedCount:    int32(c.UsedCount),
+	}
+}
+
+// couponsToResponse converts coupon database model to response model as a batch
+func couponsToResponse(cs *[]models.Coupon) []*api.Coupon {
+	coupons := make([]*api.Coupon, 0)
+	for i := range *cs {
+		csDeref := *cs
+		coupons = append(coupons, couponToResponse(&csDeref[i]))
+	}
+	return coupons
+}
+
+// DiscountsToResponse converts discount lines database model to response model as a batch
+func DiscountsToResponse(ds []models.OrderDiscount, rate money.Rate) []*api.DiscountLine {
+	discounts := make([]*api.DiscountLine, 0)
+	for i := range ds {
+		discounts = append(discounts, &api.DiscountLine{
+			Code:        ds[i].Code,
+			Description: &ds[i].Description,
+			Amount:      response.MoneyToResponse(rate.Apply(ds[i].Amount)),
+		})
+	}
+	return discounts
+}
+
+// trimAll trims the spaces around the values and leaves out the empty ones
+func trimAll(values []string) []string {
+	trimmed := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			trimmed = append(trimmed, v)
+		}
+	}
+	return trimmed
+}
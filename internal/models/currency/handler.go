@@ -0,0 +1,105 @@
+package currency
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/api"
+	"github.com/cagrikilicoglu/shopping-basket/internal/httpErrors"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/response"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/middleware"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/strfmt"
+	"go.uber.org/zap"
+)
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type exchangeRateHandler struct {
+	repo *ExchangeRateRepository
+}
+
+func NewExchangeRateHandler(r *gin.RouterGroup, repo *ExchangeRateRepository, cfg *config.Config) {
+	h := &exchangeRateHandler{repo: repo}
+
+	r.GET("/exchange-rates", h.getAll)
+	r.PUT("/admin/exchange-rates/currency/:currency", middleware.AdminAuthMiddleware(cfg.JWTConfig.SecretKey), h.save)
+	r.DELETE("/admin/exchange-rates/currency/:currency", middleware.AdminAuthMiddleware(cfg.JWTConfig.SecretKey), h.delete)
+}
+
+// getAll fetches the exchange rates of all the supported currencies
+func (eh *exchangeRateHandler) getAll(c *gin.Context) {
+	zap.L().Debug("currency.handler.getAll")
+
+	rates, err := eh.repo.getAll()
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	response.RespondWithJson(c, http.StatusOK, exchangeRatesToResponse(rates))
+}
+
+// save creates or updates the exchange rate of a currency by the input in request body
+func (eh *exchangeRateHandler) save(c *gin.Context) {
+	currency, err := currencyFromParam(c)
+	zap.L().Debug("currency.handler.save", zap.Reflect("currency", currency))
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	body := &api.ExchangeRateUpdate{}
+	if err := c.Bind(&body); err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	if err := body.Validate(strfmt.NewFormats()); err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	value, err := money.ParseRate(*body.Rate)
+	if err != nil {
+		response.RespondWithError(c, httpErrors.NewApiError(http.StatusBadRequest, err.Error(), nil))
+		return
+	}
+
+	rate, err := eh.repo.save(&models.ExchangeRate{Currency: currency, Rate: value})
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	response.RespondWithJson(c, http.StatusOK, exchangeRateToResponse(rate))
+}
+
+// delete deletes the exchange rate of a currency
+func (eh *exchangeRateHandler) delete(c *gin.Context) {
+	currency, err := currencyFromParam(c)
+	zap.L().Debug("currency.handler.delete", zap.Reflect("currency", currency))
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	if err := eh.repo.delete(currency); err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	response.RespondWithJson(c, http.StatusOK, "Exchange rate successfully deleted")
+}
+
+// currencyFromParam parses the currency code in the path
+// note that the base currency is rejected, since its rate is always one
+func currencyFromParam(c *gin.Context) (string, error) {
+	currency := strings.ToUpper(c.Param("currency"))
+	if !currencyCode.MatchString(currency) {
+		return "", httpErrors.NewApiError(http.StatusBadRequest, "Currency should be a 3 letter ISO 4217 code", nil)
+	}
+	if currency == money.DefaultCurrency {
+		return "", httpErrors.NewApiError(http.StatusBadRequest, "Exchange rate of the base currency cannot be changed", nil)
+	}
+	return currency, nil
+}
@@ -0,0 +1,64 @@
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/httpErrors"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/response"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// QueryParam is the query parameter that selects the currency of the amounts in a response
+	QueryParam = "currency"
+	// Header is the request header that selects the currency when the query parameter is not given
+	Header = "Currency"
+
+	rateKey = "exchangeRate"
+)
+
+// Middleware resolves the requested currency to its exchange rate and puts it into the context
+// note that requests without a currency, or with the base currency, are served in the base currency
+func Middleware(repo *ExchangeRateRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requested := c.Query(QueryParam)
+		if requested == "" {
+			requested = c.GetHeader(Header)
+		}
+		requested = strings.ToUpper(strings.TrimSpace(requested))
+		if requested == "" || requested == money.DefaultCurrency {
+			c.Next()
+			return
+		}
+		zap.L().Debug("currency.middleware", zap.Reflect("currency", requested))
+
+		rate, err := repo.GetByCurrency(requested)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				err = httpErrors.NewApiError(http.StatusBadRequest, fmt.Sprintf("Currency %s is not supported", requested), nil)
+			}
+			response.RespondWithError(c, err)
+			c.Abort()
+			return
+		}
+		c.Set(rateKey, money.NewRate(rate.Currency, rate.Rate))
+		c.Next()
+	}
+}
+
+// RateFromCtx returns the exchange rate of the requested currency
+// note that it returns the identity rate when the base currency is requested
+func RateFromCtx(c *gin.Context) money.Rate {
+	if rate, ok := c.Get(rateKey); ok {
+		if r, ok := rate.(money.Rate); ok {
+			return r
+		}
+	}
+	return money.Rate{}
+}
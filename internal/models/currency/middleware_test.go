@@ -0,0 +1,77 @@
+package currency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func newTestRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock, *money.Rate) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db, PreferSimpleProtocol: true}), &gorm.Config{})
+	require.NoError(t, err)
+
+	var rate money.Rate
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/products", Middleware(NewExchangeRateRepository(gdb)), func(c *gin.Context) {
+		rate = RateFromCtx(c)
+		c.Status(http.StatusOK)
+	})
+	return r, mock, &rate
+}
+
+func get(r *gin.Engine, target, header string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if header != "" {
+		req.Header.Set(Header, header)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddleware_BaseCurrency(t *testing.T) {
+	r, mock, rate := newTestRouter(t)
+
+	w := get(r, "/products?currency="+money.DefaultCurrency, "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, rate.IsIdentity())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMiddleware_QueryOverridesHeader(t *testing.T) {
+	r, mock, rate := newTestRouter(t)
+	mock.ExpectQuery(`SELECT \* FROM "exchange_rates" WHERE currency = \$1`).
+		WithArgs("EUR").
+		WillReturnRows(sqlmock.NewRows([]string{"currency", "rate"}).AddRow("EUR", 920000))
+
+	w := get(r, "/products?currency=eur", "GBP")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, money.NewRate("EUR", 920000), *rate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMiddleware_UnsupportedCurrency(t *testing.T) {
+	r, mock, _ := newTestRouter(t)
+	mock.ExpectQuery(`SELECT \* FROM "exchange_rates" WHERE currency = \$1`).
+		WithArgs("JPY").
+		WillReturnRows(sqlmock.NewRows([]string{"currency", "rate"}))
+
+	w := get(r, "/products", "JPY")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Currency JPY is not supported")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,73 @@
+package currency
+
+import (
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExchangeRateRepository struct {
+	db *gorm.DB
+}
+
+func (er *ExchangeRateRepository) Migration() {
+	er.db.AutoMigrate(&models.ExchangeRate{})
+}
+
+func NewExchangeRateRepository(db *gorm.DB) *ExchangeRateRepository {
+	return &ExchangeRateRepository{db: db}
+}
+
+// getAll fetches all the exchange rates ordered by currency
+func (er *ExchangeRateRepository) getAll() (*[]models.ExchangeRate, error) {
+	zap.L().Debug("currency.repo.getAll")
+
+	var rates []models.ExchangeRate
+	if err := er.db.Order("currency").Find(&rates).Error; err != nil {
+		zap.L().Error("currency.repo.getAll failed to get exchange rates", zap.Error(err))
+		return nil, err
+	}
+	return &rates, nil
+}
+
+// GetByCurrency fetches the exchange rate of a currency
+func (er *ExchangeRateRepository) GetByCurrency(currency string) (*models.ExchangeRate, error) {
+	zap.L().Debug("currency.repo.GetByCurrency", zap.Reflect("currency", currency))
+
+	var rate models.ExchangeRate
+	if err := er.db.Where("currency = ?", currency).First(&rate).Error; err != nil {
+		zap.L().Error("currency.repo.GetByCurrency failed to get exchange rate", zap.Error(err))
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// save creates the exchange rate of a currency or updates it if it already exists
+func (er *ExchangeRateRepository) save(r *models.ExchangeRate) (*models.ExchangeRate, error) {
+	zap.L().Debug("currency.repo.save", zap.Reflect("rate", r))
+
+	if err := er.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "currency"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+	}).Create(r).Error; err != nil {
+		zap.L().Error("currency.repo.save failed to save exchange rate", zap.Error(err))
+		return nil, err
+	}
+	return r, nil
+}
+
+// delete deletes the exchange rate of a currency
+func (er *ExchangeRateRepository) delete(currency string) error {
+	zap.L().Debug("currency.repo.delete", zap.Reflect("currency", currency))
+
+	result := er.db.Where("currency = ?", currency).Delete(&models.ExchangeRate{})
+	if result.Error != nil {
+		zap.L().Error("currency.repo.delete failed to delete exchange rate", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package currency
+
+import (
+	"github.com/cagrikilicoglu/shopping-basket/internal/api"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/go-openapi/strfmt"
+	"go.uber.org/zap"
+)
+
+// exchangeRateToResponse converts exchange rate database model to response model
+func exchangeRateToResponse(r *models.ExchangeRate) *api.ExchangeRate {
+	zap.L().Debug("currency.serializer.exchangeRateToResponse", zap.Reflect("rate", r))
+
+	rate := money.FormatRate(r.Rate)
+	return &api.ExchangeRate{
+		Currency:  &r.Currency,
+		Rate:      &rate,
+		UpdatedAt: strfmt.DateTime(r.UpdatedAt),
+	}
+}
+
+// exchangeRatesToResponse converts exchange rate database model to response model as a batch
+func exchangeRatesToResponse(rs *[]models.ExchangeRate) []*api.ExchangeRate {
+	rates := make([]*api.ExchangeRate, 0)
+	for i := range *rs {
+		rsDeref := *rs
+		rates = append(rates, exchangeRateToResponse(&rsDeref[i]))
+	}
+	return rates
+}
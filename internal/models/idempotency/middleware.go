@@ -0,0 +1,205 @@
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/httpErrors"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/response"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// HeaderKey is the request header that carries the idempotency key
+	HeaderKey = "Idempotency-Key"
+	// HeaderReplayed is the response header that marks a replayed response
+	HeaderReplayed = "Idempotent-Replayed"
+
+	maxKeyLength  = 255
+	defaultWindow = 24 * time.Hour
+)
+
+var (
+	errKeyInUse     = httpErrors.NewApiError(http.StatusConflict, "A request with the same Idempotency-Key is still being processed", nil)
+	errKeyMismatch  = httpErrors.NewApiError(http.StatusUnprocessableEntity, "Idempotency-Key is already used with a different request", nil)
+	errKeyTooLong   = httpErrors.NewApiError(http.StatusBadRequest, fmt.Sprintf("Idempotency-Key cannot be longer than %d characters", maxKeyLength), nil)
+	errUserNotInCtx = errors.New("User data not found")
+)
+
+// Middleware makes the requests of a user with the same Idempotency-Key header run once
+// the first response is stored and replayed for the duplicates within the configured window
+// note that it must run after an authorization middleware, since keys are scoped to the user in the context
+func Middleware(repo *IdempotencyRepository, cfg config.IdempotencyConfig) gin.HandlerFunc {
+	window := time.Duration(cfg.WindowMins) * time.Minute
+	if window <= 0 {
+		window = defaultWindow
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(HeaderKey)
+		if key == "" {
+			c.Next()
+			return
+		}
+		zap.L().Debug("idempotency.middleware", zap.Reflect("key", key))
+
+		if len(key) > maxKeyLength {
+			abortWithError(c, errKeyTooLong)
+			return
+		}
+		userID, err := userIDFromCtx(c)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		hash, err := requestHash(c)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		record, err := acquire(repo, userID, key, hash, time.Now().Add(-window))
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		if record.Completed {
+			replay(c, record)
+			return
+		}
+
+		// the key is released unless the handlers respond without a server error, so the client can retry the request with the same key
+		// note that the release is deferred, so it also runs when a handler panics
+		keep := false
+		defer func() {
+			if keep {
+				return
+			}
+			if err := repo.release(record); err != nil {
+				zap.L().Error("idempotency.middleware failed to release key", zap.Error(err))
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// server errors are not stored
+		if recorder.Status() >= http.StatusInternalServerError {
+			return
+		}
+		keep = true
+		if err := repo.complete(record, recorder.Status(), recorder.body.Bytes()); err != nil {
+			zap.L().Error("idempotency.middleware failed to store response", zap.Error(err))
+		}
+	}
+}
+
+// acquire returns a new pending record for the key, or the completed record of a previous request with the same key
+// note that the records older than the given time are expired and replaced
+func acquire(repo *IdempotencyRepository, userID uuid.UUID, key, hash string, expiredBefore time.Time) (*models.IdempotencyRecord, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		record := &models.IdempotencyRecord{UserID: userID, Key: key, RequestHash: hash}
+		created, err := repo.create(record)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			return record, nil
+		}
+
+		existing, err := repo.getWithUserIDAndKey(userID, key)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// the record is released by its request in the meantime
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if existing.CreatedAt.Before(expiredBefore) {
+			if err := repo.deleteExpired(existing.ID, expiredBefore); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if existing.RequestHash != hash {
+			return nil, errKeyMismatch
+		}
+		if !existing.Completed {
+			return nil, errKeyInUse
+		}
+		return existing, nil
+	}
+	return nil, errKeyInUse
+}
+
+// replay writes the stored response of a completed record
+func replay(c *gin.Context, r *models.IdempotencyRecord) {
+	zap.L().Debug("idempotency.middleware.replay", zap.Reflect("id", r.ID), zap.Reflect("statusCode", r.StatusCode))
+
+	c.Header("code", strconv.Itoa(r.StatusCode))
+	c.Header(HeaderReplayed, "true")
+	c.Data(r.StatusCode, "application/json; charset=utf-8", r.ResponseBody)
+	c.Abort()
+}
+
+// requestHash creates a fingerprint of the method, path, query and body of the request
+// note that the body is restored after reading, so the handlers can still bind it
+func requestHash(c *gin.Context) (string, error) {
+	var body []byte
+	if c.Request.Body != nil {
+		var err error
+		body, err = io.ReadAll(c.Request.Body)
+		if err != nil {
+			return "", err
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n", c.Request.Method, c.Request.URL.Path, c.Request.URL.RawQuery)
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// userIDFromCtx parses the id of the authorized user from the context
+func userIDFromCtx(c *gin.Context) (uuid.UUID, error) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		return uuid.Nil, errUserNotInCtx
+	}
+	return uuid.Parse(fmt.Sprintf("%v", userID))
+}
+
+func abortWithError(c *gin.Context, err error) {
+	response.RespondWithError(c, err)
+	c.Abort()
+}
+
+// responseRecorder keeps a copy of the response body written by the handlers
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,158 @@
+package idempotency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var userID = uuid.New()
+
+func newTestRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock, *int) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db, PreferSimpleProtocol: true}), &gorm.Config{})
+	require.NoError(t, err)
+
+	calls := 0
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/order", func(c *gin.Context) {
+		c.Set("userID", userID.String())
+	}, Middleware(NewIdempotencyRepository(gdb), config.IdempotencyConfig{WindowMins: 60}), func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusOK, gin.H{"id": "order"})
+	})
+	return r, mock, &calls
+}
+
+func postOrder(r *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/order", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(HeaderKey, key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func hashOf(t *testing.T, body string) string {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/order", strings.NewReader(body))
+	hash, err := requestHash(c)
+	require.NoError(t, err)
+	return hash
+}
+
+func expectExisting(mock sqlmock.Sqlmock, hash string, completed bool, createdAt time.Time) {
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "idempotency_records"`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "idempotency_records" WHERE user_id = $1 AND key = $2`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "key", "request_hash", "completed", "status_code", "response_body", "created_at"}).
+			AddRow(uuid.New(), userID, "key-1", hash, completed, http.StatusCreated, []byte(`{"id":"first"}`), createdAt))
+}
+
+func TestMiddleware_WithoutKey(t *testing.T) {
+	r, mock, calls := newTestRouter(t)
+
+	w := postOrder(r, "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, *calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMiddleware_FirstRequest(t *testing.T) {
+	r, mock, calls := newTestRouter(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "idempotency_records"`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "idempotency_records" SET`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	w := postOrder(r, "key-1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, *calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMiddleware_Replay(t *testing.T) {
+	r, mock, calls := newTestRouter(t)
+	expectExisting(mock, hashOf(t, ""), true, time.Now())
+
+	w := postOrder(r, "key-1", "")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"id":"first"}`, w.Body.String())
+	assert.Equal(t, "true", w.Header().Get(HeaderReplayed))
+	assert.Equal(t, 0, *calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMiddleware_DifferentPayload(t *testing.T) {
+	r, mock, calls := newTestRouter(t)
+	expectExisting(mock, hashOf(t, `{"addressId":"other"}`), true, time.Now())
+
+	w := postOrder(r, "key-1", "")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, 0, *calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMiddleware_InProgress(t *testing.T) {
+	r, mock, calls := newTestRouter(t)
+	expectExisting(mock, hashOf(t, ""), false, time.Now())
+
+	w := postOrder(r, "key-1", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, 0, *calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMiddleware_PanicReleasesKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db, PreferSimpleProtocol: true}), &gorm.Config{})
+	require.NoError(t, err)
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.POST("/order", func(c *gin.Context) {
+		c.Set("userID", userID.String())
+	}, Middleware(NewIdempotencyRepository(gdb), config.IdempotencyConfig{WindowMins: 60}), func(c *gin.Context) {
+		panic("order failed")
+	})
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "idempotency_records"`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "idempotency_records" WHERE id = $1`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	w := postOrder(r, "key-1", "")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRequestHash(t *testing.T) {
+	assert.Equal(t, hashOf(t, `{"a":1}`), hashOf(t, `{"a":1}`))
+	assert.NotEqual(t, hashOf(t, `{"a":1}`), hashOf(t, `{"a":2}`))
+}
@@ -0,0 +1,80 @@
+package idempotency
+
+import (
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyRepository struct {
+	db *gorm.DB
+}
+
+func (ir *IdempotencyRepository) Migration() {
+	ir.db.AutoMigrate(&models.IdempotencyRecord{})
+}
+
+func NewIdempotencyRepository(db *gorm.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// create creates an idempotency record unless the user already has a record with the same key
+// note that it returns false when the key is already taken, so concurrent duplicates cannot both proceed
+func (ir *IdempotencyRepository) create(r *models.IdempotencyRecord) (bool, error) {
+	zap.L().Debug("idempotency.repo.create", zap.Reflect("userID", r.UserID), zap.Reflect("key", r.Key))
+
+	result := ir.db.Clauses(clause.OnConflict{DoNothing: true}).Create(r)
+	if result.Error != nil {
+		zap.L().Error("idempotency.repo.create failed to create record", zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// getWithUserIDAndKey fetches the idempotency record of a user with the given key
+func (ir *IdempotencyRepository) getWithUserIDAndKey(userID uuid.UUID, key string) (*models.IdempotencyRecord, error) {
+	zap.L().Debug("idempotency.repo.getWithUserIDAndKey", zap.Reflect("userID", userID), zap.Reflect("key", key))
+
+	var r models.IdempotencyRecord
+	if err := ir.db.Where("user_id = ? AND key = ?", userID, key).First(&r).Error; err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// complete stores the response of the request the record belongs to
+func (ir *IdempotencyRepository) complete(r *models.IdempotencyRecord, statusCode int, body []byte) error {
+	zap.L().Debug("idempotency.repo.complete", zap.Reflect("id", r.ID), zap.Reflect("statusCode", statusCode))
+
+	if err := ir.db.Model(r).Updates(map[string]interface{}{"completed": true, "status_code": statusCode, "response_body": body}).Error; err != nil {
+		zap.L().Error("idempotency.repo.complete failed to update record", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// release deletes a record whose request is not completed, so the key can be used again
+func (ir *IdempotencyRepository) release(r *models.IdempotencyRecord) error {
+	zap.L().Debug("idempotency.repo.release", zap.Reflect("id", r.ID))
+
+	if err := ir.db.Where("id = ?", r.ID).Delete(&models.IdempotencyRecord{}).Error; err != nil {
+		zap.L().Error("idempotency.repo.release failed to delete record", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// deleteExpired deletes the record with the given id if it is created before the given time
+func (ir *IdempotencyRepository) deleteExpired(id uuid.UUID, before time.Time) error {
+	zap.L().Debug("idempotency.repo.deleteExpired", zap.Reflect("id", id), zap.Reflect("before", before))
+
+	if err := ir.db.Where("id = ? AND created_at < ?", id, before).Delete(&models.IdempotencyRecord{}).Error; err != nil {
+		zap.L().Error("idempotency.repo.deleteExpired failed to delete record", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package invoice
+
+import (
+	"strings"
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/tax"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"go.uber.org/zap"
+)
+
+// Document keeps the data printed on an invoice, with the amounts formatted in the currency of the order
+type Document struct {
+	Number       string
+	IssuedAt     time.Time
+	OrderID      string
+	OrderDate    time.Time
+	ExchangeRate string
+	Seller       Party
+	Customer     Party
+	ShipTo       []string
+	Lines        []Line
+	TaxLines     []TaxLine
+	Subtotal     string
+	Shipping     string
+	Tax          string
+	Total        string
+}
+
+// Party is the seller or the customer of an invoice
+type Party struct {
+	Name      string
+	Email     string
+	Address   []string
+	TaxNumber string
+}
+
+// Line is an ordered item on an invoice
+type Line struct {
+	Description string
+	SKU         string
+	Quantity    uint
+	UnitPrice   string
+	TaxRate     string
+	Net         string
+	Tax         string
+}
+
+// TaxLine is the tax of the items that share a rate on an invoice
+type TaxLine struct {
+	Rate string
+	Net  string
+	Tax  string
+}
+
+// NewDocument collects the data of an invoice from its order
+// note that the items are taken from their snapshots, so the invoice does not change with the catalog,
+// and an invoice whose lines cannot be summed is refused, so it never prints a subtotal that does not match its lines
+func NewDocument(inv *models.Invoice, o *models.Order, cfg config.InvoiceConfig) (*Document, error) {
+	rate := o.Rate()
+	format := o.Format
+
+	d := &Document{
+		Number:    inv.Number,
+		IssuedAt:  inv.CreatedAt,
+		OrderID:   o.ID.String(),
+		OrderDate: o.CreatedAt,
+		Seller: Party{
+			Name:      cfg.SellerName,
+			Address:   cfg.SellerAddress,
+			TaxNumber: cfg.SellerTaxNumber,
+		},
+		Customer: Party{
+			Name:    o.BillingAddress.FullName,
+			Address: o.BillingAddress.Lines(),
+		},
+		ShipTo:   o.ShippingAddress.Lines(),
+		Shipping: format(o.Shipping.Cost),
+		Tax:      format(o.Tax),
+		Total:    format(o.TotalPrice),
+	}
+	if !rate.IsIdentity() {
+		d.ExchangeRate = "1 " + money.DefaultCurrency + " = " + money.FormatRate(rate.Value) + " " + rate.Currency
+	}
+	if o.User != nil {
+		if o.User.Email != nil {
+			d.Customer.Email = *o.User.Email
+		}
+		if d.Customer.Name == "" {
+			d.Customer.Name = strings.TrimSpace(o.User.FirstName + " " + o.User.LastName)
+		}
+	}
+
+	var subtotal money.Money
+	for i := range o.Items {
+		item := &o.Items[i]
+		d.Lines = append(d.Lines, Line{
+			Description: item.Snapshot.Name,
+			SKU:         item.Snapshot.SKU,
+			Quantity:    item.Quantity,
+			UnitPrice:   format(item.Snapshot.UnitPrice),
+			TaxRate:     tax.FormatRate(item.TaxRate) + "%",
+			Net:         format(item.TotalPrice),
+			Tax:         format(item.Tax),
+		})
+		var err error
+		if subtotal, err = subtotal.Add(item.TotalPrice); err != nil {
+			zap.L().Error("invoice.document.NewDocument failed to sum the lines", zap.Reflect("orderID", o.ID), zap.Error(err))
+			return nil, err
+		}
+	}
+	d.Subtotal = format(subtotal)
+
+	for i := range o.TaxLines {
+		d.TaxLines = append(d.TaxLines, TaxLine{
+			Rate: tax.FormatRate(o.TaxLines[i].Rate) + "%",
+			Net:  format(o.TaxLines[i].Net),
+			Tax:  format(o.TaxLines[i].Tax),
+		})
+	}
+	return d, nil
+}
@@ -0,0 +1,101 @@
+package invoice
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = config.InvoiceConfig{
+	NumberPrefix:    "INV",
+	SellerName:      "Shopping Basket Ltd.",
+	SellerAddress:   []string{"Main Street 1", "34000 Istanbul"},
+	SellerTaxNumber: "1234567890",
+}
+
+func testOrder() *models.Order {
+	return &models.Order{
+		CreatedAt:    time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC),
+		TotalPrice:   money.New(3299, money.DefaultCurrency),
+		Currency:     money.DefaultCurrency,
+		ExchangeRate: money.RateScale,
+		Items: []models.Item{{
+			Quantity:   2,
+			TotalPrice: money.New(2000, money.DefaultCurrency),
+			TaxRate:    1800,
+			Tax:        money.New(360, money.DefaultCurrency),
+			Snapshot: models.ProductSnapshot{
+				Name:      "Notebook",
+				SKU:       "NB-1",
+				UnitPrice: money.New(1000, money.DefaultCurrency),
+			},
+		}},
+		Shipping: models.ShippingQuote{Zone: "local", Cost: money.New(939, money.DefaultCurrency)},
+		Tax:      money.New(360, money.DefaultCurrency),
+		TaxLines: []models.OrderTaxLine{{
+			Rate: 1800,
+			Net:  money.New(2000, money.DefaultCurrency),
+			Tax:  money.New(360, money.DefaultCurrency),
+		}},
+		BillingAddress: models.PostalAddress{
+			FullName: "Jane Doe",
+			Line1:    "Elm Street 5",
+			City:     "Istanbul",
+			ZipCode:  "34010",
+			Country:  "TR",
+		},
+	}
+}
+
+func TestNumber(t *testing.T) {
+	assert.Equal(t, "INV-000042", NewInvoiceRepository(nil, "INV").number(42))
+	assert.Equal(t, "000007", NewInvoiceRepository(nil, "").number(7))
+}
+
+func TestNewDocument(t *testing.T) {
+	inv := &models.Invoice{Number: "INV-000001", CreatedAt: time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)}
+	doc, err := NewDocument(inv, testOrder(), testConfig)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Jane Doe", doc.Customer.Name)
+	assert.Equal(t, []string{"Elm Street 5", "34010 Istanbul", "TR"}, doc.Customer.Address)
+	require.Len(t, doc.Lines, 1)
+	assert.Equal(t, "18%", doc.Lines[0].TaxRate)
+	assert.Equal(t, money.New(2000, money.DefaultCurrency).String(), doc.Subtotal)
+	assert.Equal(t, money.New(3299, money.DefaultCurrency).String(), doc.Total)
+	assert.Empty(t, doc.ExchangeRate)
+}
+
+func TestNewDocument_MismatchedCurrencies(t *testing.T) {
+	inv := &models.Invoice{Number: "INV-000001", CreatedAt: time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)}
+	o := testOrder()
+	item := o.Items[0]
+	item.TotalPrice = money.New(1000, "EUR")
+	o.Items = append(o.Items, item)
+
+	_, err := NewDocument(inv, o, testConfig)
+	assert.Error(t, err)
+}
+
+func TestRender(t *testing.T) {
+	inv := &models.Invoice{Number: "INV-000001", CreatedAt: time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)}
+	doc, err := NewDocument(inv, testOrder(), testConfig)
+	require.NoError(t, err)
+
+	var html bytes.Buffer
+	require.NoError(t, RenderHTML(&html, doc))
+	assert.Contains(t, html.String(), "Invoice INV-000001")
+	assert.Contains(t, html.String(), "Jane Doe")
+	assert.Contains(t, html.String(), "Notebook")
+
+	var pdf bytes.Buffer
+	require.NoError(t, RenderPDF(&pdf, doc))
+	assert.True(t, bytes.HasPrefix(pdf.Bytes(), []byte("%PDF-")))
+	assert.Contains(t, pdf.String(), "(Invoice INV-000001)")
+}
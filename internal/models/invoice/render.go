@@ -0,0 +1,192 @@
+package invoice
+
+import (
+	"html/template"
+	"io"
+	"strconv"
+
+	"github.com/cagrikilicoglu/shopping-basket/pkg/pdf"
+)
+
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Invoice {{.Number}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; font-size: 13px; margin: 40px; }
+table { border-collapse: collapse; width: 100%; margin-top: 16px; }
+th, td { border-bottom: 1px solid #ccc; padding: 6px; text-align: left; }
+td.amount, th.amount { text-align: right; }
+.parties { display: flex; justify-content: space-between; margin-top: 24px; }
+</style>
+</head>
+<body>
+<h1>Invoice {{.Number}}</h1>
+<p>Issued on {{.IssuedAt.Format "2006-01-02"}} for order {{.OrderID}} placed on {{.OrderDate.Format "2006-01-02"}}</p>
+{{if .ExchangeRate}}<p>Exchange rate: {{.ExchangeRate}}</p>{{end}}
+<div class="parties">
+<div>
+<h3>Seller</h3>
+<div>{{.Seller.Name}}</div>
+{{range .Seller.Address}}<div>{{.}}</div>{{end}}
+{{if .Seller.TaxNumber}}<div>Tax number: {{.Seller.TaxNumber}}</div>{{end}}
+</div>
+<div>
+<h3>Bill to</h3>
+<div>{{.Customer.Name}}</div>
+{{if .Customer.Email}}<div>{{.Customer.Email}}</div>{{end}}
+{{range .Customer.Address}}<div>{{.}}</div>{{end}}
+</div>
+<div>
+<h3>Ship to</h3>
+{{range .ShipTo}}<div>{{.}}</div>{{end}}
+</div>
+</div>
+<table>
+<tr><th>Item</th><th>SKU</th><th class="amount">Quantity</th><th class="amount">Unit price</th><th class="amount">Tax rate</th><th class="amount">Net</th><th class="amount">Tax</th></tr>
+{{range .Lines}}<tr><td>{{.Description}}</td><td>{{.SKU}}</td><td class="amount">{{.Quantity}}</td><td class="amount">{{.UnitPrice}}</td><td class="amount">{{.TaxRate}}</td><td class="amount">{{.Net}}</td><td class="amount">{{.Tax}}</td></tr>
+{{end}}</table>
+{{if .TaxLines}}<table>
+<tr><th>Tax rate</th><th class="amount">Net</th><th class="amount">Tax</th></tr>
+{{range .TaxLines}}<tr><td>{{.Rate}}</td><td class="amount">{{.Net}}</td><td class="amount">{{.Tax}}</td></tr>
+{{end}}</table>{{end}}
+<table>
+<tr><td>Subtotal</td><td class="amount">{{.Subtotal}}</td></tr>
+<tr><td>Shipping</td><td class="amount">{{.Shipping}}</td></tr>
+<tr><td>Tax</td><td class="amount">{{.Tax}}</td></tr>
+<tr><th>Total</th><th class="amount">{{.Total}}</th></tr>
+</table>
+</body>
+</html>
+`
+
+var invoiceTemplate = template.Must(template.New("invoice").Parse(htmlTemplate))
+
+const (
+	margin     = 50
+	lineHeight = 14
+	fontSize   = 10
+)
+
+// RenderHTML writes an invoice as an html page
+func RenderHTML(w io.Writer, d *Document) error {
+	return invoiceTemplate.Execute(w, d)
+}
+
+// RenderPDF writes an invoice as a pdf document
+// note that a new page is started whenever the content reaches the bottom margin
+func RenderPDF(w io.Writer, d *Document) error {
+	p := &pdfWriter{doc: pdf.New()}
+	p.newPage()
+
+	p.text(margin, 18, true, "Invoice "+d.Number)
+	p.y -= 10
+	p.text(margin, fontSize, false, "Issued on "+d.IssuedAt.Format("2006-01-02")+" for order "+d.OrderID+" placed on "+d.OrderDate.Format("2006-01-02"))
+	if d.ExchangeRate != "" {
+		p.text(margin, fontSize, false, "Exchange rate: "+d.ExchangeRate)
+	}
+	p.y -= lineHeight
+
+	top := p.y
+	customer := []string{d.Customer.Name}
+	if d.Customer.Email != "" {
+		customer = append(customer, d.Customer.Email)
+	}
+	p.y = lowest(
+		p.party(margin, top, "Seller", append(append([]string{d.Seller.Name}, d.Seller.Address...), taxNumberLine(d.Seller.TaxNumber)...)),
+		p.party(220, top, "Bill to", append(customer, d.Customer.Address...)),
+		p.party(390, top, "Ship to", d.ShipTo),
+	)
+
+	columns := []float64{margin, 230, 310, 350, 410, 460, 510}
+	p.row(columns, true, "Item", "SKU", "Qty", "Unit price", "Tax rate", "Net", "Tax")
+	for _, l := range d.Lines {
+		p.row(columns, false, l.Description, l.SKU, uintToString(l.Quantity), l.UnitPrice, l.TaxRate, l.Net, l.Tax)
+	}
+	p.y -= lineHeight
+
+	if len(d.TaxLines) > 0 {
+		taxColumns := []float64{margin, 150, 250}
+		p.row(taxColumns, true, "Tax rate", "Net", "Tax")
+		for _, t := range d.TaxLines {
+			p.row(taxColumns, false, t.Rate, t.Net, t.Tax)
+		}
+		p.y -= lineHeight
+	}
+
+	totalColumns := []float64{380, 460}
+	p.row(totalColumns, false, "Subtotal", d.Subtotal)
+	p.row(totalColumns, false, "Shipping", d.Shipping)
+	p.row(totalColumns, false, "Tax", d.Tax)
+	p.row(totalColumns, true, "Total", d.Total)
+
+	_, err := p.doc.WriteTo(w)
+	return err
+}
+
+// pdfWriter keeps the cursor of a pdf document that is written from top to bottom
+type pdfWriter struct {
+	doc *pdf.Document
+	y   float64
+}
+
+func (p *pdfWriter) newPage() {
+	p.doc.AddPage()
+	p.y = pdf.PageHeight - margin
+}
+
+// text writes a line of text and moves the cursor below it
+func (p *pdfWriter) text(x, size float64, bold bool, s string) {
+	if p.y < margin {
+		p.newPage()
+	}
+	p.doc.Text(x, p.y, size, bold, s)
+	p.y -= size + 4
+}
+
+// row writes the cells of a table row at the given columns, and underlines the header rows
+func (p *pdfWriter) row(columns []float64, header bool, cells ...string) {
+	if p.y < margin {
+		p.newPage()
+	}
+	for i, cell := range cells {
+		p.doc.Text(columns[i], p.y, fontSize, header, cell)
+	}
+	if header {
+		p.doc.Line(columns[0], p.y-4, pdf.PageWidth-margin, p.y-4)
+	}
+	p.y -= lineHeight
+}
+
+// party writes a titled block of lines starting at the given position and returns the position below it
+func (p *pdfWriter) party(x, y float64, title string, lines []string) float64 {
+	p.doc.Text(x, y, fontSize+1, true, title)
+	for _, line := range lines {
+		y -= lineHeight
+		p.doc.Text(x, y, fontSize, false, line)
+	}
+	return y - lineHeight
+}
+
+// lowest returns the lowest of the given positions
+func lowest(ys ...float64) float64 {
+	y := ys[0]
+	for _, v := range ys[1:] {
+		if v < y {
+			y = v
+		}
+	}
+	return y
+}
+
+func taxNumberLine(taxNumber string) []string {
+	if taxNumber == "" {
+		return nil
+	}
+	return []string{"Tax number: " + taxNumber}
+}
+
+func uintToString(n uint) string {
+	return strconv.FormatUint(uint64(n), 10)
+}
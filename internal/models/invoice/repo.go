@@ -0,0 +1,78 @@
+package invoice
+
+import (
+	"fmt"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// counterName is the name of the counter that invoice sequence numbers are taken from
+const counterName = "invoice"
+
+type InvoiceRepository struct {
+	db     *gorm.DB
+	prefix string
+}
+
+func (ir *InvoiceRepository) Migration() {
+	ir.db.AutoMigrate(&models.Invoice{}, &models.InvoiceCounter{})
+}
+
+func NewInvoiceRepository(db *gorm.DB, prefix string) *InvoiceRepository {
+	return &InvoiceRepository{db: db, prefix: prefix}
+}
+
+// WithTx returns a copy of the repository that runs its queries in the given transaction
+func (ir *InvoiceRepository) WithTx(tx *gorm.DB) *InvoiceRepository {
+	return &InvoiceRepository{db: tx, prefix: ir.prefix}
+}
+
+// Issue gives the next invoice number to an order
+// note that the counter is incremented in the same transaction, so a rolled back order leaves no gap in the numbers
+func (ir *InvoiceRepository) Issue(orderID uuid.UUID) (*models.Invoice, error) {
+	zap.L().Debug("invoice.repo.Issue", zap.Reflect("orderID", orderID))
+
+	var invoice *models.Invoice
+	err := ir.db.Transaction(func(tx *gorm.DB) error {
+		// the upsert locks the counter row, so concurrent orders get consecutive numbers
+		var sequence int64
+		if err := tx.Raw(`INSERT INTO invoice_counters (name, value) VALUES (?, 1)
+			ON CONFLICT (name) DO UPDATE SET value = invoice_counters.value + 1 RETURNING value`, counterName).Scan(&sequence).Error; err != nil {
+			return err
+		}
+
+		invoice = &models.Invoice{
+			OrderID:  orderID,
+			Sequence: sequence,
+			Number:   ir.number(sequence),
+		}
+		return tx.Create(invoice).Error
+	})
+	if err != nil {
+		zap.L().Error("invoice.repo.Issue failed to issue invoice", zap.Error(err))
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// GetByOrderID fetches the invoice of an order
+func (ir *InvoiceRepository) GetByOrderID(orderID uuid.UUID) (*models.Invoice, error) {
+	zap.L().Debug("invoice.repo.GetByOrderID", zap.Reflect("orderID", orderID))
+
+	var invoice models.Invoice
+	if err := ir.db.Where("order_id = ?", orderID).First(&invoice).Error; err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// number formats a sequence number as an invoice number such as INV-000042
+func (ir *InvoiceRepository) number(sequence int64) string {
+	if ir.prefix == "" {
+		return fmt.Sprintf("%06d", sequence)
+	}
+	return fmt.Sprintf("%s-%06d", ir.prefix, sequence)
+}
@@ -2,21 +2,27 @@ package item
 
 import (
 	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/database"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Repository encapsulates the logic to access cart items from the data source.
 type Repository interface {
 	create(i *models.Item) (*models.Item, error)
 	getItemsInCart(cartID uuid.UUID) (*[]models.Item, error)
-	updateItemWithProductID(id, cartID uuid.UUID, quantity int, price float32) error
+	updateItemWithProductID(id, cartID uuid.UUID, quantity int, price money.Money) error
 	removeFromCart(i *models.Item) error
-	order(i *models.Item, orderID uuid.UUID) error
+	order(i *models.Item, orderID uuid.UUID, snapshot models.ProductSnapshot) error
 	deleteItemWithProductID(id, cartID uuid.UUID) error
 	getItemWithProductSKU(sku string, cartID uuid.UUID) (*models.Item, error)
 	getItemWithProductID(id, cartID uuid.UUID) (*models.Item, error)
+	getItemsInCartForUpdate(cartID uuid.UUID) (*[]models.Item, error)
+	withTx(tx *gorm.DB) Repository
+	transaction(fn func(tx *gorm.DB) error) error
 }
 
 type ItemRepository struct {
@@ -25,12 +31,36 @@ type ItemRepository struct {
 
 func (ir *ItemRepository) Migration() {
 	ir.db.AutoMigrate(&models.Item{})
+	if err := database.MigrateMoneyColumn(ir.db, "items", "total_price", "total_price_"); err != nil {
+		zap.L().Fatal("item.repo.Migration failed to migrate money column", zap.Error(err))
+	}
+	if err := database.MigrateMoneyColumn(ir.db, "items", "snapshot_unit_price", "snapshot_unit_price_"); err != nil {
+		zap.L().Fatal("item.repo.Migration failed to migrate money column", zap.Error(err))
+	}
+
+	// items ordered before snapshots are introduced take the current data of their products
+	ir.db.Exec(`UPDATE items SET snapshot_name = COALESCE(products.name, ''), snapshot_sku = products.sku, snapshot_unit_price_amount = products.price_amount, snapshot_unit_price_currency = products.price_currency, snapshot_category_name = COALESCE(products.category_name, '')
+		FROM products WHERE products.id = items.product_id AND items.order_id IS NOT NULL AND COALESCE(items.snapshot_sku, '') = ''`)
 }
 
 func NewItemRepository(db *gorm.DB) *ItemRepository {
 	return &ItemRepository{db: db}
 }
 
+//withTx returns a copy of the repository that runs its queries in the given transaction
+func (ir *ItemRepository) withTx(tx *gorm.DB) Repository {
+	return &ItemRepository{db: tx}
+}
+
+//transaction runs the given function in a database transaction
+func (ir *ItemRepository) transaction(fn func(tx *gorm.DB) error) error {
+	if err := ir.db.Transaction(fn); err != nil {
+		zap.L().Error("item.repo.transaction rolled back", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
 //create creates an item in the database
 func (ir *ItemRepository) create(i *models.Item) (*models.Item, error) {
 	zap.L().Debug("item.repo.create", zap.Reflect("item", i))
@@ -55,6 +85,19 @@ func (ir *ItemRepository) getItemsInCart(cartID uuid.UUID) (*[]models.Item, erro
 	return items, nil
 }
 
+//getItemsInCartForUpdate fetches all items in the cart by cartID and locks them until the surrounding transaction ends
+func (ir *ItemRepository) getItemsInCartForUpdate(cartID uuid.UUID) (*[]models.Item, error) {
+	zap.L().Debug("item.repo.getItemsInCartForUpdate", zap.Reflect("cartID", cartID))
+	var items *[]models.Item
+
+	result := ir.db.Clauses(clause.Locking{Strength: "UPDATE"}).Order("created_at").Where("is_ordered", false).Where(&models.Item{CartID: cartID}).Preload("Product").Find(&items)
+	if result.Error != nil {
+		zap.L().Error("item.repo.getItemsInCartForUpdate failed to get items", zap.Error(result.Error))
+		return nil, result.Error
+	}
+	return items, nil
+}
+
 //getItemWithProductID fetches an item by the productID from the database
 func (ir *ItemRepository) getItemWithProductID(id, cartID uuid.UUID) (*models.Item, error) {
 	zap.L().Debug("item.repo.GetItemByProductID", zap.Reflect("ID", id), zap.Reflect("cartID", cartID))
@@ -85,10 +128,10 @@ func (ir *ItemRepository) getItemWithProductSKU(sku string, cartID uuid.UUID) (*
 }
 
 //updateItemWithProductID updates an item in the database with quantity and price inputs
-func (ir *ItemRepository) updateItemWithProductID(id, cartID uuid.UUID, quantity int, price float32) error {
+func (ir *ItemRepository) updateItemWithProductID(id, cartID uuid.UUID, quantity int, price money.Money) error {
 	zap.L().Debug("item.repo.updateItemWithProductID", zap.Reflect("ID", id), zap.Reflect("cartID", cartID))
 
-	result := ir.db.Model(&models.Item{}).Preload("Product").Where(&models.Item{CartID: cartID, ProductID: id}).Where("is_ordered = ?", false).Select("quantity", "total_price").Updates(map[string]interface{}{"quantity": quantity, "total_price": price})
+	result := ir.db.Model(&models.Item{}).Preload("Product").Where(&models.Item{CartID: cartID, ProductID: id}).Where("is_ordered = ?", false).Select("quantity", "total_price_amount", "total_price_currency").Updates(map[string]interface{}{"quantity": quantity, "total_price_amount": price.Amount, "total_price_currency": price.Currency})
 
 	if err := result.Error; err != nil {
 		zap.L().Error("item.repo.updateItemWithProductID failed to update item", zap.Error(err))
@@ -109,12 +152,24 @@ func (ir *ItemRepository) removeFromCart(i *models.Item) error {
 	return nil
 }
 
-//order sets an orderID to an item
-func (ir *ItemRepository) order(i *models.Item, orderID uuid.UUID) error {
-
-	zap.L().Debug("item.repo.order", zap.Reflect("orderID", orderID))
-
-	if err := ir.db.Model(&i).Preload("Product").Select("order_id").Update("order_id", orderID).Error; err != nil {
+//order sets an orderID, the snapshot of its product, its tax and its discount to an item
+func (ir *ItemRepository) order(i *models.Item, orderID uuid.UUID, snapshot models.ProductSnapshot) error {
+
+	zap.L().Debug("item.repo.order", zap.Reflect("orderID", orderID), zap.Reflect("snapshot", snapshot))
+
+	if err := ir.db.Model(&i).Preload("Product").Select("order_id", "snapshot_name", "snapshot_sku", "snapshot_unit_price_amount", "snapshot_unit_price_currency", "snapshot_category_name", "tax_rate", "tax_amount", "tax_currency", "discount_amount", "discount_currency").Updates(map[string]interface{}{
+		"order_id":                     orderID,
+		"snapshot_name":                snapshot.Name,
+		"snapshot_sku":                 snapshot.SKU,
+		"snapshot_unit_price_amount":   snapshot.UnitPrice.Amount,
+		"snapshot_unit_price_currency": snapshot.UnitPrice.Currency,
+		"snapshot_category_name":       snapshot.CategoryName,
+		"tax_rate":                     i.TaxRate,
+		"tax_amount":                   i.Tax.Amount,
+		"tax_currency":                 i.Tax.Currency,
+		"discount_amount":              i.Discount.Amount,
+		"discount_currency":            i.Discount.Currency,
+	}).Error; err != nil {
 		zap.L().Error("item.repo.order", zap.Error(err))
 		return err
 	}
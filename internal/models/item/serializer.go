@@ -3,17 +3,58 @@ package item
 import (
 	"github.com/cagrikilicoglu/shopping-basket/internal/api"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/coupon"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models/product"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/response"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/tax"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
 	"go.uber.org/zap"
 )
 
 // ItemToResponse converts item database model to response model
-func ItemToResponse(i *models.Item) *api.Item {
+// note that the prices are displayed with the tax rate calculated for the item, not with the one of the catalog
+func ItemToResponse(i *models.Item, rate money.Rate, taxes *tax.Calculator) *api.Item {
 	zap.L().Debug("item.serializer.itemToResponse", zap.Reflect("item", i))
 	quantity := uint32(i.Quantity)
+	apiProduct := product.ProductToResponse(&i.Product, rate, nil)
+	apiProduct.Price = response.MoneyToResponse(rate.Apply(taxes.Display(i.Product.Price, i.TaxRate)))
 	return &api.Item{
-		Product:    product.ProductToResponse(&i.Product),
+		Product:    apiProduct,
 		Quantity:   &quantity,
-		TotalPrice: &i.TotalPrice,
+		TotalPrice: response.MoneyToResponse(rate.Apply(taxes.DisplayLine(i.TotalPrice, i.Tax))),
+		Tax:        response.MoneyToResponse(rate.Apply(money.New(i.Tax.Amount, i.Tax.Currency))),
+		TaxRate:    tax.FormatRate(i.TaxRate),
+		Discount:   discountToResponse(i.Discount, rate),
+		Discounts:  coupon.DiscountsToResponse(i.Discounts, rate),
 	}
 }
+
+// OrderedItemToResponse converts an ordered item database model to response model
+// note that the product is rendered from the snapshot taken at order time, not from the current catalog
+func OrderedItemToResponse(i *models.Item, rate money.Rate, taxes *tax.Calculator) *api.Item {
+	zap.L().Debug("item.serializer.OrderedItemToResponse", zap.Reflect("item", i))
+	quantity := uint32(i.Quantity)
+	return &api.Item{
+		Product: &api.Product{
+			Name:         &i.Snapshot.Name,
+			Price:        response.MoneyToResponse(rate.Apply(taxes.Display(i.Snapshot.UnitPrice, i.TaxRate))),
+			CategoryName: &i.Snapshot.CategoryName,
+			Stock: &api.Stock{
+				Sku: &i.Snapshot.SKU,
+			},
+		},
+		Quantity:   &quantity,
+		TotalPrice: response.MoneyToResponse(rate.Apply(taxes.DisplayLine(i.TotalPrice, i.Tax))),
+		Tax:        response.MoneyToResponse(rate.Apply(money.New(i.Tax.Amount, i.Tax.Currency))),
+		TaxRate:    tax.FormatRate(i.TaxRate),
+		Discount:   discountToResponse(i.Discount, rate),
+	}
+}
+
+// discountToResponse converts the discount of an item to response model, or nil when the item has no discount
+func discountToResponse(d money.Money, rate money.Rate) *api.Money {
+	if d.IsZero() {
+		return nil
+	}
+	return response.MoneyToResponse(rate.Apply(d))
+}
@@ -0,0 +1,56 @@
+package item
+
+import (
+	"testing"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/tax"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedItemToResponse(t *testing.T) {
+	name := "current name"
+	category := "current category"
+	i := &models.Item{
+		Product:    models.Product{Name: &name, Price: money.New(9900, "USD"), CategoryName: &category, Stock: models.Stock{SKU: "NEWSKU"}},
+		Quantity:   2,
+		TotalPrice: money.New(2000, "USD"),
+		Snapshot:   models.ProductSnapshot{Name: "ordered name", SKU: "OLDSKU", UnitPrice: money.New(1000, "USD"), CategoryName: "ordered category"},
+	}
+
+	res := OrderedItemToResponse(i, money.Rate{}, nil)
+	assert.Equal(t, "ordered name", *res.Product.Name)
+	assert.Equal(t, "OLDSKU", *res.Product.Stock.Sku)
+	assert.Equal(t, int64(1000), *res.Product.Price.Amount)
+	assert.Equal(t, "ordered category", *res.Product.CategoryName)
+	assert.Equal(t, uint32(2), *res.Quantity)
+	assert.Equal(t, int64(2000), *res.TotalPrice.Amount)
+}
+
+func TestOrderedItemToResponse_TaxInclusive(t *testing.T) {
+	taxes, err := tax.NewCalculator(config.TaxConfig{DisplayMode: tax.DisplayInclusive})
+	require.NoError(t, err)
+	i := &models.Item{
+		Quantity:   2,
+		TotalPrice: money.New(2000, "USD"),
+		Snapshot:   models.ProductSnapshot{Name: "ordered name", SKU: "OLDSKU", UnitPrice: money.New(1000, "USD")},
+		TaxRate:    1800,
+		Tax:        money.New(360, "USD"),
+	}
+
+	res := OrderedItemToResponse(i, money.Rate{}, taxes)
+	assert.Equal(t, int64(1180), *res.Product.Price.Amount)
+	assert.Equal(t, int64(2360), *res.TotalPrice.Amount)
+	assert.Equal(t, int64(360), *res.Tax.Amount)
+	assert.Equal(t, "18", res.TaxRate)
+}
+
+func TestSnapshotOf(t *testing.T) {
+	name := "test"
+	p := &models.Product{Name: &name, Price: money.New(1250, "USD"), Stock: models.Stock{SKU: "TESTSKU"}}
+
+	assert.Equal(t, models.ProductSnapshot{Name: "test", SKU: "TESTSKU", UnitPrice: money.New(1250, "USD")}, snapshotOf(p))
+}
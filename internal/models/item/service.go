@@ -1,61 +1,170 @@
 package item
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 
 	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/coupon"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models/product"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/promotion"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/reservation"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/tax"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type ItemService struct {
-	itemRepo    Repository
-	productRepo product.ProductRepository
+	itemRepo     Repository
+	productRepo  product.ProductRepository
+	taxes        *tax.Calculator
+	reservations *reservation.Reservations
+	coupons      *coupon.Coupons
+	promotions   *promotion.Promotions
 }
 
 type Service interface {
 	Create(c *gin.Context) (*models.Item, error)
-	Delete(c *gin.Context) (float32, error)
+	Delete(c *gin.Context) (money.Money, error)
 	CheckProduct(c *gin.Context) (bool, error)
-	Update(c *gin.Context) (float32, error)
-	CalculatePrice(c *gin.Context) (float32, error)
+	Update(c *gin.Context) (money.Money, error)
+	CalculatePrice(c *gin.Context) (money.Money, error)
+	Price(cartID uuid.UUID) (money.Money, error)
+	ApplyDiscounts(cartID uuid.UUID, items []models.Item) (*Discounts, error)
 
-	Order(c *gin.Context) error
+	Order(tx *gorm.DB, orderID, cartID uuid.UUID, region string, discounts map[uuid.UUID]money.Money) (*Ordered, error)
+	CalculateTax(items []models.Item, region string) ([]models.OrderTaxLine, money.Money, error)
 	getItemsFromCartID(c *gin.Context) (*[]models.Item, error)
 	parsedCartIdFromCtx(c *gin.Context) (uuid.UUID, error)
-	AddItem(c *gin.Context) (float32, error)
+	AddItem(c *gin.Context) (money.Money, error)
+	Revalidate(cartID uuid.UUID, items []models.Item) ([]CartWarning, error)
+	Reorder(tx *gorm.DB, cartID uuid.UUID, ordered []models.Item, maxItems int) ([]ReorderLine, error)
 }
 
-func NewItemService(repo Repository, productRepo product.ProductRepository) Service {
+// Discounts are what the promotions and the coupon of a cart take off its items
+// note that the coupon applies after the promotions, to the prices that they leave
+type Discounts struct {
+	Promotions []promotion.Applied
+	Coupon     *coupon.Discount
+	Total      money.Money
+}
+
+// Lines returns the discount of every item by its product
+// note that discounts in different currencies cannot be summed, so they fail the pricing instead of leaving a discount out
+func (d *Discounts) Lines() (map[uuid.UUID]money.Money, error) {
+	lines := map[uuid.UUID]money.Money{}
+	add := func(productID uuid.UUID, amount money.Money) error {
+		sum, err := lines[productID].Add(amount)
+		if err != nil {
+			return err
+		}
+		lines[productID] = sum
+		return nil
+	}
+	for _, a := range d.Promotions {
+		for productID, amount := range a.Lines {
+			if err := add(productID, amount); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if d.Coupon != nil {
+		for productID, amount := range d.Coupon.Lines {
+			if err := add(productID, amount); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return lines, nil
+}
+
+// OrderLines returns the lines of the promotions and the coupon to show on a cart or to keep on an order
+// note that the shipping cost that a free shipping coupon waives is given separately, since it is not part of the discount of the items
+func (d *Discounts) OrderLines(waived money.Money) []models.OrderDiscount {
+	lines := make([]models.OrderDiscount, 0, len(d.Promotions)+1)
+	for i := range d.Promotions {
+		lines = append(lines, d.Promotions[i].OrderLine(d.Promotions[i].Total))
+	}
+	return append(lines, d.Coupon.OrderLines(waived)...)
+}
+
+// Ordered is what the items of a cart come to once they are locked and ordered
+// note that the total is the sum of the items after their discounts, without their tax
+type Ordered struct {
+	Items    []models.Item
+	Total    money.Money
+	TaxLines []models.OrderTaxLine
+	Tax      money.Money
+}
+
+// results of a reordered line
+const (
+	ReorderAdded   = "added"
+	ReorderReduced = "reduced"
+	ReorderSkipped = "skipped"
+)
+
+// ReorderLine reports what is done with an ordered item while it is added to the cart again
+type ReorderLine struct {
+	SKU       string
+	Name      string
+	Requested uint
+	Added     uint
+	Result    string
+	Reason    string
+}
+
+// kinds of cart warnings
+const (
+	WarningPriceChanged      = "price_changed"
+	WarningUnavailable       = "unavailable"
+	WarningInsufficientStock = "insufficient_stock"
+)
+
+// CartWarning reports a line of a cart that has changed since it is added to the cart
+type CartWarning struct {
+	SKU     string
+	Name    string
+	Kind    string
+	Message string
+}
+
+func NewItemService(repo Repository, productRepo product.ProductRepository, taxes *tax.Calculator, reservations *reservation.Reservations, coupons *coupon.Coupons, promotions *promotion.Promotions) Service {
 	if repo == nil {
 		return nil
 	}
 
 	return &ItemService{itemRepo: repo,
-		productRepo: productRepo}
+		productRepo:  productRepo,
+		taxes:        taxes,
+		reservations: reservations,
+		coupons:      coupons,
+		promotions:   promotions}
 }
 
 //AddItem adds a new item to the cart and returns its updated total price
-func (is *ItemService) AddItem(c *gin.Context) (float32, error) {
+func (is *ItemService) AddItem(c *gin.Context) (money.Money, error) {
 	ok, err := is.CheckProduct(c)
 	if !ok {
 		if err != nil {
-			return -1, err
+			return money.Money{}, err
 		} else {
-			return -1, errors.New("Product with given sku is already in the cart, please update the quantity")
+			return money.Money{}, errors.New("Product with given sku is already in the cart, please update the quantity")
 		}
 	}
 	_, err = is.Create(c)
 	if err != nil {
-		return -1, err
+		return money.Money{}, err
 	}
 	totalPrice, err := is.CalculatePrice(c)
 	if err != nil {
-		return -1, err
+		return money.Money{}, err
 	}
 	return totalPrice, nil
 }
@@ -75,18 +184,175 @@ func (is *ItemService) getItemsFromCartID(c *gin.Context) (*[]models.Item, error
 	return items, nil
 }
 
-// CalculatePrice calculates total price of a cart
-func (is *ItemService) CalculatePrice(c *gin.Context) (float32, error) {
+// CalculatePrice calculates total price of a cart after the discount of its coupon
+// note that an empty cart costs zero in the default currency
+func (is *ItemService) CalculatePrice(c *gin.Context) (money.Money, error) {
 	zap.L().Debug("itemservice.CalculatePrice")
-	items, err := is.getItemsFromCartID(c)
+	cartID, err := is.parsedCartIdFromCtx(c)
+	if err != nil {
+		return money.Money{}, err
+	}
+	return is.Price(cartID)
+}
+
+// Price calculates total price of a cart by its ID after the discounts of its promotions and its coupon
+func (is *ItemService) Price(cartID uuid.UUID) (money.Money, error) {
+	items, err := is.itemRepo.getItemsInCart(cartID)
 	if err != nil {
-		return -1, err
+		return money.Money{}, err
 	}
-	var totalPrice float32
+	var totalPrice money.Money
 	for _, v := range *items {
-		totalPrice += v.TotalPrice
+		totalPrice, err = totalPrice.Add(v.TotalPrice)
+		if err != nil {
+			return money.Money{}, err
+		}
 	}
-	return totalPrice, nil
+	discounts, err := is.ApplyDiscounts(cartID, *items)
+	if err != nil {
+		return money.Money{}, err
+	}
+	return totalPrice.Sub(discounts.Total)
+}
+
+// Revalidate prices the items of a cart again by the current prices of their products and checks their stock, and returns a warning for every changed line
+// note that the repriced items are saved and updated in place, while the lines of deleted products and the lines above the stock are only flagged
+func (is *ItemService) Revalidate(cartID uuid.UUID, items []models.Item) ([]CartWarning, error) {
+	zap.L().Debug("itemservice.Revalidate", zap.Reflect("cartID", cartID))
+
+	warnings := make([]CartWarning, 0)
+	for i := range items {
+		available := uint(0)
+		// product of the item is not preloaded when it is deleted after being added to the cart
+		if items[i].Product.Stock.SKU != "" {
+			var err error
+			// the stock held by other carts cannot be ordered, while the holds of this cart are its own
+			available, err = is.reservations.Available(&items[i].Product, cartID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		price, lineWarnings := checkLine(&items[i], available)
+		warnings = append(warnings, lineWarnings...)
+		if price == items[i].TotalPrice {
+			continue
+		}
+		err := is.itemRepo.updateItemWithProductID(items[i].ProductID, cartID, int(items[i].Quantity), price)
+		if err != nil {
+			return nil, err
+		}
+		items[i].TotalPrice = price
+	}
+	return warnings, nil
+}
+
+// checkLine calculates the current price of an item and the warnings about it by the stock that is available to its cart
+// note that the price of an item whose product is deleted is kept, since there is no current price for it
+func checkLine(i *models.Item, available uint) (money.Money, []CartWarning) {
+	if i.Product.Stock.SKU == "" {
+		return i.TotalPrice, []CartWarning{{
+			Kind:    WarningUnavailable,
+			Message: "A product in your cart is not available anymore, please remove it from the cart",
+		}}
+	}
+
+	name := ""
+	if i.Product.Name != nil {
+		name = *i.Product.Name
+	}
+	warnings := make([]CartWarning, 0)
+	price := i.Product.Price.Mul(int64(i.Quantity))
+	if price != i.TotalPrice && i.Quantity > 0 {
+		previous := money.New(i.TotalPrice.Amount/int64(i.Quantity), i.TotalPrice.Currency)
+		warnings = append(warnings, CartWarning{SKU: i.Product.Stock.SKU, Name: name, Kind: WarningPriceChanged,
+			Message: fmt.Sprintf("The price of %s changed from %s to %s", name, previous, i.Product.Price)})
+	}
+	switch {
+	case available == 0:
+		warnings = append(warnings, CartWarning{SKU: i.Product.Stock.SKU, Name: name, Kind: WarningInsufficientStock,
+			Message: fmt.Sprintf("%s is out of stock, please remove it from the cart", name)})
+	case available < i.Quantity:
+		warnings = append(warnings, CartWarning{SKU: i.Product.Stock.SKU, Name: name, Kind: WarningInsufficientStock,
+			Message: fmt.Sprintf("Not enough %s in the stock, please request less than %d", name, available+1)})
+	}
+	return price, warnings
+}
+
+// ApplyDiscounts evaluates the promotions and the coupon of a cart, and sets their discounts to its items with an explanation per item
+// note that the promotions apply first and the coupon applies to the prices that they leave
+func (is *ItemService) ApplyDiscounts(cartID uuid.UUID, items []models.Item) (*Discounts, error) {
+	for i := range items {
+		items[i].Discount, items[i].Discounts = money.Money{}, nil
+	}
+	discounts := &Discounts{}
+
+	applied, err := is.promotions.Apply(items)
+	if err != nil {
+		return nil, err
+	}
+	for i := range applied {
+		err = discountItems(items, applied[i].Lines, func(amount money.Money) models.OrderDiscount { return applied[i].OrderLine(amount) })
+		if err != nil {
+			return nil, err
+		}
+		if discounts.Total, err = discounts.Total.Add(applied[i].Total); err != nil {
+			return nil, err
+		}
+	}
+	discounts.Promotions = applied
+
+	discounts.Coupon, err = is.coupons.Discount(cartID, items)
+	if err != nil || discounts.Coupon == nil {
+		return discounts, err
+	}
+	c := discounts.Coupon.Coupon
+	err = discountItems(items, discounts.Coupon.Lines, func(amount money.Money) models.OrderDiscount {
+		return models.OrderDiscount{Code: c.Code, Description: coupon.Description(c), Amount: amount}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if discounts.Total, err = discounts.Total.Add(discounts.Coupon.Total); err != nil {
+		return nil, err
+	}
+	return discounts, nil
+}
+
+// discountItems adds the discounts of the products to their items with the lines that explain them
+func discountItems(items []models.Item, lines map[uuid.UUID]money.Money, explain func(amount money.Money) models.OrderDiscount) error {
+	for i := range items {
+		amount, ok := lines[items[i].ProductID]
+		if !ok || amount.IsZero() {
+			continue
+		}
+		var err error
+		if items[i].Discount, err = items[i].Discount.Add(amount); err != nil {
+			return err
+		}
+		items[i].Discounts = append(items[i].Discounts, explain(amount))
+	}
+	return nil
+}
+
+// CalculateTax calculates the tax of every item for the region they are delivered to and groups it by rate
+// note that the tax of an item is calculated on its total price, so the rounding happens once per line
+func (is *ItemService) CalculateTax(items []models.Item, region string) ([]models.OrderTaxLine, money.Money, error) {
+	zap.L().Debug("itemservice.CalculateTax", zap.Reflect("region", region))
+	for i := range items {
+		is.applyTax(&items[i], items[i].Product.CategoryName, region)
+	}
+	return tax.Breakdown(items)
+}
+
+// applyTax sets the tax rate and the tax of an item by the category of its product
+// note that the tax is calculated on the price of the item after its discount
+func (is *ItemService) applyTax(i *models.Item, categoryName *string, region string) {
+	category := ""
+	if categoryName != nil {
+		category = *categoryName
+	}
+	i.TaxRate = is.taxes.RateFor(category, region)
+	i.Tax = tax.Of(i.Net(), i.TaxRate)
 }
 
 // CheckProduct checks if an item with the given product is existed in the cart
@@ -116,34 +382,30 @@ func (is *ItemService) Create(c *gin.Context) (*models.Item, error) {
 	quantity := c.Param("quantity")
 	zap.L().Debug("itemservice.Create", zap.Reflect("sku", sku), zap.Reflect("quantity", quantity))
 
-	product, err := is.productRepo.GetBySKU(sku)
-	if err != nil {
-		return nil, err
-	}
-
 	quantityInt, err := strconv.Atoi(quantity)
 	if err != nil {
 		return nil, errors.New("cannot parse quantity")
 	}
 
-	if product.Stock.Number < uint(quantityInt) {
-		return nil, fmt.Errorf("Not enough %s in the stock,please request less than %d", *product.Name, (product.Stock.Number + 1))
-	}
-
-	totalPrice := product.Price * float32(quantityInt)
 	parsedCartId, err := is.parsedCartIdFromCtx(c)
 	if err != nil {
 		return nil, err
 	}
 
-	itemToCreate := models.Item{
-		ProductID:  product.ID,
-		Product:    *product,
-		Quantity:   uint(quantityInt),
-		TotalPrice: totalPrice,
-		CartID:     parsedCartId,
-	}
-	item, err := is.itemRepo.create(&itemToCreate)
+	var item *models.Item
+	err = is.hold(sku, parsedCartId, uint(quantityInt), func(itemRepo Repository, product *models.Product) error {
+		totalPrice := product.Price.Mul(int64(quantityInt))
+
+		itemToCreate := models.Item{
+			ProductID:  product.ID,
+			Product:    *product,
+			Quantity:   uint(quantityInt),
+			TotalPrice: totalPrice,
+			CartID:     parsedCartId,
+		}
+		item, err = itemRepo.create(&itemToCreate)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -151,119 +413,353 @@ func (is *ItemService) Create(c *gin.Context) (*models.Item, error) {
 }
 
 // Update updates an item with productSKU and quantity inputs and returns updated total price of the cart
-func (is *ItemService) Update(c *gin.Context) (float32, error) {
+func (is *ItemService) Update(c *gin.Context) (money.Money, error) {
 	sku := c.Param("sku")
 	quantity := c.Param("quantity")
 	zap.L().Debug("itemservice.Update", zap.Reflect("sku", sku), zap.Reflect("quantity", quantity))
 
 	ok, err := is.CheckProduct(c)
 	if ok {
-		return -1, errors.New("Product with given sku is not in the cart, please add the product")
+		return money.Money{}, errors.New("Product with given sku is not in the cart, please add the product")
 	} else {
 		if err != nil {
-			return -1, err
+			return money.Money{}, err
 		}
 	}
 
-	product, err := is.productRepo.GetBySKU(sku)
-
 	quantityInt, err := strconv.Atoi(quantity)
 	if err != nil {
-		return -1, errors.New("cannot parse quantity")
-	}
-
-	if product.Stock.Number < uint(quantityInt) {
-		return -1, fmt.Errorf("Not enough %s in the stock, please request less than %d", *product.Name, (product.Stock.Number + 1))
+		return money.Money{}, errors.New("cannot parse quantity")
 	}
 
 	parsedCartId, err := is.parsedCartIdFromCtx(c)
 	if err != nil {
-		return -1, err
+		return money.Money{}, err
 	}
 
-	itemPrice := product.Price * float32(quantityInt)
-
-	err = is.itemRepo.updateItemWithProductID(product.ID, parsedCartId, quantityInt, itemPrice)
+	err = is.hold(sku, parsedCartId, uint(quantityInt), func(itemRepo Repository, product *models.Product) error {
+		itemPrice := product.Price.Mul(int64(quantityInt))
+		return itemRepo.updateItemWithProductID(product.ID, parsedCartId, quantityInt, itemPrice)
+	})
 	if err != nil {
-		return -1, err
+		return money.Money{}, err
 	}
 	totalPrice, err := is.CalculatePrice(c)
 	if err != nil {
-		return -1, err
+		return money.Money{}, err
 	}
 	return totalPrice, nil
 
 }
 
-// Order orders items in the cart by updating product stocks and clearing the cart
-func (is *ItemService) Order(c *gin.Context) error {
+// hold checks that the given quantity of a product is in the stock for a cart, saves the item by the given function and holds the quantity for the cart
+// note that when the stock is held for the carts, the product is locked and all the queries run in one transaction, so two carts cannot take the same units at once
+func (is *ItemService) hold(sku string, cartID uuid.UUID, quantity uint, save func(itemRepo Repository, product *models.Product) error) error {
+	run := func(itemRepo Repository, reservations *reservation.Reservations, product *models.Product) error {
+		// the stock held by other carts cannot be added
+		available, err := reservations.Available(product, cartID)
+		if err != nil {
+			return err
+		}
+		if available < quantity {
+			return fmt.Errorf("Not enough %s in the stock, please request less than %d", *product.Name, (available + 1))
+		}
+		if err := save(itemRepo, product); err != nil {
+			return err
+		}
+		return reservations.Hold(product.ID, cartID, quantity)
+	}
 
-	orderID, err := is.parsedOrderIdFromCtx(c)
-	zap.L().Debug("itemservice.Order", zap.Reflect("orderID", orderID))
-	if err != nil {
-		return err
+	if !is.reservations.Enabled() {
+		product, err := is.productRepo.GetBySKU(sku)
+		if err != nil {
+			return err
+		}
+		return run(is.itemRepo, is.reservations, product)
 	}
+	return is.itemRepo.transaction(func(tx *gorm.DB) error {
+		product, err := is.productRepo.WithTx(tx).GetBySKUForUpdate(sku)
+		if err != nil {
+			return err
+		}
+		return run(is.itemRepo.withTx(tx), is.reservations.WithTx(tx), product)
+	})
+}
 
-	items, err := is.getItemsFromCartID(c)
+// Order orders the items in a cart by updating product stocks and clearing the cart, and returns what the ordered items come to
+// note that all the queries run in the given transaction, so a failing item rolls back the whole order
+// the tax is calculated for the given region, after the given discounts of the products
+func (is *ItemService) Order(tx *gorm.DB, orderID, cartID uuid.UUID, region string, discounts map[uuid.UUID]money.Money) (*Ordered, error) {
+	zap.L().Debug("itemservice.Order", zap.Reflect("orderID", orderID), zap.Reflect("cartID", cartID), zap.Reflect("region", region))
+
+	itemRepo := is.itemRepo.withTx(tx)
+	productRepo := is.productRepo.WithTx(tx)
+	reservations := is.reservations.WithTx(tx)
+
+	// locking the cart items prevents the same cart from being ordered twice by concurrent requests
+	items, err := itemRepo.getItemsInCartForUpdate(cartID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if len(*items) == 0 {
+		return nil, errors.New("Your cart is empty")
 	}
 
-	for i := range *items {
-		itemsDeref := *items
+	// products are locked in a deterministic order so that concurrent checkouts and cancellations cannot deadlock
+	itemsDeref := *items
+	sort.Slice(itemsDeref, func(i, j int) bool {
+		return bytes.Compare(itemsDeref[i].ProductID[:], itemsDeref[j].ProductID[:]) < 0
+	})
+
+	for i := range itemsDeref {
 
 		sku := &itemsDeref[i].Product.Stock.SKU
 		quantity := &itemsDeref[i].Quantity
-		product, err := is.productRepo.GetBySKU(*sku)
+		// product of the item is not preloaded when it is deleted after being added to the cart
+		if *sku == "" {
+			return nil, errors.New("A product in your cart is not available anymore, please remove it from the cart")
+		}
+		product, err := productRepo.GetBySKUForUpdate(*sku)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		// the stock held by other carts cannot be ordered, while the holds of this cart are its own
+		available, err := reservations.Available(product, cartID)
+		if err != nil {
+			return nil, err
 		}
-		if product.Stock.Number < *quantity {
-			return fmt.Errorf("Not enough %s in the stock, please request less than %d", *product.Name, (product.Stock.Number + 1))
+		if available < *quantity {
+			return nil, fmt.Errorf("Not enough %s in the stock, please request less than %d", *product.Name, (available + 1))
 		}
 
-		err = is.productRepo.UpdateStock(*sku, *quantity)
+		err = productRepo.UpdateStock(*sku, *quantity)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		err = is.itemRepo.order(&itemsDeref[i], orderID)
+		itemsDeref[i].Discount = discounts[itemsDeref[i].ProductID]
+		is.applyTax(&itemsDeref[i], product.CategoryName, region)
+		err = itemRepo.order(&itemsDeref[i], orderID, snapshotOf(product))
 		if err != nil {
-			return err
+			return nil, err
 		}
-		err = is.itemRepo.removeFromCart(&itemsDeref[i])
+		err = itemRepo.removeFromCart(&itemsDeref[i])
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 	}
-	return nil
+	err = reservations.ReleaseCart(cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := &Ordered{Items: itemsDeref}
+	for i := range itemsDeref {
+		if ordered.Total, err = ordered.Total.Add(itemsDeref[i].Net()); err != nil {
+			return nil, err
+		}
+	}
+	if ordered.TaxLines, ordered.Tax, err = tax.Breakdown(itemsDeref); err != nil {
+		return nil, err
+	}
+	return ordered, nil
+}
+
+// Reorder adds the items of a past order to the cart and reports what is done with every line
+// note that the quantities are reduced to the current stock that is not held by other carts, and the lines of deleted or out of stock products are skipped as well as the new lines beyond maxItems
+// all the queries run in the given transaction, so the cart is left unchanged if any line fails
+func (is *ItemService) Reorder(tx *gorm.DB, cartID uuid.UUID, ordered []models.Item, maxItems int) ([]ReorderLine, error) {
+	zap.L().Debug("itemservice.Reorder", zap.Reflect("cartID", cartID), zap.Reflect("lines", len(ordered)))
+
+	itemRepo := is.itemRepo.withTx(tx)
+	productRepo := is.productRepo.WithTx(tx)
+	reservations := is.reservations.WithTx(tx)
+
+	// locking the cart items prevents concurrent requests from adding the same product twice
+	items, err := itemRepo.getItemsInCartForUpdate(cartID)
+	if err != nil {
+		return nil, err
+	}
+	inCart := make(map[string]*models.Item, len(*items))
+	for i := range *items {
+		inCart[(*items)[i].Product.Stock.SKU] = &(*items)[i]
+	}
+	lineCount := len(*items)
+
+	// the products are locked before their stock is checked, so two carts cannot take the same units at once
+	products, err := lockProducts(productRepo, ordered)
+	if err != nil {
+		return nil, err
+	}
+
+	report := make([]ReorderLine, 0, len(ordered))
+	for i := range ordered {
+		sku := ordered[i].Snapshot.SKU
+		var product *models.Product
+		if locked, ok := products[sku]; ok {
+			copied := *locked
+			product = &copied
+		}
+		// the stock held by other carts cannot be reordered
+		if product != nil {
+			product.Stock.Number, err = reservations.Available(product, cartID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		existing := inCart[sku]
+		line := planReorder(&ordered[i], product, existing, lineCount >= maxItems)
+		report = append(report, line)
+		if line.Added == 0 {
+			continue
+		}
+
+		if existing != nil {
+			existing.Quantity += line.Added
+			err = itemRepo.updateItemWithProductID(product.ID, cartID, int(existing.Quantity), product.Price.Mul(int64(existing.Quantity)))
+			if err != nil {
+				return nil, err
+			}
+			err = reservations.Hold(product.ID, cartID, existing.Quantity)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		created, err := itemRepo.create(&models.Item{
+			ProductID:  product.ID,
+			Product:    *product,
+			Quantity:   line.Added,
+			TotalPrice: product.Price.Mul(int64(line.Added)),
+			CartID:     cartID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		err = reservations.Hold(product.ID, cartID, line.Added)
+		if err != nil {
+			return nil, err
+		}
+		inCart[sku] = created
+		lineCount++
+	}
+	return report, nil
+}
+
+// lockProducts fetches the products of the ordered items by their SKUs and locks their rows until the transaction of the given repository ends
+// note that the rows are locked in the order of the product IDs as checkout does, so the two cannot deadlock,
+// and an empty sku would match any product, so a line without a snapshot counts as an unavailable product as the deleted products do
+func lockProducts(productRepo *product.ProductRepository, ordered []models.Item) (map[string]*models.Product, error) {
+	lines := make([]*models.Item, 0, len(ordered))
+	for i := range ordered {
+		if ordered[i].Snapshot.SKU != "" {
+			lines = append(lines, &ordered[i])
+		}
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		return bytes.Compare(lines[i].ProductID[:], lines[j].ProductID[:]) < 0
+	})
+
+	products := make(map[string]*models.Product, len(lines))
+	for _, line := range lines {
+		sku := line.Snapshot.SKU
+		if _, ok := products[sku]; ok {
+			continue
+		}
+		p, err := productRepo.GetBySKUForUpdate(sku)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		products[sku] = p
+	}
+	return products, nil
+}
+
+// planReorder decides how much of an ordered item can be added to the cart
+// note that product is nil when it is deleted, and the quantity already in the cart counts against the stock
+func planReorder(ordered *models.Item, product *models.Product, inCart *models.Item, cartFull bool) ReorderLine {
+	line := ReorderLine{
+		SKU:       ordered.Snapshot.SKU,
+		Name:      ordered.Snapshot.Name,
+		Requested: ordered.Quantity,
+		Result:    ReorderSkipped,
+	}
+	if product == nil {
+		line.Reason = "Product is not available anymore"
+		return line
+	}
+	if inCart == nil && cartFull {
+		line.Reason = "You exceed maximum number of items"
+		return line
+	}
+
+	available := uint(0)
+	if inCart == nil {
+		available = product.Stock.Number
+	} else if product.Stock.Number > inCart.Quantity {
+		available = product.Stock.Number - inCart.Quantity
+	}
+	if available == 0 {
+		line.Reason = "Product is out of stock"
+		return line
+	}
+
+	line.Added, line.Result = ordered.Quantity, ReorderAdded
+	if available < ordered.Quantity {
+		line.Added, line.Result = available, ReorderReduced
+		line.Reason = fmt.Sprintf("Only %d more can be added from the stock", available)
+	}
+	return line
+}
+
+// snapshotOf captures the product data that an ordered item keeps independent of later catalog changes
+func snapshotOf(p *models.Product) models.ProductSnapshot {
+	snapshot := models.ProductSnapshot{
+		SKU:       p.Stock.SKU,
+		UnitPrice: p.Price,
+	}
+	if p.Name != nil {
+		snapshot.Name = *p.Name
+	}
+	if p.CategoryName != nil {
+		snapshot.CategoryName = *p.CategoryName
+	}
+	return snapshot
 }
 
 // Delete deletes an item with with productSKU and returns updated total price of the cart
-func (is *ItemService) Delete(c *gin.Context) (float32, error) {
+func (is *ItemService) Delete(c *gin.Context) (money.Money, error) {
 
 	sku := c.Param("sku")
 	zap.L().Debug("itemservice.Delete", zap.Reflect("sku", sku))
 
 	parsedCartId, err := is.parsedCartIdFromCtx(c)
 	if err != nil {
-		return -1, err
+		return money.Money{}, err
 	}
 
 	product, err := is.productRepo.GetBySKU(sku)
 	if err != nil {
-		return -1, err
+		return money.Money{}, err
 	}
 
 	err = is.itemRepo.deleteItemWithProductID(product.ID, parsedCartId)
 	if err != nil {
-		return -1, err
+		return money.Money{}, err
+	}
+	err = is.reservations.Release(product.ID, parsedCartId)
+	if err != nil {
+		return money.Money{}, err
 	}
 
 	totalPrice, err := is.CalculatePrice(c)
 	if err != nil {
-		return -1, err
+		return money.Money{}, err
 	}
 	return totalPrice, nil
 }
@@ -284,20 +780,3 @@ func (is *ItemService) parsedCartIdFromCtx(c *gin.Context) (uuid.UUID, error) {
 	}
 	return parsedCartId, nil
 }
-
-//parsedOrderIdFromCtx get orderID from context and parse it to uuid
-func (is *ItemService) parsedOrderIdFromCtx(c *gin.Context) (uuid.UUID, error) {
-	orderID, ok := c.Get("orderID")
-	zap.L().Debug("itemservice.parsedOrderIdFromCtx", zap.Reflect("orderID", orderID))
-
-	if !ok {
-		zap.L().Error("itemservice.parsedOrderIdFromCtx failed to fetch orderID", zap.Error(errors.New("orderID can not be fetched from context")))
-		return uuid.Nil, errors.New("Order data not found")
-	}
-	parsedOrderId, err := uuid.Parse(fmt.Sprintf("%v", orderID))
-	if err != nil {
-		zap.L().Error("itemservice.parsedOrderIdFromCtx failed to parse orderID", zap.Error(errors.New("orderID can not be parsed")))
-		return uuid.Nil, err
-	}
-	return parsedOrderId, nil
-}
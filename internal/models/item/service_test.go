@@ -0,0 +1,108 @@
+package item
+
+import (
+	"testing"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/coupon"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/promotion"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanReorder(t *testing.T) {
+	ordered := &models.Item{Quantity: 3, Snapshot: models.ProductSnapshot{Name: "test", SKU: "TESTSKU"}}
+	inStock := func(n uint) *models.Product {
+		return &models.Product{Stock: models.Stock{SKU: "TESTSKU", Number: n}}
+	}
+
+	cases := []struct {
+		name     string
+		product  *models.Product
+		inCart   *models.Item
+		cartFull bool
+		result   string
+		added    uint
+	}{
+		{name: "added", product: inStock(10), result: ReorderAdded, added: 3},
+		{name: "reduced to stock", product: inStock(2), result: ReorderReduced, added: 2},
+		{name: "reduced by quantity in cart", product: inStock(4), inCart: &models.Item{Quantity: 3}, result: ReorderReduced, added: 1},
+		{name: "deleted product", result: ReorderSkipped},
+		{name: "out of stock", product: inStock(0), result: ReorderSkipped},
+		{name: "stock already in cart", product: inStock(2), inCart: &models.Item{Quantity: 2}, result: ReorderSkipped},
+		{name: "full cart", product: inStock(10), cartFull: true, result: ReorderSkipped},
+		{name: "full cart with product in it", product: inStock(10), inCart: &models.Item{Quantity: 1}, cartFull: true, result: ReorderAdded, added: 3},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			line := planReorder(ordered, tc.product, tc.inCart, tc.cartFull)
+
+			assert.Equal(t, tc.result, line.Result)
+			assert.Equal(t, tc.added, line.Added)
+			assert.Equal(t, uint(3), line.Requested)
+			assert.Equal(t, "TESTSKU", line.SKU)
+			if tc.result != ReorderAdded {
+				assert.NotEmpty(t, line.Reason)
+			}
+		})
+	}
+}
+
+func TestCheckLine(t *testing.T) {
+	name := "test"
+	inCart := func(unit, current int64, quantity uint) *models.Item {
+		return &models.Item{
+			Quantity:   quantity,
+			TotalPrice: money.New(unit*int64(quantity), "USD"),
+			Product:    models.Product{Name: &name, Price: money.New(current, "USD"), Stock: models.Stock{SKU: "TESTSKU"}},
+		}
+	}
+
+	cases := []struct {
+		name      string
+		item      *models.Item
+		available uint
+		price     money.Money
+		kinds     []string
+	}{
+		{name: "unchanged", item: inCart(500, 500, 2), available: 10, price: money.New(1000, "USD")},
+		{name: "repriced", item: inCart(500, 600, 2), available: 10, price: money.New(1200, "USD"), kinds: []string{WarningPriceChanged}},
+		{name: "not enough stock", item: inCart(500, 500, 2), available: 1, price: money.New(1000, "USD"), kinds: []string{WarningInsufficientStock}},
+		{name: "out of stock and repriced", item: inCart(500, 400, 2), price: money.New(800, "USD"), kinds: []string{WarningPriceChanged, WarningInsufficientStock}},
+		{name: "deleted product", item: &models.Item{Quantity: 2, TotalPrice: money.New(1000, "USD")}, price: money.New(1000, "USD"), kinds: []string{WarningUnavailable}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			price, warnings := checkLine(tc.item, tc.available)
+
+			assert.Equal(t, tc.price, price)
+			kinds := make([]string, 0)
+			for _, w := range warnings {
+				kinds = append(kinds, w.Kind)
+				assert.NotEmpty(t, w.Message)
+			}
+			if tc.kinds == nil {
+				tc.kinds = []string{}
+			}
+			assert.Equal(t, tc.kinds, kinds)
+		})
+	}
+}
+
+func TestDiscountsLines(t *testing.T) {
+	productID := uuid.New()
+	d := &Discounts{
+		Promotions: []promotion.Applied{{Lines: map[uuid.UUID]money.Money{productID: money.New(300, "USD")}}},
+		Coupon:     &coupon.Discount{Lines: map[uuid.UUID]money.Money{productID: money.New(200, "USD")}},
+	}
+	lines, err := d.Lines()
+	require.NoError(t, err)
+	assert.Equal(t, money.New(500, "USD"), lines[productID])
+
+	// a discount in another currency fails the pricing instead of being left out
+	d.Coupon.Lines[productID] = money.New(200, "EUR")
+	_, err = d.Lines()
+	assert.Error(t, err)
+}
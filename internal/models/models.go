@@ -1,15 +1,17 @@
 package models
 
 import (
+	"strings"
 	"time"
 
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type Stock struct {
 	SKU    string `json:"sku" gorm:"unique"`
-	Number uint   `json:"number,omitempty"`
+	Number uint   `json:"number,omitempty" gorm:"check:chk_products_stock_number,number >= 0"`
 }
 
 type Product struct {
@@ -18,9 +20,20 @@ type Product struct {
 	DeletedAt    gorm.DeletedAt `gorm:"index"`
 	ID           uuid.UUID      `json:"id"`
 	Name         *string        `json:"description"`
-	Price        float32        `json:"price"`
+	Price        money.Money    `json:"price" gorm:"embedded;embeddedPrefix:price_"`
 	Stock        Stock          `json:"stock" gorm:"embedded"`
 	CategoryName *string        `json:"categoryName"`
+	Weight       uint           `json:"weight"`
+	Dimensions   Dimensions     `json:"dimensions" gorm:"embedded;embeddedPrefix:dimensions_"`
+	// Available is the stock that is not held by the carts, set only when stock reservations are enabled
+	Available *uint `json:"-" gorm:"-"`
+}
+
+// Dimensions keeps the package size of a product in centimetres
+type Dimensions struct {
+	Length uint `json:"length"`
+	Width  uint `json:"width"`
+	Height uint `json:"height"`
 }
 
 type Category struct {
@@ -58,39 +71,309 @@ type Cart struct {
 	UpdatedAt  time.Time
 	DeletedAt  gorm.DeletedAt `gorm:"index"`
 	ID         uuid.UUID      `json:"id"`
-	UserID     uuid.UUID      `json:"userId"`
+	UserID     uuid.UUID      `json:"userId" gorm:"default:null"`
 	Items      []Item         `json:"items"`
-	TotalPrice float32        `json:"totalPrice"`
+	TotalPrice money.Money    `json:"totalPrice" gorm:"embedded;embeddedPrefix:total_price_"`
 }
 
 type Order struct {
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeletedAt       gorm.DeletedAt       `gorm:"index"`
+	ID              uuid.UUID            `json:"id"`
+	UserID          uuid.UUID            `json:"userId"`
+	User            *User                `json:"user,omitempty" gorm:"constraint:-"`
+	Items           []Item               `json:"items"`
+	TotalPrice      money.Money          `json:"totalPrice" gorm:"embedded;embeddedPrefix:total_price_"`
+	Status          string               `json:"status"`
+	StatusHistory   []OrderStatusHistory `json:"statusHistory"`
+	Payments        []Payment            `json:"payments"`
+	Currency        string               `json:"currency"`
+	ExchangeRate    int64                `json:"exchangeRate"`
+	ShippingAddress PostalAddress        `json:"shippingAddress" gorm:"embedded;embeddedPrefix:shipping_"`
+	BillingAddress  PostalAddress        `json:"billingAddress" gorm:"embedded;embeddedPrefix:billing_"`
+	Shipping        ShippingQuote        `json:"shipping" gorm:"embedded;embeddedPrefix:shipping_"`
+	Tax             money.Money          `json:"tax" gorm:"embedded;embeddedPrefix:tax_"`
+	TaxLines        []OrderTaxLine       `json:"taxLines"`
+	Invoice         *Invoice             `json:"invoice,omitempty"`
+	Discount        money.Money          `json:"discount" gorm:"embedded;embeddedPrefix:discount_"`
+	Discounts       []OrderDiscount      `json:"discounts"`
+}
+
+type Invoice struct {
+	CreatedAt time.Time
+	ID        uuid.UUID `json:"id"`
+	OrderID   uuid.UUID `json:"orderId" gorm:"uniqueIndex"`
+	Sequence  int64     `json:"sequence" gorm:"uniqueIndex"`
+	Number    string    `json:"number" gorm:"uniqueIndex"`
+}
+
+// InvoiceCounter keeps the last sequence number given to an invoice
+type InvoiceCounter struct {
+	Name  string `gorm:"primaryKey"`
+	Value int64
+}
+
+// OutboxEvent is a domain event that is recorded in the transaction of its change and delivered to the consumers afterwards
+type OutboxEvent struct {
+	CreatedAt     time.Time
+	ID            uuid.UUID  `json:"id"`
+	Type          string     `json:"type" gorm:"index"`
+	AggregateID   string     `json:"aggregateId" gorm:"index"`
+	Payload       string     `json:"payload" gorm:"type:jsonb"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"lastError"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt" gorm:"index"`
+	DeliveredAt   *time.Time `json:"deliveredAt" gorm:"index"`
+}
+
+// Webhook is an endpoint that the events of a type are posted to
+type Webhook struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	ID        uuid.UUID      `json:"id"`
+	URL       string         `json:"url"`
+	EventType string         `json:"eventType" gorm:"index"`
+	Secret    string         `json:"-"`
+	Disabled  bool           `json:"disabled" gorm:"default:false"`
+}
+
+// WebhookDelivery is an attempt to post an event to a webhook, kept as the delivery log of the webhook
+type WebhookDelivery struct {
 	CreatedAt  time.Time
-	UpdatedAt  time.Time
-	DeletedAt  gorm.DeletedAt `gorm:"index"`
-	ID         uuid.UUID      `json:"id"`
-	UserID     uuid.UUID      `json:"userId"`
-	Items      []Item         `json:"items"`
-	TotalPrice float32        `json:"totalPrice"`
-	Status     string         `json:"status"`
+	ID         uuid.UUID `json:"id"`
+	WebhookID  uuid.UUID `json:"webhookId" gorm:"index"`
+	EventID    uuid.UUID `json:"eventId" gorm:"index"`
+	EventType  string    `json:"eventType"`
+	Payload    string    `json:"payload" gorm:"type:jsonb"`
+	StatusCode int       `json:"statusCode"`
+	Error      string    `json:"error"`
+	Succeeded  bool      `json:"succeeded"`
+	DurationMs int64     `json:"durationMs"`
+	Redelivery bool      `json:"redelivery"`
+}
+
+// EmailNotification records an email sent for an event, so that a redelivered event does not send it again
+type EmailNotification struct {
+	CreatedAt time.Time
+	ID        uuid.UUID `json:"id"`
+	EventID   uuid.UUID `json:"eventId" gorm:"uniqueIndex:idx_email_notifications_event_kind"`
+	Kind      string    `json:"kind" gorm:"uniqueIndex:idx_email_notifications_event_kind"`
+	Recipient string    `json:"recipient"`
+}
+
+// JobRun records a run of a scheduled job
+// note that a job runs once per scheduled time across all the instances, which the unique index on the job and the scheduled time guards
+type JobRun struct {
+	CreatedAt   time.Time
+	ID          uuid.UUID  `json:"id"`
+	Job         string     `json:"job" gorm:"uniqueIndex:idx_job_runs_job_scheduled_at"`
+	ScheduledAt time.Time  `json:"scheduledAt" gorm:"uniqueIndex:idx_job_runs_job_scheduled_at"`
+	Instance    string     `json:"instance"`
+	FinishedAt  *time.Time `json:"finishedAt"`
+	Succeeded   bool       `json:"succeeded"`
+	Result      string     `json:"result"`
+	Error       string     `json:"error"`
+}
+
+// Reservation holds a quantity of a product for a cart until it expires
+// note that a cart holds a product once, which the unique index on the cart and the product guards
+type Reservation struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	ID        uuid.UUID `json:"id"`
+	CartID    uuid.UUID `json:"cartId" gorm:"uniqueIndex:idx_reservations_cart_product"`
+	ProductID uuid.UUID `json:"productId" gorm:"uniqueIndex:idx_reservations_cart_product;index"`
+	Quantity  uint      `json:"quantity"`
+	ExpiresAt time.Time `json:"expiresAt" gorm:"index"`
+}
+
+// Coupon is a discount code that admins manage and customers apply to their carts
+// note that the percentage is kept in basis points as the tax rates, and a coupon without categories and skus applies to every item
+type Coupon struct {
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	ID           uuid.UUID      `json:"id"`
+	Code         string         `json:"code" gorm:"uniqueIndex"`
+	Kind         string         `json:"kind"`
+	Percent      int64          `json:"percent"`
+	Amount       money.Money    `json:"amount" gorm:"embedded;embeddedPrefix:amount_"`
+	Categories   []string       `json:"categories" gorm:"serializer:json"`
+	SKUs         []string       `json:"skus" gorm:"serializer:json"`
+	MinBasket    money.Money    `json:"minBasket" gorm:"embedded;embeddedPrefix:min_basket_"`
+	StartsAt     *time.Time     `json:"startsAt"`
+	EndsAt       *time.Time     `json:"endsAt"`
+	UsageLimit   int            `json:"usageLimit"`
+	PerUserLimit int            `json:"perUserLimit"`
+	UsedCount    int            `json:"usedCount"`
+}
+
+// Promotion is an automatic discount that applies to the carts without a code
+// note that the percentage is kept in basis points, and a promotion without categories and skus applies to every item
+// the promotions apply in the order of their priority, and an exclusive promotion is not combined with other promotions on the same item
+type Promotion struct {
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	ID          uuid.UUID      `json:"id"`
+	Name        string         `json:"name"`
+	Kind        string         `json:"kind"`
+	Priority    int            `json:"priority"`
+	Exclusive   bool           `json:"exclusive"`
+	Disabled    bool           `json:"disabled"`
+	Percent     int64          `json:"percent"`
+	BuyQuantity uint           `json:"buyQuantity"`
+	GetQuantity uint           `json:"getQuantity"`
+	Nth         uint           `json:"nth"`
+	BundlePrice money.Money    `json:"bundlePrice" gorm:"embedded;embeddedPrefix:bundle_price_"`
+	Categories  []string       `json:"categories" gorm:"serializer:json"`
+	SKUs        []string       `json:"skus" gorm:"serializer:json"`
+	StartsAt    *time.Time     `json:"startsAt"`
+	EndsAt      *time.Time     `json:"endsAt"`
+}
+
+// CartCoupon is a coupon applied to a cart
+type CartCoupon struct {
+	CreatedAt time.Time
+	CartID    uuid.UUID `json:"cartId" gorm:"primaryKey"`
+	CouponID  uuid.UUID `json:"couponId" gorm:"index"`
+}
+
+// CouponUsage records a coupon used by an order, so that the usages of a customer can be limited
+type CouponUsage struct {
+	CreatedAt time.Time
+	ID        uuid.UUID `json:"id"`
+	CouponID  uuid.UUID `json:"couponId" gorm:"index:idx_coupon_usages_coupon_user"`
+	UserID    uuid.UUID `json:"userId" gorm:"index:idx_coupon_usages_coupon_user"`
+	OrderID   uuid.UUID `json:"orderId" gorm:"uniqueIndex"`
+}
+
+// OrderDiscount keeps a discount that is applied to an order
+type OrderDiscount struct {
+	ID          uuid.UUID   `json:"id"`
+	OrderID     uuid.UUID   `json:"orderId" gorm:"index"`
+	Code        string      `json:"code"`
+	Description string      `json:"description"`
+	Amount      money.Money `json:"amount" gorm:"embedded;embeddedPrefix:amount_"`
+}
+
+// OrderTaxLine keeps the net amount and the tax of the items of an order that share a tax rate
+// note that the rates of tax lines and items are kept in basis points, so 18% is kept as 1800
+type OrderTaxLine struct {
+	ID      uuid.UUID   `json:"id"`
+	OrderID uuid.UUID   `json:"orderId" gorm:"index"`
+	Rate    int64       `json:"rate"`
+	Net     money.Money `json:"net" gorm:"embedded;embeddedPrefix:net_"`
+	Tax     money.Money `json:"tax" gorm:"embedded;embeddedPrefix:tax_"`
+}
+
+// ShippingQuote keeps the shipping zone, the chargeable weight in grams and the cost of a delivery
+type ShippingQuote struct {
+	Zone   string      `json:"zone"`
+	Weight uint        `json:"weight"`
+	Cost   money.Money `json:"cost" gorm:"embedded;embeddedPrefix:cost_"`
+}
+
+type Address struct {
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	DeletedAt         gorm.DeletedAt `gorm:"index"`
+	ID                uuid.UUID      `json:"id"`
+	UserID            uuid.UUID      `json:"userId" gorm:"index"`
+	Label             string         `json:"label"`
+	PostalAddress     PostalAddress  `json:"postalAddress" gorm:"embedded"`
+	IsDefaultShipping bool           `json:"isDefaultShipping" gorm:"default:false"`
+	IsDefaultBilling  bool           `json:"isDefaultBilling" gorm:"default:false"`
+}
+
+// PostalAddress keeps the delivery data of an address
+// note that orders keep a copy of it, so later edits to the address book do not change placed orders
+type PostalAddress struct {
+	FullName string `json:"fullName"`
+	Line1    string `json:"line1"`
+	Line2    string `json:"line2"`
+	City     string `json:"city"`
+	State    string `json:"state"`
+	ZipCode  string `json:"zipCode"`
+	Country  string `json:"country" gorm:"size:2"`
+	Phone    string `json:"phone"`
+}
+
+type ExchangeRate struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Currency  string `json:"currency" gorm:"primaryKey;size:3"`
+	Rate      int64  `json:"rate"`
+}
+
+type OrderStatusHistory struct {
+	CreatedAt  time.Time
+	ID         uuid.UUID `json:"id"`
+	OrderID    uuid.UUID `json:"orderId" gorm:"index"`
+	FromStatus string    `json:"fromStatus"`
+	ToStatus   string    `json:"toStatus"`
+	ChangedBy  uuid.UUID `json:"changedBy"`
+	Note       string    `json:"note"`
+}
+
+type Payment struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	ID        uuid.UUID   `json:"id"`
+	OrderID   uuid.UUID   `json:"orderId" gorm:"index"`
+	Provider  string      `json:"provider"`
+	Reference string      `json:"reference"`
+	Amount    money.Money `json:"amount" gorm:"embedded;embeddedPrefix:amount_"`
+	Status    string      `json:"status"`
+}
+
+type IdempotencyRecord struct {
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	ID           uuid.UUID `json:"id"`
+	UserID       uuid.UUID `json:"userId" gorm:"uniqueIndex:idx_idempotency_records_user_key"`
+	Key          string    `json:"key" gorm:"uniqueIndex:idx_idempotency_records_user_key"`
+	RequestHash  string    `json:"requestHash"`
+	Completed    bool      `json:"completed" gorm:"default:false"`
+	StatusCode   int       `json:"statusCode"`
+	ResponseBody []byte    `json:"responseBody"`
 }
 
 type Item struct {
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
-	DeletedAt  gorm.DeletedAt `gorm:"index"`
-	ID         uuid.UUID      `json:"id"`
-	ProductID  uuid.UUID      `json:"productID"`
-	Product    Product        `json:"product" gorm:"constraint:OnUpdate:CASCADE;"`
-	Quantity   uint           `json:"quantity"`
-	TotalPrice float32        `json:"totalPrice"`
-	CartID     uuid.UUID      `json:"cartId"`
-	OrderID    uuid.UUID      `json:"orderId,omitempty" gorm:"default:null"`
-	IsOrdered  bool           `json:"isOrdered" gorm:"default:false"`
+	DeletedAt  gorm.DeletedAt  `gorm:"index"`
+	ID         uuid.UUID       `json:"id"`
+	ProductID  uuid.UUID       `json:"productID"`
+	Product    Product         `json:"product" gorm:"constraint:OnUpdate:CASCADE;"`
+	Quantity   uint            `json:"quantity"`
+	TotalPrice money.Money     `json:"totalPrice" gorm:"embedded;embeddedPrefix:total_price_"`
+	CartID     uuid.UUID       `json:"cartId"`
+	OrderID    uuid.UUID       `json:"orderId,omitempty" gorm:"default:null"`
+	IsOrdered  bool            `json:"isOrdered" gorm:"default:false"`
+	Snapshot   ProductSnapshot `json:"snapshot" gorm:"embedded;embeddedPrefix:snapshot_"`
+	TaxRate    int64           `json:"taxRate"`
+	Tax        money.Money     `json:"tax" gorm:"embedded;embeddedPrefix:tax_"`
+	// Discount is the part of the discounts of the cart that falls on the item, and its tax is calculated after it
+	Discount money.Money `json:"discount" gorm:"embedded;embeddedPrefix:discount_"`
+	// Discounts explains the promotions and the coupon that make up the discount, and is only set while the cart is priced
+	Discounts []OrderDiscount `json:"-" gorm:"-"`
+}
+
+// ProductSnapshot keeps the product data of an ordered item as it was at order time
+type ProductSnapshot struct {
+	Name         string      `json:"name"`
+	SKU          string      `json:"sku"`
+	UnitPrice    money.Money `json:"unitPrice" gorm:"embedded;embeddedPrefix:unit_price_"`
+	CategoryName string      `json:"categoryName"`
 }
 
 type Tokens struct {
 	AccessToken  string
 	RefreshToken string
+	Warnings     []string `json:",omitempty"`
 }
 
 // Hook for user data: creates a new id for user and and his/her cart
@@ -120,25 +403,181 @@ func (i *Item) BeforeCreate(tx *gorm.DB) (err error) {
 	return
 }
 
-// Hook for order data:
-var (
-	statusPlaced   = "placed"
-	statusCanceled = "canceled"
+// Order statuses
+const (
+	OrderStatusPlaced    = "placed"
+	OrderStatusPaid      = "paid"
+	OrderStatusPacked    = "packed"
+	OrderStatusShipped   = "shipped"
+	OrderStatusDelivered = "delivered"
+	OrderStatusCanceled  = "canceled"
+	OrderStatusReturned  = "returned"
 )
 
-// creates a new id for order and set its status to placed
+// Hook for order data: creates a new id for order and set its status to placed
 func (o *Order) BeforeCreate(tx *gorm.DB) (err error) {
-	o.ID = uuid.New()
-	o.Status = statusPlaced
+	// the id is kept when it is given, since the payment is authorized for it before the order is saved
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	o.Status = OrderStatusPlaced
+	return
+}
+
+// Hook for order data: records the placement as the first entry of the order's status history
+func (o *Order) AfterCreate(tx *gorm.DB) (err error) {
+	return tx.Create(&OrderStatusHistory{
+		OrderID:   o.ID,
+		ToStatus:  OrderStatusPlaced,
+		ChangedBy: o.UserID,
+	}).Error
+}
+
+// TableName overrides the default pluralized table name of order status history
+func (OrderStatusHistory) TableName() string {
+	return "order_status_history"
+}
+
+// Payment statuses
+const (
+	PaymentStatusAuthorized = "authorized"
+	PaymentStatusCaptured   = "captured"
+	PaymentStatusVoided     = "voided"
+	PaymentStatusRefunded   = "refunded"
+)
+
+// Hook for payment data: creates a new id for payment
+func (p *Payment) BeforeCreate(tx *gorm.DB) (err error) {
+	p.ID = uuid.New()
 	return
 }
 
-// sets the order's status to canceled when it is deleted
-func (o *Order) AfterDelete(tx *gorm.DB) (err error) {
+// Rate returns the exchange rate from the base currency to the currency that the order is placed in
+// note that the orders placed before currencies are introduced use the base currency
+func (o *Order) Rate() money.Rate {
+	return money.NewRate(o.Currency, o.ExchangeRate)
+}
 
-	result := tx.Model(&o).Unscoped().Where("id = ?", o.ID).Select("status").Update("status", statusCanceled)
-	if result.Error != nil {
-		return result.Error
+// Format formats an amount of an order in the currency that the order is placed in
+func (o *Order) Format(m money.Money) string {
+	return o.Rate().Apply(money.New(m.Amount, m.Currency)).String()
+}
+
+// Lines returns the lines of a postal address as they are printed on the documents of an order, leaving out the empty ones
+// note that the name of the recipient is not among the lines, as the documents print it on its own
+func (p *PostalAddress) Lines() []string {
+	lines := []string{}
+	for _, line := range []string{
+		p.Line1,
+		p.Line2,
+		strings.TrimSpace(p.ZipCode + " " + p.City),
+		p.State,
+		p.Country,
+		p.Phone,
+	} {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Net returns the total price of an item after its discount
+// note that a discount in another currency is ignored, as the discounts are calculated in the currency of the items
+func (i *Item) Net() money.Money {
+	net, err := i.TotalPrice.Sub(i.Discount)
+	if err != nil {
+		return i.TotalPrice
 	}
+	return net
+}
+
+// Hook for address data: creates a new id for address
+func (a *Address) BeforeCreate(tx *gorm.DB) (err error) {
+	a.ID = uuid.New()
+	return
+}
+
+// Hook for order tax line data: creates a new id for the tax line
+func (l *OrderTaxLine) BeforeCreate(tx *gorm.DB) (err error) {
+	l.ID = uuid.New()
+	return
+}
+
+// Hook for invoice data: creates a new id for invoice
+func (i *Invoice) BeforeCreate(tx *gorm.DB) (err error) {
+	i.ID = uuid.New()
+	return
+}
+
+// Hook for outbox event data: creates a new id for the event
+func (e *OutboxEvent) BeforeCreate(tx *gorm.DB) (err error) {
+	e.ID = uuid.New()
+	return
+}
+
+// Hook for webhook data: creates a new id for the webhook
+func (w *Webhook) BeforeCreate(tx *gorm.DB) (err error) {
+	w.ID = uuid.New()
+	return
+}
+
+// Hook for webhook delivery data: creates a new id for the delivery
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) (err error) {
+	d.ID = uuid.New()
+	return
+}
+
+// Hook for email notification data: creates a new id for the notification
+func (n *EmailNotification) BeforeCreate(tx *gorm.DB) (err error) {
+	n.ID = uuid.New()
+	return
+}
+
+// Hook for job run data: creates a new id for the run
+func (r *JobRun) BeforeCreate(tx *gorm.DB) (err error) {
+	r.ID = uuid.New()
+	return
+}
+
+// Hook for coupon data: creates a new id for the coupon
+func (c *Coupon) BeforeCreate(tx *gorm.DB) (err error) {
+	c.ID = uuid.New()
+	return
+}
+
+// Hook for promotion data: creates a new id for the promotion
+func (p *Promotion) BeforeCreate(tx *gorm.DB) (err error) {
+	p.ID = uuid.New()
+	return
+}
+
+// Hook for coupon usage data: creates a new id for the usage
+func (u *CouponUsage) BeforeCreate(tx *gorm.DB) (err error) {
+	u.ID = uuid.New()
+	return
+}
+
+// Hook for order discount data: creates a new id for the discount
+func (d *OrderDiscount) BeforeCreate(tx *gorm.DB) (err error) {
+	d.ID = uuid.New()
+	return
+}
+
+// Hook for reservation data: creates a new id for the reservation
+func (r *Reservation) BeforeCreate(tx *gorm.DB) (err error) {
+	r.ID = uuid.New()
+	return
+}
+
+// Hook for idempotency record data: creates a new id for the record
+func (r *IdempotencyRecord) BeforeCreate(tx *gorm.DB) (err error) {
+	r.ID = uuid.New()
+	return
+}
+
+// Hook for order status history data: creates a new id for the history record
+func (h *OrderStatusHistory) BeforeCreate(tx *gorm.DB) (err error) {
+	h.ID = uuid.New()
 	return
 }
@@ -0,0 +1,59 @@
+package notification
+
+import (
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func (nr *NotificationRepository) Migration() {
+	nr.db.AutoMigrate(&models.EmailNotification{})
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// getUser fetches the recipient of an email by ID
+func (nr *NotificationRepository) getUser(id uuid.UUID) (*models.User, error) {
+	var user models.User
+	if err := nr.db.Where("id = ?", id).First(&user).Error; err != nil {
+		zap.L().Error("notification.repo.getUser failed to get user", zap.Error(err))
+		return nil, err
+	}
+	return &user, nil
+}
+
+// getOrder fetches an order by ID with the items and the invoice that are listed in its emails
+func (nr *NotificationRepository) getOrder(id uuid.UUID) (*models.Order, error) {
+	var order models.Order
+	if err := nr.db.Unscoped().Preload("Items").Preload("Invoice").Where("id = ?", id).First(&order).Error; err != nil {
+		zap.L().Error("notification.repo.getOrder failed to get order", zap.Error(err))
+		return nil, err
+	}
+	return &order, nil
+}
+
+// isSent checks if the email of the given kind is already sent for an event
+func (nr *NotificationRepository) isSent(eventID uuid.UUID, kind string) (bool, error) {
+	var count int64
+	if err := nr.db.Model(&models.EmailNotification{}).Where("event_id = ? AND kind = ?", eventID, kind).Count(&count).Error; err != nil {
+		zap.L().Error("notification.repo.isSent failed to count notifications", zap.Error(err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// markSent records that the email of the given kind is sent for an event
+func (nr *NotificationRepository) markSent(n *models.EmailNotification) error {
+	if err := nr.db.Create(n).Error; err != nil {
+		zap.L().Error("notification.repo.markSent failed to record notification", zap.Error(err))
+		return err
+	}
+	return nil
+}
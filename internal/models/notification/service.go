@@ -0,0 +1,151 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/outbox"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/mailer"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Notifier sends the transactional emails of the outbox events
+// note that the emails are sent by the outbox dispatcher in the background, so they do not slow down the requests that record the events
+type Notifier struct {
+	repo     *NotificationRepository
+	mailer   mailer.Mailer
+	shopName string
+}
+
+func NewNotifier(repo *NotificationRepository, m mailer.Mailer, shopName string) *Notifier {
+	return &Notifier{repo: repo, mailer: m, shopName: shopName}
+}
+
+// welcomeData is the data of the welcome email
+type welcomeData struct {
+	ShopName  string
+	FirstName string
+}
+
+// orderData is the data of the order confirmation and cancellation emails
+type orderData struct {
+	ShopName      string
+	FirstName     string
+	OrderID       string
+	InvoiceNumber string
+	Note          string
+	Lines         []orderLine
+	Shipping      string
+	Tax           string
+	Total         string
+	ShipTo        []string
+}
+
+// orderLine is an ordered item in an order email
+type orderLine struct {
+	Name     string
+	SKU      string
+	Quantity uint
+	Total    string
+}
+
+// Consume sends the email of an outbox event, and ignores the events that have no email
+func (n *Notifier) Consume(ctx context.Context, e *models.OutboxEvent) error {
+	switch e.Type {
+	case outbox.UserSignedUp:
+		var event outbox.UserSignedUpEvent
+		if err := json.Unmarshal([]byte(e.Payload), &event); err != nil {
+			return err
+		}
+		// admins are created by the operators, so they are not welcomed
+		if event.Role == "admin" || event.Email == "" {
+			return nil
+		}
+		return n.send(ctx, e.ID, KindWelcome, event.Email, welcomeData{ShopName: n.shopName, FirstName: event.FirstName})
+
+	case outbox.OrderPlaced:
+		var event outbox.OrderPlacedEvent
+		if err := json.Unmarshal([]byte(e.Payload), &event); err != nil {
+			return err
+		}
+		return n.sendOrderEmail(ctx, e.ID, KindOrderConfirmation, event.OrderID, event.UserID, "")
+
+	case outbox.OrderCanceled:
+		var event outbox.OrderCanceledEvent
+		if err := json.Unmarshal([]byte(e.Payload), &event); err != nil {
+			return err
+		}
+		return n.sendOrderEmail(ctx, e.ID, KindOrderCancellation, event.OrderID, event.UserID, event.Note)
+	}
+	return nil
+}
+
+// sendOrderEmail sends an email about an order to its owner
+func (n *Notifier) sendOrderEmail(ctx context.Context, eventID uuid.UUID, kind string, orderID, userID uuid.UUID, note string) error {
+	user, err := n.repo.getUser(userID)
+	if err != nil {
+		return err
+	}
+	if user.Email == nil {
+		return nil
+	}
+	order, err := n.repo.getOrder(orderID)
+	if err != nil {
+		return err
+	}
+
+	data := newOrderData(order, user, n.shopName)
+	data.Note = note
+	return n.send(ctx, eventID, kind, *user.Email, data)
+}
+
+// send renders and sends an email unless it is already sent for the event
+func (n *Notifier) send(ctx context.Context, eventID uuid.UUID, kind, to string, data interface{}) error {
+	zap.L().Debug("notification.service.send", zap.Reflect("eventID", eventID), zap.Reflect("kind", kind))
+
+	sent, err := n.repo.isSent(eventID, kind)
+	if err != nil || sent {
+		return err
+	}
+	msg, err := render(kind, to, data)
+	if err != nil {
+		return err
+	}
+	if err := n.mailer.Send(ctx, msg); err != nil {
+		zap.L().Error("notification.service.send failed to send email", zap.Reflect("kind", kind), zap.Error(err))
+		return err
+	}
+	return n.repo.markSent(&models.EmailNotification{EventID: eventID, Kind: kind, Recipient: to})
+}
+
+// newOrderData collects the data of an order email, with the amounts in the currency of the order
+func newOrderData(o *models.Order, u *models.User, shopName string) orderData {
+	format := o.Format
+
+	data := orderData{
+		ShopName:  shopName,
+		FirstName: u.FirstName,
+		OrderID:   o.ID.String(),
+		Shipping:  format(o.Shipping.Cost),
+		Tax:       format(o.Tax),
+		Total:     format(o.TotalPrice),
+		ShipTo:    o.ShippingAddress.Lines(),
+	}
+	if o.ShippingAddress.FullName != "" {
+		data.ShipTo = append([]string{o.ShippingAddress.FullName}, data.ShipTo...)
+	}
+	if o.Invoice != nil {
+		data.InvoiceNumber = o.Invoice.Number
+	}
+	for i := range o.Items {
+		data.Lines = append(data.Lines, orderLine{
+			Name:     o.Items[i].Snapshot.Name,
+			SKU:      o.Items[i].Snapshot.SKU,
+			Quantity: o.Items[i].Quantity,
+			Total:    format(o.Items[i].TotalPrice),
+		})
+	}
+	return data
+}
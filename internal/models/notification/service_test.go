@@ -0,0 +1,100 @@
+package notification
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/outbox"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/mailer"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// fakeMailer keeps the sent emails instead of sending them
+type fakeMailer struct {
+	sent []*mailer.Message
+}
+
+func (f *fakeMailer) Send(ctx context.Context, m *mailer.Message) error {
+	f.sent = append(f.sent, m)
+	return nil
+}
+
+func newTestNotifier(t *testing.T) (*Notifier, *fakeMailer, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db, PreferSimpleProtocol: true}), &gorm.Config{})
+	require.NoError(t, err)
+	m := &fakeMailer{}
+	return NewNotifier(NewNotificationRepository(gdb), m, "Shopping Basket"), m, mock
+}
+
+func TestRender_OrderConfirmation(t *testing.T) {
+	order := &models.Order{
+		ID:           uuid.New(),
+		TotalPrice:   money.New(3299, money.DefaultCurrency),
+		Currency:     money.DefaultCurrency,
+		ExchangeRate: money.RateScale,
+		Tax:          money.New(360, money.DefaultCurrency),
+		Shipping:     models.ShippingQuote{Cost: money.New(939, money.DefaultCurrency)},
+		Items: []models.Item{{
+			Quantity:   2,
+			TotalPrice: money.New(2000, money.DefaultCurrency),
+			Snapshot:   models.ProductSnapshot{Name: "Pens & Pencils", SKU: "PP-1"},
+		}},
+		Invoice:         &models.Invoice{Number: "INV-000001"},
+		ShippingAddress: models.PostalAddress{FullName: "Jane Doe", City: "Istanbul", ZipCode: "34010", Country: "TR"},
+	}
+
+	msg, err := render(KindOrderConfirmation, "jane@example.com", newOrderData(order, &models.User{FirstName: "Jane"}, "Shopping Basket"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"jane@example.com"}, msg.To)
+	assert.Equal(t, "Your order "+order.ID.String()+" is placed", msg.Subject)
+	assert.Contains(t, msg.Text, "2 x Pens & Pencils (PP-1): "+money.New(2000, money.DefaultCurrency).String())
+	assert.Contains(t, msg.Text, "Invoice: INV-000001")
+	assert.Contains(t, msg.Text, "34010 Istanbul")
+	assert.Contains(t, msg.HTML, "Pens &amp; Pencils")
+	assert.Contains(t, msg.HTML, "Total</th><th align=\"right\">"+money.New(3299, money.DefaultCurrency).String())
+}
+
+func TestConsume_Welcome(t *testing.T) {
+	n, m, mock := newTestNotifier(t)
+	event := &models.OutboxEvent{ID: uuid.New(), Type: outbox.UserSignedUp, Payload: `{"email":"jane@example.com","firstName":"Jane","role":"user"}`}
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "email_notifications" WHERE event_id = \$1 AND kind = \$2`).
+		WithArgs(event.ID, KindWelcome).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO "email_notifications"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, n.Consume(context.Background(), event))
+	require.Len(t, m.sent, 1)
+	assert.Equal(t, "Welcome to Shopping Basket", m.sent[0].Subject)
+	assert.Contains(t, m.sent[0].Text, "Hello Jane,")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConsume_SkipsSentAndAdminEmails(t *testing.T) {
+	n, m, mock := newTestNotifier(t)
+	sent := &models.OutboxEvent{ID: uuid.New(), Type: outbox.UserSignedUp, Payload: `{"email":"jane@example.com","role":"user"}`}
+	admin := &models.OutboxEvent{ID: uuid.New(), Type: outbox.UserSignedUp, Payload: `{"email":"admin@example.com","role":"admin"}`}
+	other := &models.OutboxEvent{ID: uuid.New(), Type: outbox.StockChanged, Payload: `{}`}
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "email_notifications"`).
+		WithArgs(sent.ID, KindWelcome).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	require.NoError(t, n.Consume(context.Background(), sent))
+	require.NoError(t, n.Consume(context.Background(), admin))
+	require.NoError(t, n.Consume(context.Background(), other))
+	assert.Empty(t, m.sent)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,64 @@
+package notification
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/cagrikilicoglu/shopping-basket/pkg/mailer"
+)
+
+// Kinds of the emails, named after their template files
+const (
+	KindWelcome           = "welcome"
+	KindOrderConfirmation = "order_confirmation"
+	KindOrderCancellation = "order_cancellation"
+)
+
+//go:embed templates/*.tmpl
+var templateFiles embed.FS
+
+// emailTemplate keeps the subject, text and html templates of an email kind
+// note that the text parts are parsed without html escaping
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+var templates = map[string]*emailTemplate{
+	KindWelcome:           mustParse(KindWelcome),
+	KindOrderConfirmation: mustParse(KindOrderConfirmation),
+	KindOrderCancellation: mustParse(KindOrderCancellation),
+}
+
+func mustParse(kind string) *emailTemplate {
+	file := "templates/" + kind + ".tmpl"
+	return &emailTemplate{
+		text: texttemplate.Must(texttemplate.ParseFS(templateFiles, file)),
+		html: htmltemplate.Must(htmltemplate.ParseFS(templateFiles, file)),
+	}
+}
+
+// render renders the email of the given kind with the given data for a recipient
+func render(kind, to string, data interface{}) (*mailer.Message, error) {
+	t := templates[kind]
+
+	var subject, text, html bytes.Buffer
+	if err := t.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+	if err := t.text.ExecuteTemplate(&text, "text", data); err != nil {
+		return nil, err
+	}
+	if err := t.html.ExecuteTemplate(&html, "html", data); err != nil {
+		return nil, err
+	}
+	return &mailer.Message{
+		To:      []string{to},
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    strings.TrimSpace(html.String()) + "\n",
+	}, nil
+}
@@ -0,0 +1,138 @@
+package order
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/httpErrors"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const dateLayout = "2006-01-02"
+
+// sortColumns maps the sort parameters to the order columns
+var sortColumns = map[string]string{
+	"date":  "orders.created_at",
+	"total": "orders.total_price_amount",
+}
+
+// Filter holds the conditions to search orders
+type Filter struct {
+	UserID   uuid.UUID
+	Status   string
+	From     *time.Time
+	To       *time.Time
+	Email    string
+	MinTotal *money.Money
+	SKU      string
+	SortBy   string
+	Desc     bool
+}
+
+// parseFilter parses order search conditions from the query parameters of the request
+// note that dates are expected in YYYY-MM-DD format, the to date is inclusive and minTotal is in major units of the default currency
+func parseFilter(c *gin.Context) (*Filter, error) {
+	f, err := parseHistoryFilter(c)
+	if err != nil {
+		return nil, err
+	}
+	f.Email = c.Query("email")
+	f.SKU = c.Query("sku")
+
+	if minTotal := c.Query("minTotal"); minTotal != "" {
+		parsed, err := money.Parse(minTotal, money.DefaultCurrency)
+		if err != nil {
+			return nil, badQueryParam("minTotal should be a decimal number")
+		}
+		f.MinTotal = &parsed
+	}
+	return f, nil
+}
+
+// parseHistoryFilter parses the status, date range and sort conditions that customers can search their own orders with
+// note that dates are expected in YYYY-MM-DD format and the to date is inclusive
+func parseHistoryFilter(c *gin.Context) (*Filter, error) {
+	f := &Filter{
+		Status: c.Query("status"),
+		SortBy: c.DefaultQuery("sort", "date"),
+		Desc:   c.DefaultQuery("order", "desc") == "desc",
+	}
+
+	if f.Status != "" && !isValidStatus(f.Status) {
+		return nil, badQueryParam(fmt.Sprintf("status %s is not valid", f.Status))
+	}
+	if _, ok := sortColumns[f.SortBy]; !ok {
+		return nil, badQueryParam("sort should be date or total")
+	}
+
+	if from := c.Query("from"); from != "" {
+		parsed, err := time.Parse(dateLayout, from)
+		if err != nil {
+			return nil, badQueryParam("from should be a date in YYYY-MM-DD format")
+		}
+		f.From = &parsed
+	}
+	if to := c.Query("to"); to != "" {
+		parsed, err := time.Parse(dateLayout, to)
+		if err != nil {
+			return nil, badQueryParam("to should be a date in YYYY-MM-DD format")
+		}
+		parsed = parsed.AddDate(0, 0, 1)
+		f.To = &parsed
+	}
+	return f, nil
+}
+
+// apply adds the conditions of the filter to an order query
+func (f *Filter) apply(db *gorm.DB) *gorm.DB {
+	if f.UserID != uuid.Nil {
+		db = db.Where("orders.user_id = ?", f.UserID)
+	}
+	if f.Status != "" {
+		db = db.Where("orders.status = ?", f.Status)
+	}
+	if f.From != nil {
+		db = db.Where("orders.created_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		db = db.Where("orders.created_at < ?", *f.To)
+	}
+	if f.Email != "" {
+		db = db.Where(`orders.user_id IN (SELECT id FROM users WHERE email ILIKE ? ESCAPE '\')`, containsPattern(f.Email))
+	}
+	if f.MinTotal != nil {
+		db = db.Where("orders.total_price_currency = ? AND orders.total_price_amount >= ?", f.MinTotal.Currency, f.MinTotal.Amount)
+	}
+	if f.SKU != "" {
+		db = db.Where("EXISTS (SELECT 1 FROM items WHERE items.order_id = orders.id AND items.snapshot_sku = ?)", f.SKU)
+	}
+	return db
+}
+
+// orderBy returns the sort clause of the filter
+func (f *Filter) orderBy() string {
+	direction := "asc"
+	if f.Desc {
+		direction = "desc"
+	}
+	return fmt.Sprintf("%s %s", sortColumns[f.SortBy], direction)
+}
+
+// badQueryParam creates an error for an invalid query parameter
+func badQueryParam(cause string) error {
+	return httpErrors.NewApiError(http.StatusBadRequest, httpErrors.BadQueryParams.Error(), cause)
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern with a backslash
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// containsPattern returns a LIKE pattern that matches the values containing the given text
+// note that the wildcards in the text match themselves, so "_" in a searched email does not match any character
+func containsPattern(text string) string {
+	return "%" + likeEscaper.Replace(text) + "%"
+}
@@ -0,0 +1,61 @@
+package order
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newContextWithQuery(query string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/admin/orders?"+query, nil)
+	return c
+}
+
+func TestParseFilter(t *testing.T) {
+	f, err := parseFilter(newContextWithQuery("status=shipped&from=2022-04-01&to=2022-04-30&minTotal=50.5&sort=total&order=asc"))
+
+	require.NoError(t, err)
+	require.Equal(t, "shipped", f.Status)
+	require.Equal(t, time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC), *f.From)
+	require.Equal(t, time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC), *f.To)
+	require.Equal(t, money.New(5050, money.DefaultCurrency), *f.MinTotal)
+	require.Equal(t, "orders.total_price_amount asc", f.orderBy())
+}
+
+func TestParseFilter_Defaults(t *testing.T) {
+	f, err := parseFilter(newContextWithQuery(""))
+
+	require.NoError(t, err)
+	require.Nil(t, f.From)
+	require.Nil(t, f.MinTotal)
+	require.Equal(t, "orders.created_at desc", f.orderBy())
+}
+
+func TestParseFilter_Error(t *testing.T) {
+	for _, query := range []string{"status=lost", "from=01-04-2022", "minTotal=fifty", "sort=name"} {
+		_, err := parseFilter(newContextWithQuery(query))
+		require.Error(t, err, query)
+	}
+}
+
+func TestParseHistoryFilter(t *testing.T) {
+	f, err := parseHistoryFilter(newContextWithQuery("status=placed&to=2022-04-30&email=other&sku=TESTSKU&minTotal=50"))
+
+	require.NoError(t, err)
+	require.Equal(t, "placed", f.Status)
+	require.Equal(t, time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC), *f.To)
+	require.Empty(t, f.Email)
+	require.Empty(t, f.SKU)
+	require.Nil(t, f.MinTotal)
+	require.Equal(t, "orders.created_at desc", f.orderBy())
+}
+
+func TestContainsPattern(t *testing.T) {
+	require.Equal(t, "%jane%", containsPattern("jane"))
+	require.Equal(t, `%jane\_doe\%40\\%`, containsPattern(`jane_doe%40\`))
+}
@@ -1,41 +1,76 @@
 package order
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/cagrikilicoglu/shopping-basket/internal/api"
+	"github.com/cagrikilicoglu/shopping-basket/internal/httpErrors"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/address"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models/cart"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/coupon"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/currency"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/invoice"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models/item"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/payment"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models/response"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/shipping"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/tax"
 	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
 	"github.com/cagrikilicoglu/shopping-basket/pkg/middleware"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/pagination"
 	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/strfmt"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
-)
-
-var (
-	maxAllowedCancelDay = 14
-	minOrderPrice       = 50
+	"gorm.io/gorm"
 )
 
 type orderHandler struct {
-	orderRepo   *OrderRepository
-	cartRepo    *cart.CartRepository
-	itemService item.Service
+	orderRepo      *OrderRepository
+	cartRepo       *cart.CartRepository
+	itemService    item.Service
+	lifecycle      *Lifecycle
+	paymentService *payment.PaymentService
+	addressRepo    *address.AddressRepository
+	shipping       *shipping.Calculator
+	taxes          *tax.Calculator
+	invoices       *invoice.InvoiceRepository
+	coupons        *coupon.Coupons
+	invoiceCfg     config.InvoiceConfig
+	rules          *config.Rules
 }
 
-func NewOrderHandler(r *gin.RouterGroup, orderRepo *OrderRepository, cartRepo *cart.CartRepository, is item.Service, cfg *config.Config) {
+func NewOrderHandler(r *gin.RouterGroup, orderRepo *OrderRepository, cartRepo *cart.CartRepository, is item.Service, lifecycle *Lifecycle, ps *payment.PaymentService, addressRepo *address.AddressRepository, calculator *shipping.Calculator, taxes *tax.Calculator, invoices *invoice.InvoiceRepository, coupons *coupon.Coupons, rules *config.Rules, idempotent gin.HandlerFunc, cfg *config.Config) {
 	h := &orderHandler{orderRepo: orderRepo,
-		cartRepo:    cartRepo,
-		itemService: is}
+		cartRepo:       cartRepo,
+		itemService:    is,
+		lifecycle:      lifecycle,
+		paymentService: ps,
+		addressRepo:    addressRepo,
+		shipping:       calculator,
+		taxes:          taxes,
+		invoices:       invoices,
+		coupons:        coupons,
+		invoiceCfg:     cfg.InvoiceConfig,
+		rules:          rules}
 
-	r.POST("/order", middleware.UserAuthMiddleware(cfg.JWTConfig.SecretKey), h.placeOrder)
+	r.POST("/order", middleware.UserAuthMiddleware(cfg.JWTConfig.SecretKey), idempotent, h.placeOrder)
 	r.DELETE("/order/id/:id/cancel", middleware.UserAuthMiddleware(cfg.JWTConfig.SecretKey), h.cancelOrder)
+	r.POST("/order/id/:id/reorder", middleware.UserAuthMiddleware(cfg.JWTConfig.SecretKey), idempotent, h.reorder)
+	r.GET("/order/id/:id/invoice", middleware.UserAuthMiddleware(cfg.JWTConfig.SecretKey), h.getInvoice)
+	r.GET("/order/id/:id", middleware.UserAuthMiddleware(cfg.JWTConfig.SecretKey), h.getOrder)
 	r.GET("/order/history", middleware.UserAuthMiddleware(cfg.JWTConfig.SecretKey), h.getOrders)
+	r.GET("/admin/orders", middleware.AdminAuthMiddleware(cfg.JWTConfig.SecretKey), h.getAllOrders)
+	r.GET("/admin/orders/id/:id", middleware.AdminAuthMiddleware(cfg.JWTConfig.SecretKey), h.getOrderForAdmin)
+	r.PUT("/admin/orders/id/:id/status", middleware.AdminAuthMiddleware(cfg.JWTConfig.SecretKey), h.updateStatus)
 
 }
 
@@ -53,22 +88,125 @@ func (oh *orderHandler) placeOrder(c *gin.Context) {
 		return
 	}
 
-	order, err := createOrderFromCart(cart)
+	// the lines are revalidated against their products, so the order is not placed with stale prices or with lines that cannot be delivered
+	warnings, err := oh.itemService.Revalidate(cart.ID, cart.Items)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	if len(warnings) > 0 {
+		response.RespondWithError(c, httpErrors.NewApiError(http.StatusConflict, cartChangedMessage(warnings), warnings))
+		return
+	}
+
+	// the cart is priced again with its promotions and coupon, so the order is not placed with a stale total or a coupon that no longer applies
+	discounts, err := oh.itemService.ApplyDiscounts(cart.ID, cart.Items)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	if discounts.Coupon != nil && discounts.Coupon.Reason != "" {
+		response.RespondWithError(c, httpErrors.NewApiError(http.StatusBadRequest, discounts.Coupon.Reason+", please remove it from your cart", nil))
+		return
+	}
+	cart.TotalPrice, err = oh.itemService.CalculatePrice(c)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	order, err := createOrderFromCart(cart, currency.RateFromCtx(c), oh.rules.Get().MinOrderPrice)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	// the selected addresses are copied onto the order, so later edits to the address book do not change it
+	order.ShippingAddress, order.BillingAddress, err = oh.resolveAddresses(c, cart.UserID)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	order.Shipping, err = oh.shipping.Quote(cart.Items, cart.TotalPrice, order.ShippingAddress.ZipCode)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	discountLines, err := discounts.Lines()
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	waived := discounts.Coupon.WaiveShipping(&order.Shipping)
+	order.Discounts = discounts.OrderLines(waived)
+	order.Discount, err = discountTotal(order.Discounts)
 	if err != nil {
 		response.RespondWithError(c, err)
 		return
 	}
 
-	err = oh.orderRepo.Create(order)
+	// the items are taxed by the country that the order is delivered to, and the total is known before the order is saved, so the payment can be authorized first
+	_, estimatedTax, err := oh.itemService.CalculateTax(cart.Items, order.ShippingAddress.Country)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	order.TotalPrice, err = orderTotal(cart.TotalPrice, order.Shipping.Cost, estimatedTax)
 	if err != nil {
 		response.RespondWithError(c, err)
 		return
 	}
 
-	c.Set("orderID", order.ID)
+	// payment is authorized before the transaction, so that no row stays locked while the provider is called
+	order.ID = uuid.New()
+	authorized, err := oh.paymentService.Authorize(order)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
 
-	err = oh.itemService.Order(c)
+	// the order, its items and its payment are saved in a single transaction, so there is no order left behind if any step fails
+	err = oh.orderRepo.Transaction(func(tx *gorm.DB) error {
+		if err := oh.orderRepo.WithTx(tx).Create(order); err != nil {
+			return err
+		}
+		ordered, err := oh.itemService.Order(tx, order.ID, cart.ID, order.ShippingAddress.Country, discountLines)
+		if err != nil {
+			return err
+		}
+		// the total is calculated from the items locked by the order, so the cart cannot be changed by a concurrent request after it is priced
+		if ordered.Total != cart.TotalPrice {
+			return errCartChanged
+		}
+		// the usage of the coupon is counted with the order, so a coupon cannot be used beyond its limits by concurrent orders
+		if err := oh.coupons.WithTx(tx).Redeem(discounts.Coupon, cart.ID, cart.UserID, order.ID); err != nil {
+			return err
+		}
+		order.TaxLines, order.Tax = ordered.TaxLines, ordered.Tax
+		total, err := orderTotal(ordered.Total, order.Shipping.Cost, ordered.Tax)
+		if err != nil {
+			return err
+		}
+		// the order cannot take a total other than the authorized one
+		if total != order.TotalPrice {
+			return errCartChanged
+		}
+		if err := oh.orderRepo.WithTx(tx).updateTax(order); err != nil {
+			return err
+		}
+		if err := oh.orderRepo.WithTx(tx).recordPlaced(order, ordered.Items); err != nil {
+			return err
+		}
+		if err := oh.paymentService.Record(tx, authorized); err != nil {
+			return err
+		}
+		// the invoice number is taken as the last step, so the counter is locked only until the commit and a failed order does not use up a number
+		_, err = oh.invoices.WithTx(tx).Issue(order.ID)
+		return err
+	})
 	if err != nil {
+		oh.paymentService.Release(authorized)
 		response.RespondWithError(c, err)
 		return
 	}
@@ -78,7 +216,7 @@ func (oh *orderHandler) placeOrder(c *gin.Context) {
 		response.RespondWithError(c, err)
 		return
 	}
-	response.RespondWithJson(c, http.StatusCreated, orderToResponse(orderPlaced))
+	response.RespondWithJson(c, http.StatusCreated, orderToResponse(orderPlaced, currency.RateFromCtx(c), oh.taxes))
 
 }
 
@@ -100,50 +238,429 @@ func (oh *orderHandler) cancelOrder(c *gin.Context) {
 		return
 	}
 
-	allowedCancelDeadline := order.CreatedAt.AddDate(0, 0, maxAllowedCancelDay)
+	userIDParsed, err := parsedUserIDFromCtx(c)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	if order.UserID != userIDParsed && !isAdmin(c) {
+		response.RespondWithError(c, errors.New("You are not allowed to cancel this order"))
+		return
+	}
+
+	switch order.Status {
+	case models.OrderStatusCanceled:
+		response.RespondWithError(c, httpErrors.NewApiError(http.StatusConflict, "Order is already canceled", nil))
+		return
+	case models.OrderStatusShipped, models.OrderStatusDelivered, models.OrderStatusReturned:
+		response.RespondWithError(c, httpErrors.NewApiError(http.StatusConflict, "Order cannot be canceled after it is shipped", nil))
+		return
+	}
+
+	maxCancelDays := oh.rules.Get().MaxAllowedCancelDays
+	allowedCancelDeadline := order.CreatedAt.AddDate(0, 0, maxCancelDays)
 	if !time.Now().Before(allowedCancelDeadline) {
-		response.RespondWithError(c, errors.New("Order cannot be canceled after 14 days :("))
+		response.RespondWithError(c, fmt.Errorf("Order cannot be canceled after %d days :(", maxCancelDays))
 		return
 	}
-	err = oh.orderRepo.delete(order)
 
+	note := "canceled by the customer"
+	if order.UserID != userIDParsed {
+		note = "canceled by an admin"
+	}
+
+	// the status is checked again while the order is locked and the stock of the items is restored in the same transaction
+	_, err = oh.lifecycle.Transition(order.ID, models.OrderStatusCanceled, userIDParsed, note)
 	if err != nil {
 		response.RespondWithError(c, err)
+		return
 	}
 	response.RespondWithJson(c, http.StatusOK, "Order successfully canceled")
 
 }
 
-// getOrders fetches orders of a user by userID
+// updateStatus moves an order to the status given in the request body
+func (oh *orderHandler) updateStatus(c *gin.Context) {
+
+	id := c.Param("id")
+	zap.L().Debug("order.handler.updateStatus", zap.Reflect("id", id))
+
+	orderIDParsed, err := uuid.Parse(id)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	statusBody := &api.OrderStatusUpdate{}
+	if err := c.Bind(&statusBody); err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	zap.L().Debug("order.handler.updateStatus.Validate", zap.Reflect("statusBody", statusBody))
+	if err := statusBody.Validate(strfmt.NewFormats()); err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	adminIDParsed, err := parsedUserIDFromCtx(c)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	order, err := oh.lifecycle.Transition(orderIDParsed, *statusBody.Status, adminIDParsed, statusBody.Note)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	response.RespondWithJson(c, http.StatusOK, orderToResponse(order, currency.RateFromCtx(c), oh.taxes))
+}
+
+// getOrders fetches orders of a user with filters and paginate the results
+// note that only the status, date range and sort filters are available to customers
 func (oh *orderHandler) getOrders(c *gin.Context) {
 
-	userID, ok := c.Get("userID")
-	zap.L().Debug("order.handler.cancelOrder", zap.Reflect("userid", userID))
-	if !ok {
-		response.RespondWithError(c, errors.New("User data not found"))
+	userIDParsed, err := parsedUserIDFromCtx(c)
+	zap.L().Debug("order.handler.getOrders", zap.Reflect("userID", userIDParsed))
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	pageIndex, pageSize := pagination.GetPaginationParametersFromRequest(c)
+	filter, err := parseHistoryFilter(c)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	filter.UserID = userIDParsed
+
+	orders, count, err := oh.orderRepo.search(filter, pageIndex, pagination.ClampPageSize(pageSize))
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	paginatedResult := pagination.NewFromGinRequest(c, count, ordersToResponse(orders, currency.RateFromCtx(c), oh.taxes))
+
+	response.RespondWithJson(c, http.StatusOK, paginatedResult)
+}
+
+// getOrder fetches an order of the user by ID
+// note that admins can fetch the orders of any user
+func (oh *orderHandler) getOrder(c *gin.Context) {
+
+	id := c.Param("id")
+	zap.L().Debug("order.handler.getOrder", zap.Reflect("id", id))
+
+	orderIDParsed, err := uuid.Parse(id)
+	if err != nil {
+		response.RespondWithError(c, httpErrors.NewApiError(http.StatusBadRequest, "Order ID is invalid", err))
+		return
+	}
+	userIDParsed, err := parsedUserIDFromCtx(c)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	// canceled orders stay in the history, so soft-deleted orders are included
+	order, err := oh.orderRepo.getWithIDForAdmin(orderIDParsed)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	if order.UserID != userIDParsed && !isAdmin(c) {
+		response.RespondWithError(c, errors.New("You are not allowed to see this order"))
+		return
+	}
+	response.RespondWithJson(c, http.StatusOK, orderToResponse(order, currency.RateFromCtx(c), oh.taxes))
+}
+
+// getAllOrders fetches orders of all users with filters and paginate the results
+func (oh *orderHandler) getAllOrders(c *gin.Context) {
+
+	pageIndex, pageSize := pagination.GetPaginationParametersFromRequest(c)
+	zap.L().Debug("order.handler.getAllOrders with pagination", zap.Reflect("pageIndex", pageIndex), zap.Reflect("pageSize", pageSize))
+
+	filter, err := parseFilter(c)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	orders, count, err := oh.orderRepo.search(filter, pageIndex, pagination.ClampPageSize(pageSize))
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	paginatedResult := pagination.NewFromGinRequest(c, count, ordersToResponseForAdmin(orders, currency.RateFromCtx(c), oh.taxes))
+
+	response.RespondWithJson(c, http.StatusOK, paginatedResult)
+}
+
+// getOrderForAdmin fetches an order by ID with its customer and items
+func (oh *orderHandler) getOrderForAdmin(c *gin.Context) {
+
+	id := c.Param("id")
+	zap.L().Debug("order.handler.getOrderForAdmin", zap.Reflect("id", id))
+
+	orderIDParsed, err := uuid.Parse(id)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	order, err := oh.orderRepo.getWithIDForAdmin(orderIDParsed)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	response.RespondWithJson(c, http.StatusOK, orderToResponseForAdmin(order, currency.RateFromCtx(c), oh.taxes))
+}
+
+// reorder adds the items of a past order of the user to the cart and reports what is done with every line
+// note that the lines are added by the current prices and stocks of their products
+func (oh *orderHandler) reorder(c *gin.Context) {
+
+	id := c.Param("id")
+	zap.L().Debug("order.handler.reorder", zap.Reflect("id", id))
+
+	orderIDParsed, err := uuid.Parse(id)
+	if err != nil {
+		response.RespondWithError(c, httpErrors.NewApiError(http.StatusBadRequest, "Order ID is invalid", err))
+		return
+	}
+	userIDParsed, err := parsedUserIDFromCtx(c)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	// canceled orders can be reordered too, so soft-deleted orders are included
+	order, err := oh.orderRepo.getWithIDForAdmin(orderIDParsed)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	if order.UserID != userIDParsed {
+		response.RespondWithError(c, errors.New("You are not allowed to reorder this order"))
+		return
+	}
+
+	userCart, err := oh.getCartFromUserID(c)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	var lines []item.ReorderLine
+	err = oh.orderRepo.Transaction(func(tx *gorm.DB) error {
+		lines, err = oh.itemService.Reorder(tx, userCart.ID, order.Items, oh.rules.Get().MaxItemsForCart)
+		return err
+	})
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	totalPrice, err := oh.itemService.CalculatePrice(c)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	if err := oh.cartRepo.UpdateTotalPrice(userCart, totalPrice); err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	response.RespondWithJson(c, http.StatusOK, reorderToResponse(lines, totalPrice, currency.RateFromCtx(c)))
+}
+
+// getInvoice renders the invoice of an order as an html page or a pdf document
+// note that an invoice is issued on the first download for the orders placed before invoicing was introduced
+func (oh *orderHandler) getInvoice(c *gin.Context) {
+
+	id := c.Param("id")
+	format := c.DefaultQuery("format", "html")
+	zap.L().Debug("order.handler.getInvoice", zap.Reflect("id", id), zap.Reflect("format", format))
+
+	if format != "html" && format != "pdf" {
+		response.RespondWithError(c, httpErrors.NewApiError(http.StatusBadRequest, "Invoice format should be html or pdf", nil))
+		return
+	}
+
+	orderIDParsed, err := uuid.Parse(id)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	userIDParsed, err := parsedUserIDFromCtx(c)
+	if err != nil {
+		response.RespondWithError(c, err)
 		return
 	}
-	userIDParsed, err := uuid.Parse(fmt.Sprintf("%v", userID))
+
+	order, err := oh.orderRepo.getWithIDForAdmin(orderIDParsed)
 	if err != nil {
 		response.RespondWithError(c, err)
+		return
+	}
+	if order.UserID != userIDParsed && !isAdmin(c) {
+		response.RespondWithError(c, errors.New("You are not allowed to see this invoice"))
+		return
+	}
+
+	inv := order.Invoice
+	if inv == nil {
+		if inv, err = oh.invoices.Issue(order.ID); err != nil {
+			// a concurrent download may have issued the invoice in the meantime
+			if inv, err = oh.invoices.GetByOrderID(order.ID); err != nil {
+				response.RespondWithError(c, err)
+				return
+			}
+		}
 	}
-	orders, err := oh.orderRepo.getWithUserID(userIDParsed)
 
-	response.RespondWithJson(c, http.StatusOK, ordersToResponse(orders))
+	doc, err := invoice.NewDocument(inv, order, oh.invoiceCfg)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	var buf bytes.Buffer
+	contentType := "text/html; charset=utf-8"
+	if format == "pdf" {
+		contentType = "application/pdf"
+		err = invoice.RenderPDF(&buf, doc)
+	} else {
+		err = invoice.RenderHTML(&buf, doc)
+	}
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
 
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.%s", inv.Number, format))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
 }
 
 // createOrderFromCart places an order from cart
-func createOrderFromCart(c *models.Cart) (*models.Order, error) {
-	if c.TotalPrice < float32(minOrderPrice) {
-		return nil, errors.New("Cart is below minimum order price of 50")
+// note that the order records the currency and the exchange rate that it is placed with
+// the minimum order price is in major units of the default currency and applies to the items only, so shipping cannot be used to reach it
+func createOrderFromCart(c *models.Cart, rate money.Rate, minOrderPrice float64) (*models.Order, error) {
+	minPrice := money.FromMajor(minOrderPrice, money.DefaultCurrency)
+	belowMin, err := c.TotalPrice.LessThan(minPrice)
+	if err != nil {
+		return nil, err
+	}
+	if belowMin {
+		return nil, fmt.Errorf("Cart is below minimum order price of %s", minPrice)
+	}
+	if rate.IsIdentity() {
+		rate = money.NewRate(money.DefaultCurrency, money.RateScale)
 	}
 	return &models.Order{
-		UserID:     c.UserID,
-		TotalPrice: c.TotalPrice,
+		UserID:       c.UserID,
+		TotalPrice:   c.TotalPrice,
+		Currency:     rate.Currency,
+		ExchangeRate: rate.Value,
 	}, nil
 }
 
+// cartChangedMessage explains the changed lines that keep a cart from being ordered
+func cartChangedMessage(warnings []item.CartWarning) string {
+	messages := make([]string, 0, len(warnings))
+	for _, w := range warnings {
+		messages = append(messages, w.Message)
+	}
+	return "Your cart has changed, please review it before placing the order: " + strings.Join(messages, "; ")
+}
+
+// errCartChanged rejects an order whose cart is changed by a concurrent request after it is priced
+var errCartChanged = httpErrors.NewApiError(http.StatusConflict, "Your cart has changed while the order is placed, please review it and try again", nil)
+
+// orderTotal adds the shipping cost and the tax to the total of the ordered items
+func orderTotal(items, shipping, tax money.Money) (money.Money, error) {
+	total, err := items.Add(shipping)
+	if err != nil {
+		return money.Money{}, err
+	}
+	return total.Add(tax)
+}
+
+// discountTotal sums the discount lines of an order
+func discountTotal(lines []models.OrderDiscount) (money.Money, error) {
+	var total money.Money
+	for i := range lines {
+		var err error
+		if total, err = total.Add(lines[i].Amount); err != nil {
+			return money.Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// resolveAddresses finds the shipping and billing addresses of an order by the IDs in the request body
+// note that the default addresses of the user are used when they are not given, and the billing address falls back to the shipping address
+func (oh *orderHandler) resolveAddresses(c *gin.Context, userID uuid.UUID) (models.PostalAddress, models.PostalAddress, error) {
+	orderBody := &api.OrderRequest{}
+	// the body is optional, so an empty one is not an error
+	if err := c.ShouldBindJSON(orderBody); err != nil && !errors.Is(err, io.EOF) {
+		return models.PostalAddress{}, models.PostalAddress{}, httpErrors.NewApiError(http.StatusBadRequest, "Order request cannot be parsed", err)
+	}
+	zap.L().Debug("order.handler.resolveAddresses", zap.Reflect("orderBody", orderBody))
+
+	shipping, err := oh.getAddress(orderBody.ShippingAddressID, userID, oh.addressRepo.GetDefaultShipping)
+	if err != nil {
+		return models.PostalAddress{}, models.PostalAddress{}, err
+	}
+	if shipping == nil {
+		return models.PostalAddress{}, models.PostalAddress{}, httpErrors.NewApiError(http.StatusBadRequest, "Please add a shipping address to your address book or select one", nil)
+	}
+
+	billing, err := oh.getAddress(orderBody.BillingAddressID, userID, oh.addressRepo.GetDefaultBilling)
+	if err != nil {
+		return models.PostalAddress{}, models.PostalAddress{}, err
+	}
+	if billing == nil {
+		billing = shipping
+	}
+	return shipping.PostalAddress, billing.PostalAddress, nil
+}
+
+// getAddress fetches the address of the user with the given ID, or the default one when no ID is given
+// note that it returns nil without an error when the user has no default address
+func (oh *orderHandler) getAddress(id string, userID uuid.UUID, getDefault func(uuid.UUID) (*models.Address, error)) (*models.Address, error) {
+	if id == "" {
+		a, err := getDefault(userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return a, err
+	}
+
+	idParsed, err := uuid.Parse(id)
+	if err != nil {
+		return nil, httpErrors.NewApiError(http.StatusBadRequest, "Address ID is invalid", err)
+	}
+	return oh.addressRepo.GetWithIDAndUserID(idParsed, userID)
+}
+
+// parsedUserIDFromCtx gets userID from context and parse it to uuid
+func parsedUserIDFromCtx(c *gin.Context) (uuid.UUID, error) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		zap.L().Error("order.handler.parsedUserIDFromCtx failed to fetch userID", zap.Error(errors.New("UserID can not be fetched from context")))
+		return uuid.Nil, errors.New("User data not found")
+	}
+	return uuid.Parse(fmt.Sprintf("%v", userID))
+}
+
+// isAdmin checks if the role of the authorized user is admin
+func isAdmin(c *gin.Context) bool {
+	role, ok := c.Get("role")
+	return ok && fmt.Sprintf("%v", role) == "admin"
+}
+
 // getCartFromUserID fetches cart data by userID
 func (oh *orderHandler) getCartFromUserID(c *gin.Context) (*models.Cart, error) {
 
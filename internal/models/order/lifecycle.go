@@ -0,0 +1,152 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/httpErrors"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/payment"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/product"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// transitions lists the statuses an order is allowed to move to from its current status
+var transitions = map[string][]string{
+	models.OrderStatusPlaced:    {models.OrderStatusPaid, models.OrderStatusCanceled},
+	models.OrderStatusPaid:      {models.OrderStatusPacked, models.OrderStatusCanceled},
+	models.OrderStatusPacked:    {models.OrderStatusShipped, models.OrderStatusCanceled},
+	models.OrderStatusShipped:   {models.OrderStatusDelivered, models.OrderStatusReturned},
+	models.OrderStatusDelivered: {models.OrderStatusReturned},
+	models.OrderStatusCanceled:  {},
+	models.OrderStatusReturned:  {},
+}
+
+// Lifecycle moves orders between statuses by obeying the allowed transitions and records every change
+// note that the side effects of a transition, such as restocking, run in the transaction of the change,
+// while the payment steps are only recorded in it and run through the outbox after the commit, so the provider is not called with the order row locked
+type Lifecycle struct {
+	repo           *OrderRepository
+	productRepo    *product.ProductRepository
+	paymentService *payment.PaymentService
+}
+
+func NewLifecycle(repo *OrderRepository, productRepo *product.ProductRepository, ps *payment.PaymentService) *Lifecycle {
+	return &Lifecycle{repo: repo,
+		productRepo:    productRepo,
+		paymentService: ps}
+}
+
+// Transition moves the order with the given id to the given status and returns the updated order
+// note that the order row is locked during the change, so concurrent transitions cannot skip the rules
+func (l *Lifecycle) Transition(id uuid.UUID, to string, changedBy uuid.UUID, note string) (*models.Order, error) {
+	zap.L().Debug("order.lifecycle.Transition", zap.Reflect("id", id), zap.Reflect("to", to), zap.Reflect("changedBy", changedBy))
+
+	err := l.repo.Transaction(func(tx *gorm.DB) error {
+		repo := l.repo.WithTx(tx)
+
+		o, err := repo.getWithIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		return l.transition(repo, o, to, changedBy, note)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l.repo.getWithID(id)
+}
+
+// ExpireUnpaid cancels the orders that are still not paid after being placed before the given time and returns the number of canceled orders
+// note that an order is paid once its payment is captured, so the placed orders whose authorization is not captured by then are canceled,
+// their stock is restored and their authorization is voided as in any cancellation, and an order that fails to cancel does not stop the others
+func (l *Lifecycle) ExpireUnpaid(ctx context.Context, placedBefore time.Time) (int, error) {
+	ids, err := l.repo.getUnpaidIDsBefore(placedBefore)
+	if err != nil {
+		return 0, err
+	}
+	zap.L().Debug("order.lifecycle.ExpireUnpaid", zap.Reflect("orders", len(ids)))
+
+	canceled, failed := 0, 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return canceled, ctx.Err()
+		}
+		// an order that is paid in the meantime is refused by the transition rules
+		if _, err := l.Transition(id, models.OrderStatusCanceled, uuid.Nil, "canceled because it is not paid in time"); err != nil {
+			zap.L().Error("order.lifecycle.ExpireUnpaid failed to cancel order", zap.Reflect("id", id), zap.Error(err))
+			failed++
+			continue
+		}
+		canceled++
+	}
+	if failed > 0 {
+		return canceled, fmt.Errorf("%d of %d unpaid orders cannot be canceled", failed, len(ids))
+	}
+	return canceled, nil
+}
+
+// transition validates and applies a status change of a locked order by the given transactional repository
+func (l *Lifecycle) transition(repo *OrderRepository, o *models.Order, to string, changedBy uuid.UUID, note string) error {
+	if err := checkTransition(o.Status, to); err != nil {
+		return err
+	}
+	switch to {
+	case models.OrderStatusCanceled:
+		if err := l.restock(repo, o); err != nil {
+			return err
+		}
+		if err := l.paymentService.RequestRefund(repo.db, o); err != nil {
+			return err
+		}
+	case models.OrderStatusShipped:
+		if err := l.paymentService.RequestCapture(repo.db, o); err != nil {
+			return err
+		}
+	case models.OrderStatusReturned:
+		if err := l.paymentService.RequestRefund(repo.db, o); err != nil {
+			return err
+		}
+	}
+	return repo.updateStatus(o, to, changedBy, note)
+}
+
+// restock puts the quantities of a canceled order's items back into the stock in the same transaction
+func (l *Lifecycle) restock(repo *OrderRepository, o *models.Order) error {
+	items, err := repo.getItems(o.ID)
+	if err != nil {
+		return err
+	}
+
+	// items are fetched ordered by product, so product rows are locked in the same order as checkout does
+	productRepo := l.productRepo.WithTx(repo.db)
+	for i := range items {
+		if err := productRepo.RestoreStock(items[i].ProductID, items[i].Quantity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkTransition checks if an order is allowed to move from one status to another
+func checkTransition(from, to string) error {
+	if !isValidStatus(to) {
+		return httpErrors.NewApiError(http.StatusBadRequest, fmt.Sprintf("Order status %s is not valid", to), nil)
+	}
+	for _, next := range transitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return httpErrors.NewApiError(http.StatusConflict, fmt.Sprintf("Order cannot be moved from %s to %s", from, to), nil)
+}
+
+// isValidStatus checks if the given status is one of the order statuses
+func isValidStatus(status string) bool {
+	_, ok := transitions[status]
+	return ok
+}
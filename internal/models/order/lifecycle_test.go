@@ -0,0 +1,118 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/outbox"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/payment"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/product"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestCheckTransition(t *testing.T) {
+	tests := []struct {
+		from    string
+		to      string
+		allowed bool
+	}{
+		{models.OrderStatusPlaced, models.OrderStatusPaid, true},
+		{models.OrderStatusPlaced, models.OrderStatusCanceled, true},
+		{models.OrderStatusPaid, models.OrderStatusPacked, true},
+		{models.OrderStatusPacked, models.OrderStatusShipped, true},
+		{models.OrderStatusShipped, models.OrderStatusDelivered, true},
+		{models.OrderStatusDelivered, models.OrderStatusReturned, true},
+		{models.OrderStatusPlaced, models.OrderStatusShipped, false},
+		{models.OrderStatusShipped, models.OrderStatusCanceled, false},
+		{models.OrderStatusCanceled, models.OrderStatusCanceled, false},
+		{models.OrderStatusReturned, models.OrderStatusPlaced, false},
+		{models.OrderStatusPlaced, "lost", false},
+	}
+
+	for _, tt := range tests {
+		err := checkTransition(tt.from, tt.to)
+		if tt.allowed {
+			require.NoError(t, err, "%s -> %s", tt.from, tt.to)
+		} else {
+			require.Error(t, err, "%s -> %s", tt.from, tt.to)
+		}
+	}
+}
+
+func TestExpireUnpaid_AuthorizedOrder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db, PreferSimpleProtocol: true}), &gorm.Config{})
+	require.NoError(t, err)
+	gateway := payment.NewFakeGateway(config.PaymentConfig{})
+	payments := payment.NewPaymentService(payment.NewPaymentRepository(gdb), gateway)
+	l := NewLifecycle(NewOrderRepository(gdb), product.NewProductRepository(gdb), payments)
+
+	// the order is authorized at checkout as usual, and the authorization is never captured
+	o := &models.Order{ID: uuid.New(), TotalPrice: money.New(5000, "USD"), Status: models.OrderStatusPlaced}
+	authorized, err := payments.Authorize(o)
+	require.NoError(t, err)
+	authorized.ID = uuid.New()
+	paymentRow := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "order_id", "reference", "amount_amount", "amount_currency", "status"}).
+			AddRow(authorized.ID, o.ID, authorized.Reference, authorized.Amount.Amount, authorized.Amount.Currency, models.PaymentStatusAuthorized)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "orders" WHERE (status = $1 AND created_at < $2) AND (NOT EXISTS (SELECT 1 FROM payments WHERE payments.order_id = orders.id AND payments.status = $3))`)).
+		WithArgs(models.OrderStatusPlaced, sqlmock.AnyArg(), models.PaymentStatusCaptured).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(o.ID))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "orders" WHERE "id" = $1`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(o.ID, models.OrderStatusPlaced))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "items" WHERE order_id = $1`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "payments" WHERE order_id = $1 AND status IN ($2,$3)`)).
+		WillReturnRows(paymentRow())
+	// the authorization is voided after the commit, through the outbox
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), outbox.PaymentRequested, authorized.ID.String(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "orders" SET "status"=$1`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "order_status_history"`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "outbox_events"`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "orders" WHERE "id" = $1`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(o.ID, models.OrderStatusCanceled))
+	for _, table := range []string{"order_discounts", "invoices", "items", "payments", "order_status_history", "order_tax_lines"} {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "` + table + `"`)).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	}
+
+	n, err := l.ExpireUnpaid(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "payments" WHERE id = $1`)).WillReturnRows(paymentRow())
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "payments" SET "status"=$1`)).
+		WithArgs(models.PaymentStatusVoided, sqlmock.AnyArg(), authorized.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	event := &models.OutboxEvent{
+		Type:    outbox.PaymentRequested,
+		Payload: fmt.Sprintf(`{"paymentId":%q,"orderId":%q,"action":%q}`, authorized.ID, o.ID, outbox.PaymentRefund),
+	}
+	require.NoError(t, payments.Consume(context.Background(), event))
+
+	// the authorization is voided, so it cannot be captured anymore
+	assert.Error(t, gateway.Capture(authorized.Reference, authorized.Amount))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
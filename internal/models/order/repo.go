@@ -1,28 +1,52 @@
 package order
 
 import (
+	"time"
+
 	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/outbox"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/database"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderRepository struct {
-	db *gorm.DB
+	db     *gorm.DB
+	events *outbox.OutboxRepository
 }
 
 func (or *OrderRepository) Migration() {
-	or.db.AutoMigrate(&models.Order{})
+	or.db.AutoMigrate(&models.Order{}, &models.OrderStatusHistory{}, &models.OrderTaxLine{}, &models.OrderDiscount{})
+	if err := database.MigrateMoneyColumn(or.db, "orders", "total_price", "total_price_"); err != nil {
+		zap.L().Fatal("order.repo.Migration failed to migrate money column", zap.Error(err))
+	}
 }
 
 func NewOrderRepository(db *gorm.DB) *OrderRepository {
-	return &OrderRepository{db: db}
+	return &OrderRepository{db: db, events: outbox.NewOutboxRepository(db)}
+}
+
+// WithTx returns a copy of the repository that runs its queries in the given transaction
+func (or *OrderRepository) WithTx(tx *gorm.DB) *OrderRepository {
+	return &OrderRepository{db: tx, events: or.events.WithTx(tx)}
+}
+
+// Transaction runs the given function in a database transaction
+// note that the transaction is rolled back if the function returns an error
+func (or *OrderRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	if err := or.db.Transaction(fn); err != nil {
+		zap.L().Error("order.repo.Transaction rolled back", zap.Error(err))
+		return err
+	}
+	return nil
 }
 
 // getWithID fetches orders by ID from the database
 func (or *OrderRepository) getWithID(id uuid.UUID) (*models.Order, error) {
 	var o *models.Order
-	if err := or.db.Preload("Items.Product").Preload("Items").Where("id", id).First(&o).Error; err != nil {
+	if err := or.db.Preload("Items").Preload("StatusHistory", orderByCreatedAt).Preload("Payments", orderByCreatedAt).Preload("TaxLines", orderByRate).Preload("Discounts").Preload("Invoice").Where("id", id).First(&o).Error; err != nil {
 		zap.L().Error("order.repo.getWithID failed to get order", zap.Error(err))
 		return nil, err
 	}
@@ -31,15 +55,33 @@ func (or *OrderRepository) getWithID(id uuid.UUID) (*models.Order, error) {
 	return o, nil
 }
 
-// getWithUserID fetches orders by UserID (including-soft deleted) from the database
-func (or *OrderRepository) getWithUserID(id uuid.UUID) (*[]models.Order, error) {
+// search fetches orders matching the filter with pagination parameters (including soft-deleted) from the database
+func (or *OrderRepository) search(f *Filter, pageIndex, pageSize int) (*[]models.Order, int, error) {
+	zap.L().Debug("order.repo.search", zap.Reflect("filter", f))
 
 	var orders *[]models.Order
-	if err := or.db.Order("created_at").Unscoped().Preload("Items.Product").Preload("Items").Where("user_id", id).Find(&orders).Error; err != nil {
-		zap.L().Error("order.repo.getWithID failed get orders", zap.Error(err))
+	var count int64
+
+	query := f.apply(or.db.Model(&models.Order{}).Unscoped()).Session(&gorm.Session{})
+	if err := query.Count(&count).Error; err != nil {
+		zap.L().Error("order.repo.search failed to count orders", zap.Error(err))
+		return nil, -1, err
+	}
+	if err := query.Order(f.orderBy()).Offset((pageIndex-1)*pageSize).Limit(pageSize).Preload("User").Preload("Items").Preload("StatusHistory", orderByCreatedAt).Preload("Payments", orderByCreatedAt).Preload("TaxLines", orderByRate).Preload("Discounts").Preload("Invoice").Find(&orders).Error; err != nil {
+		zap.L().Error("order.repo.search failed to get orders", zap.Error(err))
+		return nil, -1, err
+	}
+	return orders, int(count), nil
+}
+
+// getWithIDForAdmin fetches an order (including soft-deleted) by ID with its customer and items from the database
+func (or *OrderRepository) getWithIDForAdmin(id uuid.UUID) (*models.Order, error) {
+	var o *models.Order
+	if err := or.db.Unscoped().Preload("User").Preload("Items").Preload("StatusHistory", orderByCreatedAt).Preload("Payments", orderByCreatedAt).Preload("TaxLines", orderByRate).Preload("Discounts").Preload("Invoice").Where("id", id).First(&o).Error; err != nil {
+		zap.L().Error("order.repo.getWithIDForAdmin failed to get order", zap.Error(err))
 		return nil, err
 	}
-	return orders, nil
+	return o, nil
 }
 
 // Create creates order in the database
@@ -54,13 +96,120 @@ func (or *OrderRepository) Create(o *models.Order) error {
 	return nil
 }
 
-// delete deletes order from the database
-func (or *OrderRepository) delete(o *models.Order) error {
-	zap.L().Debug("Order.repo.delete", zap.Reflect("Order", o))
-	result := or.db.Delete(o)
-	if result.Error != nil {
-		zap.L().Error("Order.repo.create failed to delete order", zap.Error(result.Error))
-		return result.Error
+// updateTax sets the tax and the total price of an order and records its tax breakdown
+func (or *OrderRepository) updateTax(o *models.Order) error {
+	zap.L().Debug("Order.repo.updateTax", zap.Reflect("Order", o.ID), zap.Reflect("tax", o.Tax))
+
+	if err := or.db.Model(&o).Select("tax_amount", "tax_currency", "total_price_amount", "total_price_currency").Updates(map[string]interface{}{
+		"tax_amount":           o.Tax.Amount,
+		"tax_currency":         o.Tax.Currency,
+		"total_price_amount":   o.TotalPrice.Amount,
+		"total_price_currency": o.TotalPrice.Currency,
+	}).Error; err != nil {
+		zap.L().Error("Order.repo.updateTax failed to update tax", zap.Error(err))
+		return err
+	}
+	for i := range o.TaxLines {
+		o.TaxLines[i].OrderID = o.ID
+	}
+	if len(o.TaxLines) > 0 {
+		if err := or.db.Create(&o.TaxLines).Error; err != nil {
+			zap.L().Error("Order.repo.updateTax failed to record tax lines", zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// recordPlaced records the OrderPlaced event of an order with its ordered items
+// note that it should run last in the transaction of the order, so the event carries the final total price
+func (or *OrderRepository) recordPlaced(o *models.Order, items []models.Item) error {
+	event := outbox.OrderPlacedEvent{
+		OrderID:    o.ID,
+		UserID:     o.UserID,
+		TotalPrice: o.TotalPrice,
+		Tax:        o.Tax,
+		Currency:   o.Currency,
+		Items:      make([]outbox.OrderedItemEvent, 0, len(items)),
+	}
+	for i := range items {
+		event.Items = append(event.Items, outbox.OrderedItemEvent{
+			SKU:      items[i].Product.Stock.SKU,
+			Quantity: items[i].Quantity,
+		})
+	}
+	return or.events.Add(outbox.OrderPlaced, o.ID.String(), event)
+}
+
+// getWithIDForUpdate fetches an order by ID and locks its row until the surrounding transaction ends
+func (or *OrderRepository) getWithIDForUpdate(id uuid.UUID) (*models.Order, error) {
+	var o *models.Order
+	if err := or.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id", id).First(&o).Error; err != nil {
+		zap.L().Error("order.repo.getWithIDForUpdate failed to get order", zap.Error(err))
+		return nil, err
+	}
+	return o, nil
+}
+
+// getUnpaidIDsBefore fetches the IDs of the orders that are placed before the given time and still have no captured payment
+// note that the orders placed through checkout have an authorized payment, which is unpaid until it is captured
+func (or *OrderRepository) getUnpaidIDsBefore(before time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := or.db.Model(&models.Order{}).Where("status = ? AND created_at < ?", models.OrderStatusPlaced, before).
+		Where("NOT EXISTS (SELECT 1 FROM payments WHERE payments.order_id = orders.id AND payments.status = ?)", models.PaymentStatusCaptured).
+		Order("created_at").Pluck("id", &ids).Error; err != nil {
+		zap.L().Error("order.repo.getUnpaidIDsBefore failed to get orders", zap.Error(err))
+		return nil, err
+	}
+	return ids, nil
+}
+
+// getItems fetches ordered items of an order from the database
+func (or *OrderRepository) getItems(orderID uuid.UUID) ([]models.Item, error) {
+	var items []models.Item
+	if err := or.db.Order("product_id").Where("order_id = ?", orderID).Find(&items).Error; err != nil {
+		zap.L().Error("order.repo.getItems failed to get items", zap.Error(err))
+		return nil, err
+	}
+	return items, nil
+}
+
+// updateStatus sets the status of an order and records the change in the status history
+func (or *OrderRepository) updateStatus(o *models.Order, status string, changedBy uuid.UUID, note string) error {
+	zap.L().Debug("Order.repo.updateStatus", zap.Reflect("Order", o.ID), zap.Reflect("status", status))
+
+	history := models.OrderStatusHistory{
+		OrderID:    o.ID,
+		FromStatus: o.Status,
+		ToStatus:   status,
+		ChangedBy:  changedBy,
+		Note:       note,
+	}
+	if err := or.db.Model(&o).Select("status").Update("status", status).Error; err != nil {
+		zap.L().Error("Order.repo.updateStatus failed to update status", zap.Error(err))
+		return err
+	}
+	if err := or.db.Create(&history).Error; err != nil {
+		zap.L().Error("Order.repo.updateStatus failed to record status history", zap.Error(err))
+		return err
+	}
+	if status == models.OrderStatusCanceled {
+		return or.events.Add(outbox.OrderCanceled, o.ID.String(), outbox.OrderCanceledEvent{
+			OrderID:    o.ID,
+			UserID:     o.UserID,
+			CanceledBy: changedBy,
+			Note:       note,
+		})
 	}
 	return nil
 }
+
+// orderByRate sorts preloaded tax lines by their rates
+func orderByRate(db *gorm.DB) *gorm.DB {
+	return db.Order("rate")
+}
+
+// orderByCreatedAt sorts preloaded records by their creation date
+func orderByCreatedAt(db *gorm.DB) *gorm.DB {
+	return db.Order("created_at")
+}
@@ -3,13 +3,21 @@ package order
 import (
 	"github.com/cagrikilicoglu/shopping-basket/internal/api"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/address"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/coupon"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models/item"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/response"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/shipping"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/tax"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
 	"github.com/go-openapi/strfmt"
 	"go.uber.org/zap"
 )
 
 // orderToResponse converts order database model to response model
-func orderToResponse(o *models.Order) *api.Order {
+// note that the amounts are converted with the rate recorded at checkout when the currency of the order is requested
+// the total price always includes the tax, while the prices of the items are displayed in the tax mode of the given calculator
+func orderToResponse(o *models.Order, requested money.Rate, taxes *tax.Calculator) *api.Order {
 	zap.L().Debug("Order.serializer.orderToResponse", zap.Reflect("order", o))
 
 	apiItems := make([]*api.Item, 0)
@@ -17,26 +25,153 @@ func orderToResponse(o *models.Order) *api.Order {
 	orderDate := strfmt.Date(o.CreatedAt)
 	idStr := o.ID.String()
 
+	rate := requested
+	if requested.Currency == o.Rate().Currency {
+		rate = o.Rate()
+	}
 	for i := range o.Items {
-		apiItems = append(apiItems, item.ItemToResponse(&o.Items[i]))
+		apiItems = append(apiItems, item.OrderedItemToResponse(&o.Items[i], rate, taxes))
+	}
+
+	orderRate := o.Rate()
+	exchangeRate := money.FormatRate(money.RateScale)
+	if !orderRate.IsIdentity() {
+		exchangeRate = money.FormatRate(orderRate.Value)
 	}
 	return &api.Order{
-		ID:         &idStr,
-		Items:      apiItems,
-		TotalPrice: &o.TotalPrice,
-		Status:     &o.Status,
-		Date:       &orderDate,
+		ID:              &idStr,
+		Items:           apiItems,
+		TotalPrice:      response.MoneyToResponse(rate.Apply(o.TotalPrice)),
+		Status:          &o.Status,
+		Date:            &orderDate,
+		StatusHistory:   statusHistoryToResponse(o.StatusHistory),
+		Payments:        paymentsToResponse(o.Payments),
+		Currency:        orderRate.Currency,
+		ExchangeRate:    exchangeRate,
+		ShippingAddress: address.PostalAddressToResponse(&o.ShippingAddress),
+		BillingAddress:  address.PostalAddressToResponse(&o.BillingAddress),
+		Shipping:        shipping.QuoteToResponse(&o.Shipping, rate),
+		Tax:             response.MoneyToResponse(rate.Apply(money.New(o.Tax.Amount, o.Tax.Currency))),
+		TaxLines:        taxLinesToResponse(o.TaxLines, rate),
+		TaxIncluded:     taxes.Inclusive(),
+		InvoiceNumber:   invoiceNumber(o.Invoice),
+		Discount:        response.MoneyToResponse(rate.Apply(money.New(o.Discount.Amount, o.Discount.Currency))),
+		Discounts:       coupon.DiscountsToResponse(o.Discounts, rate),
+	}
+
+}
+
+// invoiceNumber returns the number of an invoice, or an empty string for an order that has no invoice yet
+func invoiceNumber(inv *models.Invoice) string {
+	if inv == nil {
+		return ""
 	}
+	return inv.Number
+}
 
+// statusHistoryToResponse converts status history database model to response model as a batch
+func statusHistoryToResponse(hs []models.OrderStatusHistory) []*api.OrderStatusChange {
+	changes := make([]*api.OrderStatusChange, 0)
+	for i := range hs {
+		changedBy := hs[i].ChangedBy.String()
+		date := strfmt.DateTime(hs[i].CreatedAt)
+		changes = append(changes, &api.OrderStatusChange{
+			FromStatus: hs[i].FromStatus,
+			ToStatus:   &hs[i].ToStatus,
+			ChangedBy:  &changedBy,
+			Note:       hs[i].Note,
+			Date:       &date,
+		})
+	}
+	return changes
+}
+
+// taxLinesToResponse converts order tax line database model to response model as a batch
+func taxLinesToResponse(ls []models.OrderTaxLine, rate money.Rate) []*api.TaxLine {
+	lines := make([]*api.TaxLine, 0)
+	for i := range ls {
+		taxRate := tax.FormatRate(ls[i].Rate)
+		lines = append(lines, &api.TaxLine{
+			Rate: &taxRate,
+			Net:  response.MoneyToResponse(rate.Apply(ls[i].Net)),
+			Tax:  response.MoneyToResponse(rate.Apply(ls[i].Tax)),
+		})
+	}
+	return lines
+}
+
+// paymentsToResponse converts payment database model to response model as a batch
+func paymentsToResponse(ps []models.Payment) []*api.Payment {
+	payments := make([]*api.Payment, 0)
+	for i := range ps {
+		date := strfmt.DateTime(ps[i].CreatedAt)
+		payments = append(payments, &api.Payment{
+			Provider:  &ps[i].Provider,
+			Reference: &ps[i].Reference,
+			Amount:    response.MoneyToResponse(ps[i].Amount),
+			Status:    &ps[i].Status,
+			Date:      &date,
+		})
+	}
+	return payments
+}
+
+// orderToResponseForAdmin converts order database model to response model for admin
+// note that the result shows also the customer of the order
+func orderToResponseForAdmin(o *models.Order, requested money.Rate, taxes *tax.Calculator) *api.Order {
+	order := orderToResponse(o, requested, taxes)
+	if o.User != nil {
+		userIDStr := o.User.ID.String()
+		order.Customer = &api.Customer{
+			ID:        &userIDStr,
+			Email:     o.User.Email,
+			FirstName: o.User.FirstName,
+			LastName:  o.User.LastName,
+			ZipCode:   o.User.ZipCode,
+		}
+	}
+	return order
+}
+
+// ordersToResponseForAdmin converts order database model to response model as a batch for admin
+func ordersToResponseForAdmin(os *[]models.Order, requested money.Rate, taxes *tax.Calculator) []*api.Order {
+	zap.L().Debug("Order.serializer.ordersToResponseForAdmin", zap.Reflect("orders", os))
+	orders := make([]*api.Order, 0)
+	for i := range *os {
+		osDeref := *os
+		orders = append(orders, orderToResponseForAdmin(&osDeref[i], requested, taxes))
+	}
+	return orders
 }
 
 // ordersToResponse converts order database model to response model as a batch
-func ordersToResponse(os *[]models.Order) []*api.Order {
+func ordersToResponse(os *[]models.Order, requested money.Rate, taxes *tax.Calculator) []*api.Order {
 	zap.L().Debug("Order.serializer.ordersToResponse", zap.Reflect("orders", os))
 	orders := make([]*api.Order, 0)
 	for i := range *os {
 		osDeref := *os
-		orders = append(orders, orderToResponse(&osDeref[i]))
+		orders = append(orders, orderToResponse(&osDeref[i], requested, taxes))
 	}
 	return orders
 }
+
+// reorderToResponse converts the lines of a reorder and the updated total price of the cart to response model
+func reorderToResponse(lines []item.ReorderLine, totalPrice money.Money, rate money.Rate) *api.ReorderReport {
+	apiLines := make([]*api.ReorderLine, 0, len(lines))
+	for i := range lines {
+		sku, result := lines[i].SKU, lines[i].Result
+		requested, added := int64(lines[i].Requested), int64(lines[i].Added)
+		apiLines = append(apiLines, &api.ReorderLine{
+			Sku:       &sku,
+			Name:      lines[i].Name,
+			Requested: &requested,
+			Added:     &added,
+			Result:    &result,
+			Reason:    lines[i].Reason,
+		})
+	}
+	return &api.ReorderReport{
+		Lines:      apiLines,
+		TotalPrice: response.MoneyToResponse(rate.Apply(totalPrice)),
+	}
+}
@@ -0,0 +1,194 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 50
+	defaultMaxBackoff   = 5 * time.Minute
+	defaultLease        = 5 * time.Minute
+)
+
+// Consumer handles an event delivered by the dispatcher
+// note that an event can be delivered more than once, so consumers should ignore the event IDs they have already handled
+type Consumer func(ctx context.Context, e *models.OutboxEvent) error
+
+type consumer struct {
+	name   string
+	handle Consumer
+}
+
+// Dispatcher polls the outbox and delivers the recorded events to the registered consumers
+// note that an event is marked delivered only after every consumer handles it, and it is retried with a growing delay otherwise
+type Dispatcher struct {
+	repo         *OutboxRepository
+	consumers    []consumer
+	pollInterval time.Duration
+	batchSize    int
+	maxBackoff   time.Duration
+	lease        time.Duration
+	stop         chan context.Context
+	done         chan struct{}
+}
+
+func NewDispatcher(repo *OutboxRepository, cfg config.OutboxConfig) *Dispatcher {
+	d := &Dispatcher{repo: repo,
+		pollInterval: time.Duration(cfg.PollIntervalMs) * time.Millisecond,
+		batchSize:    cfg.BatchSize,
+		maxBackoff:   time.Duration(cfg.MaxBackoffSecs) * time.Second,
+		lease:        time.Duration(cfg.LeaseSecs) * time.Second,
+		stop:         make(chan context.Context),
+		done:         make(chan struct{})}
+	if d.pollInterval <= 0 {
+		d.pollInterval = defaultPollInterval
+	}
+	if d.batchSize <= 0 {
+		d.batchSize = defaultBatchSize
+	}
+	if d.maxBackoff <= 0 {
+		d.maxBackoff = defaultMaxBackoff
+	}
+	if d.lease <= 0 {
+		d.lease = defaultLease
+	}
+	return d
+}
+
+// Register adds a consumer that every event is delivered to
+// note that consumers should be registered before the dispatcher is started
+func (d *Dispatcher) Register(name string, c Consumer) {
+	d.consumers = append(d.consumers, consumer{name: name, handle: c})
+}
+
+// Start starts polling the outbox in the background until the dispatcher is stopped
+func (d *Dispatcher) Start() {
+	go d.run()
+}
+
+// Stop stops polling and delivers the events that are already due before returning
+// note that draining is abandoned when the given context is done, and the remaining events are delivered after the next start
+func (d *Dispatcher) Stop(ctx context.Context) {
+	select {
+	case d.stop <- ctx:
+	case <-ctx.Done():
+		return
+	}
+	select {
+	case <-d.done:
+	case <-ctx.Done():
+		zap.L().Error("outbox.dispatcher.Stop could not drain the outbox in time", zap.Error(ctx.Err()))
+	}
+}
+
+func (d *Dispatcher) run() {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.drain(context.Background())
+		case ctx := <-d.stop:
+			d.drain(ctx)
+			close(d.done)
+			return
+		}
+	}
+}
+
+// drain delivers batches of due events until there is none left or the context is done
+func (d *Dispatcher) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := d.dispatch(ctx)
+		if err != nil {
+			zap.L().Error("outbox.dispatcher.drain failed to dispatch events", zap.Error(err))
+			return
+		}
+		if n < d.batchSize {
+			return
+		}
+	}
+}
+
+// dispatch delivers a batch of due events and returns the number of events in the batch
+// note that the events are claimed for the lease in a short transaction and delivered after it is committed, so no row stays locked while the consumers wait on the network,
+// another instance does not deliver the claimed events until the lease ends, and the results are recorded in a second short transaction
+func (d *Dispatcher) dispatch(ctx context.Context) (int, error) {
+	events, err := d.repo.claim(d.batchSize, time.Now().Add(d.lease))
+	if err != nil {
+		return 0, err
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	// the batch is delivered within its lease, so that its events are not delivered by another instance in the meantime
+	ctx, cancel := context.WithTimeout(ctx, d.lease)
+	defer cancel()
+	results := make([]error, len(events))
+	for i := range events {
+		results[i] = d.deliver(ctx, &events[i])
+	}
+
+	err = d.repo.db.Transaction(func(tx *gorm.DB) error {
+		repo := d.repo.WithTx(tx)
+		for i := range events {
+			if results[i] != nil {
+				zap.L().Error("outbox.dispatcher.dispatch failed to deliver event", zap.Reflect("id", events[i].ID), zap.Reflect("type", events[i].Type), zap.Error(results[i]))
+				if err := repo.markFailed(&events[i], results[i], time.Now().Add(d.backoff(events[i].Attempts))); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := repo.markDelivered(&events[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return len(events), err
+}
+
+// deliver hands an event to every consumer and returns the errors of the ones that fail
+// note that a failing consumer does not hold the event back from the others, which ignore it on the retries once they have handled it
+func (d *Dispatcher) deliver(ctx context.Context, e *models.OutboxEvent) error {
+	failures := make([]string, 0)
+	for _, c := range d.consumers {
+		if err := c.handle(ctx, e); err != nil {
+			failures = append(failures, fmt.Sprintf("consumer %s: %v", c.name, err))
+		}
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// backoff returns the delay before the next attempt of an event, doubling with every attempt up to the maximum
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := time.Second
+	for i := 0; i < attempts && delay < d.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.maxBackoff {
+		return d.maxBackoff
+	}
+	return delay
+}
+
+// LogConsumer writes the delivered events to the log
+func LogConsumer(ctx context.Context, e *models.OutboxEvent) error {
+	zap.L().Info("outbox event", zap.Reflect("id", e.ID), zap.Reflect("type", e.Type), zap.Reflect("aggregateID", e.AggregateID), zap.String("payload", e.Payload))
+	return nil
+}
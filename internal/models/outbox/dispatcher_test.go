@@ -0,0 +1,152 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func newTestDispatcher(t *testing.T) (*Dispatcher, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db, PreferSimpleProtocol: true}), &gorm.Config{})
+	require.NoError(t, err)
+	return NewDispatcher(NewOutboxRepository(gdb), config.OutboxConfig{BatchSize: 10, MaxBackoffSecs: 60}), mock
+}
+
+// expectClaim expects an event to be claimed in a transaction of its own, and the transaction that records its result to begin
+func expectClaim(mock sqlmock.Sqlmock, id uuid.UUID, attempts int) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "outbox_events" WHERE delivered_at IS NULL AND next_attempt_at <= \$1 ORDER BY created_at LIMIT 10 FOR UPDATE SKIP LOCKED`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "aggregate_id", "payload", "attempts"}).
+			AddRow(id.String(), OrderPlaced, "order-1", `{"orderId":"order-1"}`, attempts))
+	mock.ExpectExec(`UPDATE "outbox_events" SET "next_attempt_at"=\$1 WHERE id IN \(\$2\)`).
+		WithArgs(sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+}
+
+func TestDispatcher_DeliversToEveryConsumer(t *testing.T) {
+	d, mock := newTestDispatcher(t)
+	id := uuid.New()
+	var delivered []string
+	d.Register("first", func(ctx context.Context, e *models.OutboxEvent) error {
+		delivered = append(delivered, "first:"+e.Type)
+		return nil
+	})
+	d.Register("second", func(ctx context.Context, e *models.OutboxEvent) error {
+		delivered = append(delivered, "second:"+e.Type)
+		return nil
+	})
+
+	expectClaim(mock, id, 0)
+	mock.ExpectExec(`UPDATE "outbox_events" SET "attempts"=\$1,"delivered_at"=\$2 WHERE "id" = \$3`).
+		WithArgs(1, sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	n, err := d.dispatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"first:OrderPlaced", "second:OrderPlaced"}, delivered)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDispatcher_RetriesFailedDelivery(t *testing.T) {
+	d, mock := newTestDispatcher(t)
+	id := uuid.New()
+	d.Register("failing", func(ctx context.Context, e *models.OutboxEvent) error {
+		return errors.New("unavailable")
+	})
+
+	expectClaim(mock, id, 2)
+	mock.ExpectExec(`UPDATE "outbox_events" SET "attempts"=\$1,"last_error"=\$2,"next_attempt_at"=\$3 WHERE "id" = \$4`).
+		WithArgs(3, "consumer failing: unavailable", sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	_, err := d.dispatch(context.Background())
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDispatcher_FailingConsumerDoesNotHoldBackOthers(t *testing.T) {
+	d, mock := newTestDispatcher(t)
+	id := uuid.New()
+	delivered := 0
+	d.Register("failing", func(ctx context.Context, e *models.OutboxEvent) error {
+		return errors.New("unavailable")
+	})
+	d.Register("counter", func(ctx context.Context, e *models.OutboxEvent) error {
+		delivered++
+		return nil
+	})
+	d.Register("broken", func(ctx context.Context, e *models.OutboxEvent) error {
+		return errors.New("timeout")
+	})
+
+	expectClaim(mock, id, 0)
+	mock.ExpectExec(`UPDATE "outbox_events" SET "attempts"=\$1,"last_error"=\$2,"next_attempt_at"=\$3 WHERE "id" = \$4`).
+		WithArgs(1, "consumer failing: unavailable; consumer broken: timeout", sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	_, err := d.dispatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDispatcher_NothingDue(t *testing.T) {
+	d, mock := newTestDispatcher(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "outbox_events"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+
+	n, err := d.dispatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	d, _ := newTestDispatcher(t)
+
+	assert.Equal(t, time.Second, d.backoff(0))
+	assert.Equal(t, 8*time.Second, d.backoff(3))
+	assert.Equal(t, time.Minute, d.backoff(10))
+}
+
+func TestDispatcher_StopDrainsDueEvents(t *testing.T) {
+	d, mock := newTestDispatcher(t)
+	id := uuid.New()
+	delivered := 0
+	d.Register("counter", func(ctx context.Context, e *models.OutboxEvent) error {
+		delivered++
+		return nil
+	})
+
+	expectClaim(mock, id, 0)
+	mock.ExpectExec(`UPDATE "outbox_events"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	d.Start()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	d.Stop(ctx)
+
+	assert.Equal(t, 1, delivered)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
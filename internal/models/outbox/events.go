@@ -0,0 +1,91 @@
+package outbox
+
+import (
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/google/uuid"
+)
+
+// Types of the domain events
+const (
+	OrderPlaced    = "OrderPlaced"
+	OrderCanceled  = "OrderCanceled"
+	StockChanged   = "StockChanged"
+	ProductUpdated = "ProductUpdated"
+	UserSignedUp   = "UserSignedUp"
+
+	// PaymentRequested is internal to the service, so webhooks cannot subscribe to it
+	PaymentRequested = "PaymentRequested"
+)
+
+// Payment steps that can be requested
+const (
+	PaymentCapture = "capture"
+	PaymentRefund  = "refund"
+)
+
+// IsEventType checks if the given name is one of the event types
+func IsEventType(name string) bool {
+	switch name {
+	case OrderPlaced, OrderCanceled, StockChanged, ProductUpdated, UserSignedUp:
+		return true
+	}
+	return false
+}
+
+// OrderPlacedEvent is recorded when an order is placed
+type OrderPlacedEvent struct {
+	OrderID    uuid.UUID          `json:"orderId"`
+	UserID     uuid.UUID          `json:"userId"`
+	TotalPrice money.Money        `json:"totalPrice"`
+	Tax        money.Money        `json:"tax"`
+	Currency   string             `json:"currency"`
+	Items      []OrderedItemEvent `json:"items"`
+}
+
+// OrderedItemEvent is an item of a placed order
+type OrderedItemEvent struct {
+	SKU      string `json:"sku"`
+	Quantity uint   `json:"quantity"`
+}
+
+// OrderCanceledEvent is recorded when an order is canceled by its owner or an admin
+type OrderCanceledEvent struct {
+	OrderID    uuid.UUID `json:"orderId"`
+	UserID     uuid.UUID `json:"userId"`
+	CanceledBy uuid.UUID `json:"canceledBy"`
+	Note       string    `json:"note"`
+}
+
+// StockChangedEvent is recorded when the stock of a product is decreased by an order or restored by a cancellation
+type StockChangedEvent struct {
+	ProductID uuid.UUID `json:"productId"`
+	SKU       string    `json:"sku"`
+	Delta     int       `json:"delta"`
+	Number    uint      `json:"number"`
+}
+
+// UserSignedUpEvent is recorded when a user is created
+type UserSignedUpEvent struct {
+	UserID    uuid.UUID `json:"userId"`
+	Email     string    `json:"email"`
+	FirstName string    `json:"firstName"`
+	LastName  string    `json:"lastName"`
+	Role      string    `json:"role"`
+}
+
+// ProductUpdatedEvent is recorded when a product is updated by an admin
+type ProductUpdatedEvent struct {
+	ProductID    uuid.UUID   `json:"productId"`
+	SKU          string      `json:"sku"`
+	Name         string      `json:"name"`
+	CategoryName string      `json:"categoryName"`
+	Price        money.Money `json:"price"`
+}
+
+// PaymentRequestedEvent is recorded when a status change of an order needs its payment to be captured or refunded
+// note that the payment step runs after the status change is committed, so no row is locked while the provider is called
+type PaymentRequestedEvent struct {
+	PaymentID uuid.UUID `json:"paymentId"`
+	OrderID   uuid.UUID `json:"orderId"`
+	Action    string    `json:"action"`
+}
@@ -0,0 +1,104 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepository struct {
+	db *gorm.DB
+}
+
+func (or *OutboxRepository) Migration() {
+	or.db.AutoMigrate(&models.OutboxEvent{})
+}
+
+func NewOutboxRepository(db *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in the given transaction
+func (or *OutboxRepository) WithTx(tx *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{db: tx}
+}
+
+// Add records an event with the given payload
+// note that it should run in the transaction of the change that the event is about, so the event is kept only if the change is
+func (or *OutboxRepository) Add(eventType, aggregateID string, payload interface{}) error {
+	zap.L().Debug("outbox.repo.Add", zap.Reflect("type", eventType), zap.Reflect("aggregateID", aggregateID))
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		zap.L().Error("outbox.repo.Add failed to marshal payload", zap.Error(err))
+		return err
+	}
+	event := &models.OutboxEvent{
+		Type:          eventType,
+		AggregateID:   aggregateID,
+		Payload:       string(data),
+		NextAttemptAt: time.Now(),
+	}
+	if err := or.db.Create(event).Error; err != nil {
+		zap.L().Error("outbox.repo.Add failed to record event", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// claim fetches the undelivered events whose next attempt time has come, in the order they are recorded, and postpones their next attempt to the end of the lease
+// note that the rows are locked until the lease is committed and the rows locked by another dispatcher are skipped, so an event is not claimed by two dispatchers at once
+func (or *OutboxRepository) claim(limit int, leaseUntil time.Time) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := or.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("delivered_at IS NULL AND next_attempt_at <= ?", time.Now()).
+			Order("created_at").Limit(limit).Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		ids := make([]uuid.UUID, 0, len(events))
+		for i := range events {
+			ids = append(ids, events[i].ID)
+		}
+		return tx.Model(&models.OutboxEvent{}).Where("id IN ?", ids).Update("next_attempt_at", leaseUntil).Error
+	})
+	if err != nil {
+		zap.L().Error("outbox.repo.claim failed to claim events", zap.Error(err))
+		return nil, err
+	}
+	return events, nil
+}
+
+// markDelivered records that an event is delivered to all the consumers
+func (or *OutboxRepository) markDelivered(e *models.OutboxEvent) error {
+	now := time.Now()
+	if err := or.db.Model(e).Select("delivered_at", "attempts").Updates(map[string]interface{}{
+		"delivered_at": now,
+		"attempts":     e.Attempts + 1,
+	}).Error; err != nil {
+		zap.L().Error("outbox.repo.markDelivered failed to update event", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// markFailed records a failed delivery of an event and the time of its next attempt
+func (or *OutboxRepository) markFailed(e *models.OutboxEvent, cause error, next time.Time) error {
+	if err := or.db.Model(e).Select("attempts", "last_error", "next_attempt_at").Updates(map[string]interface{}{
+		"attempts":        e.Attempts + 1,
+		"last_error":      cause.Error(),
+		"next_attempt_at": next,
+	}).Error; err != nil {
+		zap.L().Error("outbox.repo.markFailed failed to update event", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/httpErrors"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const fakeProvider = "fake"
+
+// fakeAuthorization keeps the state of an authorization made by the fake gateway
+// note that an authorization of a previous run has no known amount, so any amount is accepted for it
+type fakeAuthorization struct {
+	amount   money.Money
+	unknown  bool
+	captured bool
+	voided   bool
+	refunded bool
+}
+
+// FakeGateway is an in-memory payment gateway to exercise the payment flow locally and in tests
+// note that its behaviour is driven by the payment configuration, so declines and failures can be simulated
+type FakeGateway struct {
+	cfg            config.PaymentConfig
+	mu             sync.Mutex
+	authorizations map[string]*fakeAuthorization
+}
+
+func NewFakeGateway(cfg config.PaymentConfig) *FakeGateway {
+	return &FakeGateway{cfg: cfg,
+		authorizations: make(map[string]*fakeAuthorization)}
+}
+
+// Name returns the name of the fake provider
+func (g *FakeGateway) Name() string {
+	return fakeProvider
+}
+
+// Authorize authorizes the amount unless it is above the configured decline limit
+func (g *FakeGateway) Authorize(orderID uuid.UUID, amount money.Money) (string, error) {
+	zap.L().Debug("payment.fakeGateway.Authorize", zap.Reflect("orderID", orderID), zap.Reflect("amount", amount))
+
+	if g.cfg.FakeDeclineAbove > 0 && money.FromMajor(g.cfg.FakeDeclineAbove, amount.Currency).Amount < amount.Amount {
+		return "", httpErrors.NewApiError(http.StatusPaymentRequired, "Payment is declined by the provider", nil)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	reference := fmt.Sprintf("%s_%s", fakeProvider, uuid.New())
+	g.authorizations[reference] = &fakeAuthorization{amount: amount}
+	return reference, nil
+}
+
+// Capture captures an authorization that is neither voided nor captured before
+func (g *FakeGateway) Capture(reference string, amount money.Money) error {
+	zap.L().Debug("payment.fakeGateway.Capture", zap.Reflect("reference", reference), zap.Reflect("amount", amount))
+
+	if g.cfg.FakeFailCapture {
+		return errors.New("Payment cannot be captured by the provider")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	a, err := g.get(reference)
+	if err != nil {
+		return err
+	}
+	if a.captured || a.voided {
+		return errors.New("Payment authorization is already closed")
+	}
+	if err := a.check(amount); err != nil {
+		return errors.New("Capture amount exceeds the authorized amount")
+	}
+	a.captured = true
+	return nil
+}
+
+// Void releases an authorization that is not captured
+func (g *FakeGateway) Void(reference string) error {
+	zap.L().Debug("payment.fakeGateway.Void", zap.Reflect("reference", reference))
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	a, err := g.get(reference)
+	if err != nil {
+		return err
+	}
+	if a.captured || a.voided {
+		return errors.New("Payment authorization is already closed")
+	}
+	a.voided = true
+	return nil
+}
+
+// Refund refunds a captured payment once
+func (g *FakeGateway) Refund(reference string, amount money.Money) error {
+	zap.L().Debug("payment.fakeGateway.Refund", zap.Reflect("reference", reference), zap.Reflect("amount", amount))
+
+	if g.cfg.FakeFailRefund {
+		return errors.New("Payment cannot be refunded by the provider")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	a, err := g.get(reference)
+	if err != nil {
+		return err
+	}
+	if !a.captured || a.refunded {
+		return errors.New("Payment is not captured or already refunded")
+	}
+	if err := a.check(amount); err != nil {
+		return errors.New("Refund amount exceeds the captured amount")
+	}
+	a.refunded = true
+	return nil
+}
+
+// get finds an authorization by its reference
+// note that fake references of a previous run are accepted, since the fake gateway does not persist its state
+func (g *FakeGateway) get(reference string) (*fakeAuthorization, error) {
+	if a, ok := g.authorizations[reference]; ok {
+		return a, nil
+	}
+	if !strings.HasPrefix(reference, fakeProvider+"_") {
+		return nil, errors.New("Payment authorization not found")
+	}
+	a := &fakeAuthorization{unknown: true}
+	g.authorizations[reference] = a
+	return a, nil
+}
+
+// check checks if the given amount is covered by the authorization
+func (a *fakeAuthorization) check(amount money.Money) error {
+	if a.unknown {
+		return nil
+	}
+	exceeds, err := a.amount.LessThan(amount)
+	if err != nil {
+		return err
+	}
+	if exceeds {
+		return errors.New("amount exceeds the authorization")
+	}
+	return nil
+}
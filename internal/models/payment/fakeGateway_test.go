@@ -0,0 +1,81 @@
+package payment
+
+import (
+	"testing"
+
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeGateway_AuthorizeCaptureRefund(t *testing.T) {
+	g := NewFakeGateway(config.PaymentConfig{})
+
+	reference, err := g.Authorize(uuid.New(), money.New(12000, "USD"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, reference)
+
+	assert.NoError(t, g.Capture(reference, money.New(12000, "USD")))
+	assert.Error(t, g.Capture(reference, money.New(12000, "USD")))
+	assert.Error(t, g.Void(reference))
+
+	assert.NoError(t, g.Refund(reference, money.New(12000, "USD")))
+	assert.Error(t, g.Refund(reference, money.New(12000, "USD")))
+}
+
+func TestFakeGateway_Decline(t *testing.T) {
+	g := NewFakeGateway(config.PaymentConfig{FakeDeclineAbove: 100})
+
+	_, err := g.Authorize(uuid.New(), money.New(15000, "USD"))
+	assert.Error(t, err)
+
+	_, err = g.Authorize(uuid.New(), money.New(8000, "USD"))
+	assert.NoError(t, err)
+}
+
+func TestFakeGateway_VoidThenCapture(t *testing.T) {
+	g := NewFakeGateway(config.PaymentConfig{})
+
+	reference, err := g.Authorize(uuid.New(), money.New(5000, "USD"))
+	require.NoError(t, err)
+
+	assert.NoError(t, g.Void(reference))
+	assert.Error(t, g.Capture(reference, money.New(5000, "USD")))
+	assert.Error(t, g.Refund(reference, money.New(5000, "USD")))
+}
+
+func TestFakeGateway_FailCapture(t *testing.T) {
+	g := NewFakeGateway(config.PaymentConfig{FakeFailCapture: true})
+
+	reference, err := g.Authorize(uuid.New(), money.New(5000, "USD"))
+	require.NoError(t, err)
+	assert.Error(t, g.Capture(reference, money.New(5000, "USD")))
+}
+
+func TestFakeGateway_UnknownReference(t *testing.T) {
+	g := NewFakeGateway(config.PaymentConfig{})
+
+	assert.Error(t, g.Capture("other_123", money.New(1000, "USD")))
+}
+
+func TestNewPaymentGateway(t *testing.T) {
+	fake := &config.Config{PaymentConfig: config.PaymentConfig{Provider: fakeProvider}}
+
+	g, err := NewPaymentGateway(fake, "local")
+	require.NoError(t, err)
+	assert.Equal(t, fakeProvider, g.Name())
+	_, err = NewPaymentGateway(fake, "test")
+	assert.NoError(t, err)
+
+	_, err = NewPaymentGateway(fake, "production")
+	assert.Error(t, err)
+	fake.PaymentConfig.AllowFake = true
+	_, err = NewPaymentGateway(fake, "production")
+	assert.NoError(t, err)
+	_, err = NewPaymentGateway(&config.Config{}, "local")
+	assert.Error(t, err)
+	_, err = NewPaymentGateway(&config.Config{PaymentConfig: config.PaymentConfig{Provider: "unknown"}}, "local")
+	assert.Error(t, err)
+}
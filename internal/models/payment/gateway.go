@@ -0,0 +1,44 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// PaymentGateway encapsulates the operations of a payment provider
+type PaymentGateway interface {
+	// Name returns the name of the provider that is stored on the payment records
+	Name() string
+	// Authorize holds the amount of an order and returns the reference of the authorization
+	Authorize(orderID uuid.UUID, amount money.Money) (string, error)
+	// Capture collects the authorized amount
+	Capture(reference string, amount money.Money) error
+	// Void releases an authorization that is not captured
+	Void(reference string) error
+	// Refund pays back a captured amount
+	Refund(reference string, amount money.Money) error
+}
+
+// NewPaymentGateway creates the payment gateway of the configured provider for the given app environment
+// note that the fake provider accepts any payment, so outside of the local and test environments it must be allowed explicitly by AllowFake
+func NewPaymentGateway(cfg *config.Config, env string) (PaymentGateway, error) {
+	switch cfg.PaymentConfig.Provider {
+	case "":
+		return nil, errors.New("payment provider is not configured")
+	case fakeProvider:
+		if env != "local" && env != "test" {
+			if !cfg.PaymentConfig.AllowFake {
+				return nil, fmt.Errorf("payment provider %s is not allowed in %s environment", fakeProvider, env)
+			}
+			zap.L().Warn("payment.gateway uses the fake provider, every payment is accepted", zap.String("env", env))
+		}
+		return NewFakeGateway(cfg.PaymentConfig), nil
+	default:
+		return nil, fmt.Errorf("payment provider %s is not supported", cfg.PaymentConfig.Provider)
+	}
+}
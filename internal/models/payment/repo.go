@@ -0,0 +1,89 @@
+package payment
+
+import (
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/outbox"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/database"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentRepository struct {
+	db     *gorm.DB
+	events *outbox.OutboxRepository
+}
+
+func (pr *PaymentRepository) Migration() {
+	pr.db.AutoMigrate(&models.Payment{})
+	if err := database.MigrateMoneyColumn(pr.db, "payments", "amount", "amount_"); err != nil {
+		zap.L().Fatal("payment.repo.Migration failed to migrate money column", zap.Error(err))
+	}
+}
+
+func NewPaymentRepository(db *gorm.DB) *PaymentRepository {
+	return &PaymentRepository{db: db, events: outbox.NewOutboxRepository(db)}
+}
+
+// WithTx returns a copy of the repository that runs its queries in the given transaction
+func (pr *PaymentRepository) WithTx(tx *gorm.DB) *PaymentRepository {
+	return &PaymentRepository{db: tx, events: pr.events.WithTx(tx)}
+}
+
+// create creates a payment in the database
+func (pr *PaymentRepository) create(p *models.Payment) error {
+	zap.L().Debug("payment.repo.create", zap.Reflect("payment", p))
+
+	if err := pr.db.Create(p).Error; err != nil {
+		zap.L().Error("payment.repo.create failed to create payment", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// getOpenWithOrderIDForUpdate fetches the latest payment of an order that is not voided or refunded and locks its row
+// note that it returns nil if the order has no open payment, such as the orders placed before payments are introduced
+func (pr *PaymentRepository) getOpenWithOrderIDForUpdate(orderID uuid.UUID) (*models.Payment, error) {
+	zap.L().Debug("payment.repo.getOpenWithOrderIDForUpdate", zap.Reflect("orderID", orderID))
+
+	var payments []models.Payment
+	if err := pr.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("order_id = ? AND status IN ?", orderID, []string{models.PaymentStatusAuthorized, models.PaymentStatusCaptured}).Order("created_at desc").Limit(1).Find(&payments).Error; err != nil {
+		zap.L().Error("payment.repo.getOpenWithOrderIDForUpdate failed to get payment", zap.Error(err))
+		return nil, err
+	}
+	if len(payments) == 0 {
+		return nil, nil
+	}
+	return &payments[0], nil
+}
+
+// getWithID fetches a payment by ID from the database
+func (pr *PaymentRepository) getWithID(id uuid.UUID) (*models.Payment, error) {
+	var p *models.Payment
+	if err := pr.db.Where("id = ?", id).First(&p).Error; err != nil {
+		zap.L().Error("payment.repo.getWithID failed to get payment", zap.Error(err))
+		return nil, err
+	}
+	return p, nil
+}
+
+// updateStatus sets the status of a payment
+func (pr *PaymentRepository) updateStatus(p *models.Payment, status string) error {
+	zap.L().Debug("payment.repo.updateStatus", zap.Reflect("payment", p.ID), zap.Reflect("status", status))
+
+	if err := pr.db.Model(p).Select("status").Update("status", status).Error; err != nil {
+		zap.L().Error("payment.repo.updateStatus failed to update payment", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// request records a payment step to run after the surrounding transaction is committed
+func (pr *PaymentRepository) request(p *models.Payment, action string) error {
+	return pr.events.Add(outbox.PaymentRequested, p.ID.String(), outbox.PaymentRequestedEvent{
+		PaymentID: p.ID,
+		OrderID:   p.OrderID,
+		Action:    action,
+	})
+}
@@ -0,0 +1,127 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/outbox"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// PaymentService runs the payment steps of the order lifecycle through the payment gateway and records the payments
+type PaymentService struct {
+	repo    *PaymentRepository
+	gateway PaymentGateway
+}
+
+func NewPaymentService(repo *PaymentRepository, gateway PaymentGateway) *PaymentService {
+	return &PaymentService{repo: repo,
+		gateway: gateway}
+}
+
+// Authorize authorizes the total price of an order and returns the payment to record with the order
+// note that the amount is charged in the currency that the order is placed in, and the provider is called outside of any transaction,
+// so the payment should be released when the order cannot be saved
+func (ps *PaymentService) Authorize(o *models.Order) (*models.Payment, error) {
+	zap.L().Debug("payment.service.Authorize", zap.Reflect("orderID", o.ID))
+
+	amount := o.Rate().Apply(o.TotalPrice)
+	reference, err := ps.gateway.Authorize(o.ID, amount)
+	if err != nil {
+		zap.L().Error("payment.service.Authorize failed to authorize payment", zap.Error(err))
+		return nil, err
+	}
+	return &models.Payment{
+		OrderID:   o.ID,
+		Provider:  ps.gateway.Name(),
+		Reference: reference,
+		Amount:    amount,
+		Status:    models.PaymentStatusAuthorized,
+	}, nil
+}
+
+// Record saves an authorized payment in the given transaction
+func (ps *PaymentService) Record(tx *gorm.DB, p *models.Payment) error {
+	return ps.repo.WithTx(tx).create(p)
+}
+
+// Release voids an authorization whose order could not be saved
+// note that it is a best effort compensation, so failures are only logged
+func (ps *PaymentService) Release(p *models.Payment) {
+	zap.L().Debug("payment.service.Release", zap.Reflect("reference", p.Reference))
+
+	if err := ps.gateway.Void(p.Reference); err != nil {
+		zap.L().Error("payment.service.Release failed to void payment", zap.Reflect("reference", p.Reference), zap.Error(err))
+	}
+}
+
+// RequestCapture records the capture of the authorized payment of an order in the given transaction
+// note that the provider is not called here, the capture runs by Consume once the transaction is committed
+func (ps *PaymentService) RequestCapture(tx *gorm.DB, o *models.Order) error {
+	zap.L().Debug("payment.service.RequestCapture", zap.Reflect("orderID", o.ID))
+
+	repo := ps.repo.WithTx(tx)
+	p, err := repo.getOpenWithOrderIDForUpdate(o.ID)
+	if err != nil {
+		return err
+	}
+	if p == nil || p.Status != models.PaymentStatusAuthorized {
+		return nil
+	}
+	return repo.request(p, outbox.PaymentCapture)
+}
+
+// RequestRefund records giving the payment of an order back in the given transaction
+// note that the provider is not called here, the refund runs by Consume once the transaction is committed
+func (ps *PaymentService) RequestRefund(tx *gorm.DB, o *models.Order) error {
+	zap.L().Debug("payment.service.RequestRefund", zap.Reflect("orderID", o.ID))
+
+	repo := ps.repo.WithTx(tx)
+	p, err := repo.getOpenWithOrderIDForUpdate(o.ID)
+	if err != nil || p == nil {
+		return err
+	}
+	return repo.request(p, outbox.PaymentRefund)
+}
+
+// Consume runs the payment steps requested by the order lifecycle through the payment gateway
+// note that a step is decided by the current status of the payment, so a step that is already done is skipped on a retry,
+// and an authorization that is not captured yet is voided instead of refunded
+func (ps *PaymentService) Consume(ctx context.Context, e *models.OutboxEvent) error {
+	if e.Type != outbox.PaymentRequested {
+		return nil
+	}
+	var req outbox.PaymentRequestedEvent
+	if err := json.Unmarshal([]byte(e.Payload), &req); err != nil {
+		return err
+	}
+	zap.L().Debug("payment.service.Consume", zap.Reflect("paymentID", req.PaymentID), zap.Reflect("action", req.Action))
+
+	p, err := ps.repo.getWithID(req.PaymentID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case req.Action == outbox.PaymentCapture && p.Status == models.PaymentStatusAuthorized:
+		if err := ps.gateway.Capture(p.Reference, p.Amount); err != nil {
+			zap.L().Error("payment.service.Consume failed to capture payment", zap.Error(err))
+			return err
+		}
+		return ps.repo.updateStatus(p, models.PaymentStatusCaptured)
+	case req.Action == outbox.PaymentRefund && p.Status == models.PaymentStatusAuthorized:
+		if err := ps.gateway.Void(p.Reference); err != nil {
+			zap.L().Error("payment.service.Consume failed to void payment", zap.Error(err))
+			return err
+		}
+		return ps.repo.updateStatus(p, models.PaymentStatusVoided)
+	case req.Action == outbox.PaymentRefund && p.Status == models.PaymentStatusCaptured:
+		if err := ps.gateway.Refund(p.Reference, p.Amount); err != nil {
+			zap.L().Error("payment.service.Consume failed to refund payment", zap.Error(err))
+			return err
+		}
+		return ps.repo.updateStatus(p, models.PaymentStatusRefunded)
+	}
+	return nil
+}
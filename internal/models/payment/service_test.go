@@ -0,0 +1,102 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/outbox"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func newTestService(t *testing.T, cfg config.PaymentConfig) (*PaymentService, *FakeGateway, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db, PreferSimpleProtocol: true}), &gorm.Config{})
+	require.NoError(t, err)
+	g := NewFakeGateway(cfg)
+	return NewPaymentService(NewPaymentRepository(gdb), g), g, mock
+}
+
+func paymentRequested(paymentID uuid.UUID, action string) *models.OutboxEvent {
+	return &models.OutboxEvent{
+		ID:      uuid.New(),
+		Type:    outbox.PaymentRequested,
+		Payload: fmt.Sprintf(`{"paymentId":%q,"orderId":%q,"action":%q}`, paymentID, uuid.New(), action),
+	}
+}
+
+func expectPayment(mock sqlmock.Sqlmock, id uuid.UUID, reference, status string) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "payments" WHERE id = $1`)).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "reference", "amount_amount", "amount_currency", "status"}).
+			AddRow(id, reference, 5000, "USD", status))
+}
+
+func TestConsume_Capture(t *testing.T) {
+	ps, g, mock := newTestService(t, config.PaymentConfig{})
+	reference, err := g.Authorize(uuid.New(), money.New(5000, "USD"))
+	require.NoError(t, err)
+	id := uuid.New()
+
+	expectPayment(mock, id, reference, models.PaymentStatusAuthorized)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "payments" SET "status"=$1`)).
+		WithArgs(models.PaymentStatusCaptured, sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, ps.Consume(context.Background(), paymentRequested(id, outbox.PaymentCapture)))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConsume_FailedCaptureIsRetried(t *testing.T) {
+	ps, g, mock := newTestService(t, config.PaymentConfig{FakeFailCapture: true})
+	reference, err := g.Authorize(uuid.New(), money.New(5000, "USD"))
+	require.NoError(t, err)
+	id := uuid.New()
+
+	// the payment is left authorized, so the outbox retries the capture
+	expectPayment(mock, id, reference, models.PaymentStatusAuthorized)
+
+	assert.Error(t, ps.Consume(context.Background(), paymentRequested(id, outbox.PaymentCapture)))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConsume_RefundVoidsAuthorization(t *testing.T) {
+	ps, g, mock := newTestService(t, config.PaymentConfig{})
+	reference, err := g.Authorize(uuid.New(), money.New(5000, "USD"))
+	require.NoError(t, err)
+	id := uuid.New()
+
+	expectPayment(mock, id, reference, models.PaymentStatusAuthorized)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "payments" SET "status"=$1`)).
+		WithArgs(models.PaymentStatusVoided, sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, ps.Consume(context.Background(), paymentRequested(id, outbox.PaymentRefund)))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConsume_StepAlreadyDone(t *testing.T) {
+	ps, _, mock := newTestService(t, config.PaymentConfig{})
+	id := uuid.New()
+
+	// a retried request of a payment that is refunded in the meantime does not call the provider
+	expectPayment(mock, id, "fake_1", models.PaymentStatusRefunded)
+
+	require.NoError(t, ps.Consume(context.Background(), paymentRequested(id, outbox.PaymentRefund)))
+	require.NoError(t, ps.Consume(context.Background(), &models.OutboxEvent{Type: outbox.OrderPlaced}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 
 	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
 	"go.uber.org/zap"
 )
 
@@ -60,7 +61,8 @@ func toStruct(jobs <-chan []string, results chan<- models.Product, wg *sync.Wait
 	defer wg.Done()
 
 	for j := range jobs {
-		priceParsed, err := strconv.ParseFloat(j[2], 32)
+		// prices are given in major units of the base currency
+		priceParsed, err := money.Parse(j[2], money.DefaultCurrency)
 		if err != nil {
 			return
 		}
@@ -72,10 +74,18 @@ func toStruct(jobs <-chan []string, results chan<- models.Product, wg *sync.Wait
 		product := models.Product{
 			CategoryName: &j[0],
 			Name:         &j[1],
-			Price:        float32(priceParsed),
+			Price:        priceParsed,
 			Stock: models.Stock{SKU: j[3],
 				Number: uint(stockNumberParsed),
 			}}
+		// weight in grams is an optional sixth column
+		if len(j) > 5 && j[5] != "" {
+			weightParsed, err := strconv.Atoi(j[5])
+			if err != nil || weightParsed < 0 {
+				return
+			}
+			product.Weight = uint(weightParsed)
+		}
 		results <- product
 	}
 
@@ -4,11 +4,18 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/cagrikilicoglu/shopping-basket/internal/api"
+	"github.com/cagrikilicoglu/shopping-basket/internal/httpErrors"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/currency"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/reservation"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models/response"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/tax"
 	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
 	"github.com/cagrikilicoglu/shopping-basket/pkg/middleware"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
 	"github.com/cagrikilicoglu/shopping-basket/pkg/pagination"
 	"github.com/gin-gonic/gin"
 	"github.com/go-openapi/strfmt"
@@ -16,12 +23,16 @@ import (
 )
 
 type productHandler struct {
-	repo *ProductRepository
+	repo         *ProductRepository
+	taxes        *tax.Calculator
+	reservations *reservation.Reservations
 }
 
-func NewProductHandler(r *gin.RouterGroup, repo *ProductRepository, cfg *config.Config) {
+func NewProductHandler(r *gin.RouterGroup, repo *ProductRepository, taxes *tax.Calculator, reservations *reservation.Reservations, cfg *config.Config) {
 
-	h := &productHandler{repo: repo}
+	h := &productHandler{repo: repo,
+		taxes:        taxes,
+		reservations: reservations}
 	r.GET("/", h.getAll)
 	r.GET("/id/:id", h.getByID)
 	r.GET("/sku/:sku", h.getBySKU)
@@ -43,7 +54,8 @@ func (p *productHandler) getAll(c *gin.Context) {
 		response.RespondWithError(c, err)
 		return
 	}
-	paginatedResult := pagination.NewFromGinRequest(c, count, ProductsToResponse(products))
+	p.setAvailable(productPointers(products)...)
+	paginatedResult := pagination.NewFromGinRequest(c, count, ProductsToResponse(products, currency.RateFromCtx(c), p.taxes))
 
 	response.RespondWithJson(c, http.StatusOK, paginatedResult)
 }
@@ -60,7 +72,8 @@ func (p *productHandler) getByID(c *gin.Context) {
 		return
 	}
 
-	response.RespondWithJson(c, http.StatusOK, ProductToResponse(product))
+	p.setAvailable(product)
+	response.RespondWithJson(c, http.StatusOK, ProductToResponse(product, currency.RateFromCtx(c), p.taxes))
 }
 
 // getBySKU fetches a product by SKU
@@ -74,7 +87,8 @@ func (p *productHandler) getBySKU(c *gin.Context) {
 		response.RespondWithError(c, err)
 		return
 	}
-	response.RespondWithJson(c, http.StatusOK, ProductToResponse(product))
+	p.setAvailable(product)
+	response.RespondWithJson(c, http.StatusOK, ProductToResponse(product, currency.RateFromCtx(c), p.taxes))
 }
 
 // create creates a product by the input in request body
@@ -94,12 +108,17 @@ func (p *productHandler) create(c *gin.Context) {
 		return
 	}
 
+	if err := checkBaseCurrency(productBody); err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
 	product, err := p.repo.create(responseToProduct(productBody))
 	if err != nil {
 		response.RespondWithError(c, err)
 	}
 
-	response.RespondWithJson(c, http.StatusCreated, ProductToResponseForAdmin(product))
+	response.RespondWithJson(c, http.StatusCreated, ProductToResponseForAdmin(product, currency.RateFromCtx(c)))
 }
 
 // createFromFile reads data from a csv file and create products from it
@@ -123,7 +142,7 @@ func (p *productHandler) createFromFile(c *gin.Context) {
 		return
 	}
 
-	response.RespondWithJson(c, http.StatusCreated, productsToResponseForAdmin(&products))
+	response.RespondWithJson(c, http.StatusCreated, productsToResponseForAdmin(&products, currency.RateFromCtx(c)))
 }
 
 // getByName fetches products by name
@@ -141,7 +160,8 @@ func (p *productHandler) getByName(c *gin.Context) {
 		response.RespondWithError(c, err)
 		return
 	}
-	response.RespondWithJson(c, http.StatusOK, ProductsToResponse(products))
+	p.setAvailable(productPointers(products)...)
+	response.RespondWithJson(c, http.StatusOK, ProductsToResponse(products, currency.RateFromCtx(c), p.taxes))
 }
 
 // deleteBySKU deletes a product by SKU
@@ -175,12 +195,43 @@ func (p *productHandler) updateBySKU(c *gin.Context) {
 		return
 	}
 
+	if err := checkBaseCurrency(productBody); err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
 	product, err := p.repo.updateBySKU(sku, responseToProduct(productBody))
 	if err != nil {
 		response.RespondWithError(c, err)
 		return
 	}
 
-	response.RespondWithJson(c, http.StatusOK, ProductToResponseForAdmin(product))
+	p.setAvailable(product)
+	response.RespondWithJson(c, http.StatusOK, ProductToResponseForAdmin(product, currency.RateFromCtx(c)))
 
 }
+
+// setAvailable sets the stock of the products that is not held by the carts
+// note that the products are shown without it when it cannot be calculated
+func (p *productHandler) setAvailable(ps ...*models.Product) {
+	if err := p.reservations.SetAvailable(ps...); err != nil {
+		zap.L().Error("product.handler.setAvailable failed to calculate available stock", zap.Error(err))
+	}
+}
+
+// productPointers returns pointers to the products in the slice
+func productPointers(ps *[]models.Product) []*models.Product {
+	pointers := make([]*models.Product, 0, len(*ps))
+	for i := range *ps {
+		pointers = append(pointers, &(*ps)[i])
+	}
+	return pointers
+}
+
+// checkBaseCurrency checks that the price of a product is given in the base currency
+func checkBaseCurrency(ap *api.Product) error {
+	if ap.Price.Currency != nil && *ap.Price.Currency != "" && strings.ToUpper(*ap.Price.Currency) != money.DefaultCurrency {
+		return httpErrors.NewApiError(http.StatusBadRequest, fmt.Sprintf("Product prices should be given in the base currency %s", money.DefaultCurrency), nil)
+	}
+	return nil
+}
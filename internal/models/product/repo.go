@@ -4,21 +4,40 @@ import (
 	"errors"
 
 	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/outbox"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/database"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
 type ProductRepository struct {
-	db *gorm.DB
+	db     *gorm.DB
+	events *outbox.OutboxRepository
 }
 
 func NewProductRepository(db *gorm.DB) *ProductRepository {
-	return &ProductRepository{db: db}
+	return &ProductRepository{db: db, events: outbox.NewOutboxRepository(db)}
 }
 
 func (pr *ProductRepository) Migration() {
 	pr.db.AutoMigrate(&models.Product{})
+	if err := database.MigrateMoneyColumn(pr.db, "products", "price", "price_"); err != nil {
+		zap.L().Fatal("product.repo.Migration failed to migrate money column", zap.Error(err))
+	}
+
+	// AutoMigrate only adds check constraints while creating the table, so the stock guard is created explicitly for existing databases
+	if !pr.db.Migrator().HasConstraint(&models.Product{}, "chk_products_stock_number") {
+		if err := pr.db.Migrator().CreateConstraint(&models.Product{}, "chk_products_stock_number"); err != nil {
+			zap.L().Error("product.repo.Migration failed to create stock constraint", zap.Error(err))
+		}
+	}
+}
+
+// WithTx returns a copy of the repository that runs its queries in the given transaction
+func (pr *ProductRepository) WithTx(tx *gorm.DB) *ProductRepository {
+	return &ProductRepository{db: tx, events: pr.events.WithTx(tx)}
 }
 
 // create creates a product in the database
@@ -83,6 +102,20 @@ func (pr *ProductRepository) GetBySKU(sku string) (*models.Product, error) {
 	return product, nil
 }
 
+// GetBySKUForUpdate fetches a product by SKU and locks its row until the surrounding transaction ends
+// note that it should only be called on a repository bound to a transaction by WithTx
+func (pr *ProductRepository) GetBySKUForUpdate(sku string) (*models.Product, error) {
+
+	zap.L().Debug("product.repo.GetBySKUForUpdate", zap.Reflect("SKU", sku))
+	var product *models.Product
+
+	if result := pr.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where(&models.Product{Stock: models.Stock{SKU: sku}}).First(&product); result.Error != nil {
+		zap.L().Error("product.repo.GetBySKUForUpdate failed to get product", zap.Error(result.Error))
+		return nil, result.Error
+	}
+	return product, nil
+}
+
 // getByName fetches products by name from the database
 // note that the search is elastic
 func (pr *ProductRepository) getByName(name string) (*[]models.Product, error) {
@@ -110,23 +143,84 @@ func (pr *ProductRepository) deleteBySKU(sku string) error {
 }
 
 // updateBySKU updates a product by SKU from the database
+// note that a ProductUpdated event is recorded in the same transaction
 func (pr *ProductRepository) updateBySKU(sku string, p *models.Product) (*models.Product, error) {
 	zap.L().Debug("product.repo.updateBySKU", zap.Reflect("product", p))
 
-	if err := pr.db.Model(models.Product{}).Where("sku = ?", sku).Updates(p).First(p).Error; err != nil {
+	err := pr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(models.Product{}).Where("sku = ?", sku).Updates(p).First(p).Error; err != nil {
+			return err
+		}
+		return pr.events.WithTx(tx).Add(outbox.ProductUpdated, p.ID.String(), productUpdatedEvent(p))
+	})
+	if err != nil {
 		zap.L().Error("product.repo.updateBySKU failed to update product", zap.Error(err))
 		return nil, err
 	}
 	return p, nil
 }
 
-// UpdateStock updates stock number of a product by SKU and quantity inputs
+// RestoreStock increases stock number of a product by ID and quantity inputs
+// note that the stock of a deleted product is also restored, so that it is correct if the product is recovered
+func (pr *ProductRepository) RestoreStock(id uuid.UUID, quantity uint) error {
+	zap.L().Debug("product.repo.RestoreStock", zap.Reflect("id", id), zap.Reflect("quantity", quantity))
+
+	return pr.db.Transaction(func(tx *gorm.DB) error {
+		var p models.Product
+		result := tx.Unscoped().Model(&p).Clauses(clause.Returning{}).Where("id = ?", id).Select("number").Update("number", gorm.Expr("number + ?", quantity))
+		if result.Error != nil {
+			zap.L().Error("product.repo.RestoreStock failed to update product", zap.Error(result.Error))
+			return result.Error
+		}
+		if result.RowsAffected < 1 {
+			return errors.New("Product not found")
+		}
+		return pr.events.WithTx(tx).Add(outbox.StockChanged, p.ID.String(), stockChangedEvent(&p, int(quantity)))
+	})
+}
+
+// UpdateStock decreases stock number of a product by SKU and quantity inputs
+// note that the update is refused if the stock is not enough for the given quantity
 func (pr *ProductRepository) UpdateStock(sku string, quantity uint) error {
 
-	if result := pr.db.Model(models.Product{}).Where("sku = ?", sku).Select("number").Update("number", gorm.Expr("number - ?", quantity)); result.Error != nil {
-		zap.L().Error("product.repo.UpdateStock failed to update product", zap.Error(result.Error))
-		return result.Error
+	return pr.db.Transaction(func(tx *gorm.DB) error {
+		var p models.Product
+		result := tx.Model(&p).Clauses(clause.Returning{}).Where("sku = ? AND number >= ?", sku, quantity).Select("number").Update("number", gorm.Expr("number - ?", quantity))
+		if result.Error != nil {
+			zap.L().Error("product.repo.UpdateStock failed to update product", zap.Error(result.Error))
+			return result.Error
+		}
+		if result.RowsAffected < 1 {
+			zap.L().Error("product.repo.UpdateStock not enough stock", zap.Reflect("sku", sku), zap.Reflect("quantity", quantity))
+			return errors.New("Not enough stock for the product")
+		}
+		return pr.events.WithTx(tx).Add(outbox.StockChanged, p.ID.String(), stockChangedEvent(&p, -int(quantity)))
+	})
+
+}
+
+// productUpdatedEvent creates the ProductUpdated event of a product
+func productUpdatedEvent(p *models.Product) outbox.ProductUpdatedEvent {
+	e := outbox.ProductUpdatedEvent{
+		ProductID: p.ID,
+		SKU:       p.Stock.SKU,
+		Price:     p.Price,
 	}
-	return nil
+	if p.Name != nil {
+		e.Name = *p.Name
+	}
+	if p.CategoryName != nil {
+		e.CategoryName = *p.CategoryName
+	}
+	return e
+}
 
+// stockChangedEvent creates the StockChanged event of a product whose stock is changed by the given delta
+func stockChangedEvent(p *models.Product, delta int) outbox.StockChangedEvent {
+	return outbox.StockChangedEvent{
+		ProductID: p.ID,
+		SKU:       p.Stock.SKU,
+		Delta:     delta,
+		Number:    p.Stock.Number,
+	}
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -63,7 +64,7 @@ var product = models.Product{
 	DeletedAt: gorm.DeletedAt{},
 	ID:        id,
 	Name:      &name,
-	Price:     float32(12.0),
+	Price:     money.New(1200, "USD"),
 	Stock:     stock,
 }
 
@@ -71,8 +72,8 @@ func (s *Suite) TestProductRepository_GetBySKU() {
 	var (
 		query_1 = `SELECT * FROM "products" WHERE "products"."sku" = $1 AND "products"."deleted_at" IS NULL ORDER BY "products"."id" LIMIT 1`
 
-		row_1 = sqlmock.NewRows([]string{"created_at", "updated_at", "deleted_at", "id", "name", "price", "sku", "number"}).
-			AddRow(product.CreatedAt, product.UpdatedAt, product.DeletedAt, product.ID.String(), product.Name, product.Price, product.Stock.SKU, product.Stock.Number)
+		row_1 = sqlmock.NewRows([]string{"created_at", "updated_at", "deleted_at", "id", "name", "price_amount", "price_currency", "sku", "number"}).
+			AddRow(product.CreatedAt, product.UpdatedAt, product.DeletedAt, product.ID.String(), product.Name, product.Price.Amount, product.Price.Currency, product.Stock.SKU, product.Stock.Number)
 	)
 
 	s.mock.ExpectQuery(regexp.QuoteMeta(
@@ -86,12 +87,98 @@ func (s *Suite) TestProductRepository_GetBySKU() {
 	require.True(s.T(), reflect.DeepEqual(&product, res))
 }
 
+func (s *Suite) TestProductRepository_GetBySKUForUpdate() {
+	var (
+		query_1 = `SELECT * FROM "products" WHERE "products"."sku" = $1 AND "products"."deleted_at" IS NULL ORDER BY "products"."id" LIMIT 1 FOR UPDATE`
+
+		row_1 = sqlmock.NewRows([]string{"created_at", "updated_at", "deleted_at", "id", "name", "price_amount", "price_currency", "sku", "number"}).
+			AddRow(product.CreatedAt, product.UpdatedAt, product.DeletedAt, product.ID.String(), product.Name, product.Price.Amount, product.Price.Currency, product.Stock.SKU, product.Stock.Number)
+	)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		query_1)).
+		WithArgs(product.Stock.SKU).
+		WillReturnRows(row_1)
+
+	res, err := s.repository.GetBySKUForUpdate(stock.SKU)
+
+	require.NoError(s.T(), err)
+	require.True(s.T(), reflect.DeepEqual(&product, res))
+}
+
+func (s *Suite) TestProductRepository_UpdateStock() {
+	var (
+		query_1 = `UPDATE "products" SET "number"=number - $1,"updated_at"=$2 WHERE (sku = $3 AND number >= $4) AND "products"."deleted_at" IS NULL RETURNING *`
+		query_2 = `INSERT INTO "outbox_events"`
+
+		row_1 = sqlmock.NewRows([]string{"id", "sku", "number"}).
+			AddRow(product.ID.String(), product.Stock.SKU, 8)
+	)
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		query_1)).
+		WithArgs(uint(2), sqlmock.AnyArg(), product.Stock.SKU, uint(2)).
+		WillReturnRows(row_1)
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		query_2)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "StockChanged", product.ID.String(), sqlmock.AnyArg(), 0, "", sqlmock.AnyArg(), nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repository.UpdateStock(stock.SKU, 2)
+
+	require.NoError(s.T(), err)
+}
+
+func (s *Suite) TestProductRepository_UpdateStock_NotEnoughStock() {
+	var (
+		query_1 = `UPDATE "products" SET "number"=number - $1,"updated_at"=$2 WHERE (sku = $3 AND number >= $4) AND "products"."deleted_at" IS NULL RETURNING *`
+	)
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		query_1)).
+		WithArgs(uint(11), sqlmock.AnyArg(), product.Stock.SKU, uint(11)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "sku", "number"}))
+	s.mock.ExpectRollback()
+
+	err := s.repository.UpdateStock(stock.SKU, 11)
+
+	require.Error(s.T(), err)
+}
+
+func (s *Suite) TestProductRepository_RestoreStock() {
+	var (
+		query_1 = `UPDATE "products" SET "number"=number + $1,"updated_at"=$2 WHERE id = $3 RETURNING *`
+		query_2 = `INSERT INTO "outbox_events"`
+
+		row_1 = sqlmock.NewRows([]string{"id", "sku", "number"}).
+			AddRow(product.ID.String(), product.Stock.SKU, 13)
+	)
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		query_1)).
+		WithArgs(uint(3), sqlmock.AnyArg(), product.ID).
+		WillReturnRows(row_1)
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		query_2)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "StockChanged", product.ID.String(), sqlmock.AnyArg(), 0, "", sqlmock.AnyArg(), nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repository.RestoreStock(product.ID, 3)
+
+	require.NoError(s.T(), err)
+}
+
 // func (s *Suite) TestProductRepository_GetByID() {
 // 	var (
 // 		query_1 = `SELECT * FROM "products" WHERE 	"products.id" = $1 AND "products"."deleted_at" IS NULL ORDER BY "products"."id" LIMIT 1`
 
-// 		row_1 = sqlmock.NewRows([]string{"created_at", "updated_at", "deleted_at", "id", "name", "price", "sku", "number"}).
-// 			AddRow(product.CreatedAt, product.UpdatedAt, product.DeletedAt, product.ID.String(), product.Name, product.Price, product.Stock.SKU, product.Stock.Number)
+// 		row_1 = sqlmock.NewRows([]string{"created_at", "updated_at", "deleted_at", "id", "name", "price_amount", "price_currency", "sku", "number"}).
+// 			AddRow(product.CreatedAt, product.UpdatedAt, product.DeletedAt, product.ID.String(), product.Name, product.Price.Amount, product.Price.Currency, product.Stock.SKU, product.Stock.Number)
 // 	)
 
 // 	s.mock.ExpectQuery(regexp.QuoteMeta(
@@ -109,8 +196,8 @@ func (s *Suite) TestProductRepository_GetBySKU() {
 // 	var (
 // 		query_1 = `SELECT * FROM "products" WHERE Name ILIKE $1 AND "products"."deleted_at" IS NULL ORDER BY name`
 
-// 		row_1 = sqlmock.NewRows([]string{"created_at", "updated_at", "deleted_at", "id", "name", "price", "sku", "number"}).
-// 			AddRow(product.CreatedAt, product.UpdatedAt, product.DeletedAt, product.ID.String(), product.Name, product.Price, product.Stock.SKU, product.Stock.Number)
+// 		row_1 = sqlmock.NewRows([]string{"created_at", "updated_at", "deleted_at", "id", "name", "price_amount", "price_currency", "sku", "number"}).
+// 			AddRow(product.CreatedAt, product.UpdatedAt, product.DeletedAt, product.ID.String(), product.Name, product.Price.Amount, product.Price.Currency, product.Stock.SKU, product.Stock.Number)
 // 		productSrc = "%" + name + "%"
 // 	)
 
@@ -3,60 +3,80 @@ package product
 import (
 	"github.com/cagrikilicoglu/shopping-basket/internal/api"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/response"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/tax"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
 	"go.uber.org/zap"
 )
 
 // ProductToResponse converts product database model to response model
-func ProductToResponse(p *models.Product) *api.Product {
+// note that the price is displayed in the tax mode of the given calculator and converted to the currency of the given rate
+func ProductToResponse(p *models.Product, rate money.Rate, taxes *tax.Calculator) *api.Product {
 	zap.L().Debug("Product.serializer.ProductToResponse", zap.Reflect("Products", p))
 	return &api.Product{
 		CategoryName: p.CategoryName,
 		Name:         p.Name,
-		Price:        &p.Price,
+		Price:        response.MoneyToResponse(rate.Apply(taxes.DisplayProduct(p))),
 		Stock: &api.Stock{
-			Sku: &p.Stock.SKU,
+			Sku:       &p.Stock.SKU,
+			Available: availableToResponse(p),
 		},
+		Weight:     uint32(p.Weight),
+		Dimensions: dimensionsToResponse(&p.Dimensions),
 	}
 }
 
 // ProductToResponseForAdmin converts product database model to response model for admin
-// note that the result show also the stock number of a product
-func ProductToResponseForAdmin(p *models.Product) *api.Product {
+// note that the result show also the stock number of a product, and its price is always without tax
+func ProductToResponseForAdmin(p *models.Product, rate money.Rate) *api.Product {
 	zap.L().Debug("Product.serializer.ProductToResponseForAdmin", zap.Reflect("Products", p))
 
 	stockNum := uint32(p.Stock.Number)
 	return &api.Product{
 		CategoryName: p.CategoryName,
 		Name:         p.Name,
-		Price:        &p.Price,
+		Price:        response.MoneyToResponse(rate.Apply(p.Price)),
 		Stock: &api.Stock{
-			Number: stockNum,
-			Sku:    &p.Stock.SKU,
+			Number:    stockNum,
+			Sku:       &p.Stock.SKU,
+			Available: availableToResponse(p),
 		},
+		Weight:     uint32(p.Weight),
+		Dimensions: dimensionsToResponse(&p.Dimensions),
 	}
 }
 
+// availableToResponse converts the stock of a product that is not held by the carts to response model
+// note that it is left out when the reservations are disabled
+func availableToResponse(p *models.Product) *uint32 {
+	if p.Available == nil {
+		return nil
+	}
+	available := uint32(*p.Available)
+	return &available
+}
+
 /// ProductToResponse converts product database model to response model
-func ProductsToResponse(ps *[]models.Product) []*api.Product {
+func ProductsToResponse(ps *[]models.Product, rate money.Rate, taxes *tax.Calculator) []*api.Product {
 	zap.L().Debug("Product.serializer.productsToResponse", zap.Reflect("Products", ps))
 
 	products := make([]*api.Product, 0)
 	for i := range *ps {
 		productsDeref := *ps
-		products = append(products, ProductToResponse(&productsDeref[i]))
+		products = append(products, ProductToResponse(&productsDeref[i], rate, taxes))
 	}
 	return products
 }
 
 // productsToResponseForAdmin converts product database model to response model as a batch for admin
 // note that the result show also the stock number of a product
-func productsToResponseForAdmin(ps *[]models.Product) []*api.Product {
+func productsToResponseForAdmin(ps *[]models.Product, rate money.Rate) []*api.Product {
 	zap.L().Debug("Product.serializer.productsToResponseForAdmin", zap.Reflect("Products", ps))
 
 	products := make([]*api.Product, 0)
 	for i := range *ps {
 		productsDeref := *ps
-		products = append(products, ProductToResponseForAdmin(&productsDeref[i]))
+		products = append(products, ProductToResponseForAdmin(&productsDeref[i], rate))
 	}
 	return products
 }
@@ -68,11 +88,38 @@ func responseToProduct(ap *api.Product) *models.Product {
 	stockNum := uint(ap.Stock.Number)
 	return &models.Product{
 		Name:  ap.Name,
-		Price: *ap.Price,
+		Price: response.ResponseToMoney(ap.Price),
 		Stock: models.Stock{
 			SKU:    *ap.Stock.Sku,
 			Number: stockNum,
 		},
 		CategoryName: ap.CategoryName,
+		Weight:       uint(ap.Weight),
+		Dimensions:   responseToDimensions(ap.Dimensions),
+	}
+}
+
+// dimensionsToResponse converts dimensions database model to response model
+// note that it returns nil when the dimensions of a product are not known
+func dimensionsToResponse(d *models.Dimensions) *api.Dimensions {
+	if *d == (models.Dimensions{}) {
+		return nil
+	}
+	return &api.Dimensions{
+		Length: uint32(d.Length),
+		Width:  uint32(d.Width),
+		Height: uint32(d.Height),
+	}
+}
+
+// responseToDimensions converts dimensions response model to database model
+func responseToDimensions(ad *api.Dimensions) models.Dimensions {
+	if ad == nil {
+		return models.Dimensions{}
+	}
+	return models.Dimensions{
+		Length: uint(ad.Length),
+		Width:  uint(ad.Width),
+		Height: uint(ad.Height),
 	}
 }
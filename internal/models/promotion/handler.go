@@ -0,0 +1,166 @@
+package promotion
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/api"
+	"github.com/cagrikilicoglu/shopping-basket/internal/httpErrors"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/response"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/middleware"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/pagination"
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/strfmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// basisPoints is 100% in basis points
+const basisPoints = 10000
+
+type promotionHandler struct {
+	repo *PromotionRepository
+}
+
+func NewPromotionHandler(r *gin.RouterGroup, repo *PromotionRepository, cfg *config.Config) {
+	h := &promotionHandler{repo: repo}
+
+	r.GET("/admin/promotions", middleware.AdminAuthMiddleware(cfg.JWTConfig.SecretKey), h.getAll)
+	r.POST("/admin/promotions", middleware.AdminAuthMiddleware(cfg.JWTConfig.SecretKey), h.create)
+	r.PUT("/admin/promotions/id/:id", middleware.AdminAuthMiddleware(cfg.JWTConfig.SecretKey), h.update)
+	r.DELETE("/admin/promotions/id/:id", middleware.AdminAuthMiddleware(cfg.JWTConfig.SecretKey), h.delete)
+}
+
+// getAll fetches the promotions in the order that they apply and paginates the results
+func (ph *promotionHandler) getAll(c *gin.Context) {
+	pageIndex, pageSize := pagination.GetPaginationParametersFromRequest(c)
+	zap.L().Debug("promotion.handler.getAll", zap.Reflect("pageIndex", pageIndex), zap.Reflect("pageSize", pageSize))
+
+	promotions, count, err := ph.repo.getAll(pageIndex, pagination.ClampPageSize(pageSize))
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	paginatedResult := pagination.NewFromGinRequest(c, count, promotionsToResponse(promotions))
+	response.RespondWithJson(c, http.StatusOK, paginatedResult)
+}
+
+// create creates a promotion by the input in request body
+func (ph *promotionHandler) create(c *gin.Context) {
+	zap.L().Debug("promotion.handler.create")
+
+	promotion, err := bindPromotion(c)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	created, err := ph.repo.create(promotion)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	response.RespondWithJson(c, http.StatusCreated, promotionToResponse(created))
+}
+
+// update updates a promotion by the input in request body
+func (ph *promotionHandler) update(c *gin.Context) {
+	id := c.Param("id")
+	zap.L().Debug("promotion.handler.update", zap.Reflect("id", id))
+
+	idParsed, err := uuid.Parse(id)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	promotion, err := bindPromotion(c)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	promotion.ID = idParsed
+
+	updated, err := ph.repo.update(promotion)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	response.RespondWithJson(c, http.StatusOK, promotionToResponse(updated))
+}
+
+// delete deletes a promotion
+func (ph *promotionHandler) delete(c *gin.Context) {
+	id := c.Param("id")
+	zap.L().Debug("promotion.handler.delete", zap.Reflect("id", id))
+
+	idParsed, err := uuid.Parse(id)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	if err := ph.repo.delete(idParsed); err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	response.RespondWithJson(c, http.StatusOK, "Promotion successfully deleted")
+}
+
+// bindPromotion binds and validates the promotion in the request body
+func bindPromotion(c *gin.Context) (*models.Promotion, error) {
+	promotionBody := &api.Promotion{}
+	if err := c.Bind(&promotionBody); err != nil {
+		return nil, err
+	}
+	if err := promotionBody.Validate(strfmt.NewFormats()); err != nil {
+		return nil, err
+	}
+
+	promotion := responseToPromotion(promotionBody)
+	if err := validatePromotion(promotion); err != nil {
+		return nil, err
+	}
+	return promotion, nil
+}
+
+// validatePromotion checks that the terms of a promotion can be applied to the carts
+// note that the bundle price should be in the base currency, since the prices of the products are kept in it
+func validatePromotion(p *models.Promotion) error {
+	if p.Name == "" {
+		return httpErrors.NewApiError(http.StatusBadRequest, "Promotion name should not be empty", nil)
+	}
+	switch p.Kind {
+	case KindPercentage:
+		if p.Percent <= 0 || p.Percent > basisPoints {
+			return httpErrors.NewApiError(http.StatusBadRequest, "Percent should be greater than 0 and at most 100", nil)
+		}
+	case KindBuyXGetY:
+		if p.BuyQuantity == 0 || p.GetQuantity == 0 {
+			return httpErrors.NewApiError(http.StatusBadRequest, "Buy quantity and get quantity should be greater than 0", nil)
+		}
+	case KindNthItem:
+		if p.Nth < 2 {
+			return httpErrors.NewApiError(http.StatusBadRequest, "Nth should be at least 2", nil)
+		}
+		if p.Percent <= 0 || p.Percent > basisPoints {
+			return httpErrors.NewApiError(http.StatusBadRequest, "Percent should be greater than 0 and at most 100", nil)
+		}
+	case KindBundle:
+		if len(p.SKUs) < 2 {
+			return httpErrors.NewApiError(http.StatusBadRequest, "Bundle should have at least 2 skus", nil)
+		}
+		if p.BundlePrice.Amount <= 0 {
+			return httpErrors.NewApiError(http.StatusBadRequest, "Bundle price should be greater than 0", nil)
+		}
+	default:
+		return httpErrors.NewApiError(http.StatusBadRequest, "Promotion kind should be one of percentage, buy_x_get_y, nth_item, bundle", nil)
+	}
+	if p.BundlePrice.Currency != money.DefaultCurrency {
+		return httpErrors.NewApiError(http.StatusBadRequest, fmt.Sprintf("Bundle price should be given in the base currency %s", money.DefaultCurrency), nil)
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return httpErrors.NewApiError(http.StatusBadRequest, "Promotion should end after it starts", nil)
+	}
+	return nil
+}
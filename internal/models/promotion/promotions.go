@@ -0,0 +1,37 @@
+package promotion
+
+import (
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"go.uber.org/zap"
+)
+
+// Promotions applies the active promotions to the carts
+type Promotions struct {
+	repo *PromotionRepository
+	now  func() time.Time
+}
+
+func NewPromotions(repo *PromotionRepository) *Promotions {
+	return &Promotions{repo: repo,
+		now: time.Now}
+}
+
+// Apply returns the discounts of the active promotions for the items of a cart
+// note that the promotions are read on every call, so a promotion applies to the carts as soon as it is saved
+func (ps *Promotions) Apply(items []models.Item) ([]Applied, error) {
+	if ps == nil || len(items) == 0 {
+		return nil, nil
+	}
+	active, err := ps.repo.getActive(ps.now())
+	if err != nil {
+		return nil, err
+	}
+	applied, err := Evaluate(active, items)
+	if err != nil {
+		return nil, err
+	}
+	zap.L().Debug("promotion.Apply", zap.Int("active", len(active)), zap.Int("applied", len(applied)))
+	return applied, nil
+}
@@ -0,0 +1,90 @@
+package promotion
+
+import (
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type PromotionRepository struct {
+	db *gorm.DB
+}
+
+func (pr *PromotionRepository) Migration() {
+	pr.db.AutoMigrate(&models.Promotion{})
+}
+
+func NewPromotionRepository(db *gorm.DB) *PromotionRepository {
+	return &PromotionRepository{db: db}
+}
+
+// create creates a promotion
+func (pr *PromotionRepository) create(p *models.Promotion) (*models.Promotion, error) {
+	if err := pr.db.Create(p).Error; err != nil {
+		zap.L().Error("promotion.repo.create failed to create promotion", zap.Error(err))
+		return nil, err
+	}
+	return p, nil
+}
+
+// getAll fetches the promotions with pagination parameters in the order that they apply
+func (pr *PromotionRepository) getAll(pageIndex, pageSize int) (*[]models.Promotion, int, error) {
+	var promotions *[]models.Promotion
+	var count int64
+
+	query := pr.db.Model(&models.Promotion{}).Session(&gorm.Session{})
+	if err := query.Count(&count).Error; err != nil {
+		zap.L().Error("promotion.repo.getAll failed to count promotions", zap.Error(err))
+		return nil, -1, err
+	}
+	if err := query.Order("priority desc, created_at").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&promotions).Error; err != nil {
+		zap.L().Error("promotion.repo.getAll failed to get promotions", zap.Error(err))
+		return nil, -1, err
+	}
+	return promotions, int(count), nil
+}
+
+// getActive fetches the promotions that are enabled and active at the given time in the order that they apply
+func (pr *PromotionRepository) getActive(now time.Time) ([]models.Promotion, error) {
+	var promotions []models.Promotion
+	err := pr.db.Where("disabled = ?", false).
+		Where("starts_at IS NULL OR starts_at <= ?", now).
+		Where("ends_at IS NULL OR ends_at > ?", now).
+		Order("priority desc, created_at").Find(&promotions).Error
+	if err != nil {
+		zap.L().Error("promotion.repo.getActive failed to get promotions", zap.Error(err))
+		return nil, err
+	}
+	return promotions, nil
+}
+
+// update updates a promotion by its ID
+func (pr *PromotionRepository) update(p *models.Promotion) (*models.Promotion, error) {
+	var existing *models.Promotion
+	if err := pr.db.Where("id = ?", p.ID).First(&existing).Error; err != nil {
+		zap.L().Error("promotion.repo.update failed to get promotion", zap.Error(err))
+		return nil, err
+	}
+	p.CreatedAt = existing.CreatedAt
+	if err := pr.db.Model(existing).Select("*").Omit("id", "created_at", "deleted_at").Updates(p).Error; err != nil {
+		zap.L().Error("promotion.repo.update failed to update promotion", zap.Error(err))
+		return nil, err
+	}
+	return p, nil
+}
+
+// delete deletes a promotion by its ID
+func (pr *PromotionRepository) delete(id uuid.UUID) error {
+	result := pr.db.Where("id = ?", id).Delete(&models.Promotion{})
+	if result.Error != nil {
+		zap.L().Error("promotion.repo.delete failed to delete promotion", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
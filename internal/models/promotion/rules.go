@@ -0,0 +1,221 @@
+package promotion
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/tax"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/google/uuid"
+)
+
+// kinds of promotions
+const (
+	KindPercentage = "percentage"
+	KindBuyXGetY   = "buy_x_get_y"
+	KindNthItem    = "nth_item"
+	KindBundle     = "bundle"
+)
+
+// Applied is what a promotion takes off the items of a cart
+type Applied struct {
+	Promotion *models.Promotion
+	Lines     map[uuid.UUID]money.Money
+	Total     money.Money
+}
+
+// Description describes the discount of a promotion for the customers, such as "Buy 2 get 1 free"
+func Description(p *models.Promotion) string {
+	switch p.Kind {
+	case KindPercentage:
+		return fmt.Sprintf("%s%% off", tax.FormatRate(p.Percent))
+	case KindBuyXGetY:
+		return fmt.Sprintf("Buy %d get %d free", p.BuyQuantity, p.GetQuantity)
+	case KindNthItem:
+		return fmt.Sprintf("%s%% off every %s item", tax.FormatRate(p.Percent), ordinal(p.Nth))
+	case KindBundle:
+		return fmt.Sprintf("%s for %s", strings.Join(p.SKUs, " + "), p.BundlePrice)
+	}
+	return p.Kind
+}
+
+// OrderLine returns the line of an applied promotion to show on a cart or to keep on an order
+func (a *Applied) OrderLine(amount money.Money) models.OrderDiscount {
+	return models.OrderDiscount{Description: fmt.Sprintf("%s: %s", a.Promotion.Name, Description(a.Promotion)), Amount: amount}
+}
+
+// Evaluate calculates the discounts of the promotions for the items of a cart
+// note that the promotions apply in the order of their priority, each to the prices that are left after the ones before it,
+// an exclusive promotion only applies to the items that have no promotion yet, and no other promotion applies to the items it discounts
+func Evaluate(ps []models.Promotion, items []models.Item) ([]Applied, error) {
+	ordered := make([]*models.Promotion, 0, len(ps))
+	for i := range ps {
+		ordered = append(ordered, &ps[i])
+	}
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Priority > ordered[j].Priority })
+
+	left := make([]int64, len(items))
+	for i := range items {
+		left[i] = items[i].TotalPrice.Amount
+	}
+	discounted := make([]bool, len(items))
+	locked := make([]bool, len(items))
+
+	applied := make([]Applied, 0)
+	for _, p := range ordered {
+		eligible := make([]int, 0, len(items))
+		for i := range items {
+			if locked[i] || (p.Exclusive && discounted[i]) || !inScope(p, &items[i]) {
+				continue
+			}
+			eligible = append(eligible, i)
+		}
+
+		a := Applied{Promotion: p, Lines: map[uuid.UUID]money.Money{}}
+		for i, amount := range discountsOf(p, items, eligible, left) {
+			if amount > left[i] {
+				amount = left[i]
+			}
+			if amount <= 0 {
+				continue
+			}
+			left[i] -= amount
+			discounted[i] = true
+			locked[i] = locked[i] || p.Exclusive
+			line := money.New(amount, items[i].TotalPrice.Currency)
+			a.Lines[items[i].ProductID] = line
+			var err error
+			if a.Total, err = a.Total.Add(line); err != nil {
+				return nil, err
+			}
+		}
+		if len(a.Lines) > 0 {
+			applied = append(applied, a)
+		}
+	}
+	return applied, nil
+}
+
+// discountsOf calculates the discount of a promotion for every eligible item by its index
+// note that left is the price of every item that is left after the promotions before
+func discountsOf(p *models.Promotion, items []models.Item, eligible []int, left []int64) map[int]int64 {
+	amounts := make(map[int]int64, len(eligible))
+	switch p.Kind {
+	case KindPercentage:
+		for _, i := range eligible {
+			amounts[i] = money.New(left[i], "").Percent(p.Percent).Amount
+		}
+	case KindBuyXGetY:
+		group := p.BuyQuantity + p.GetQuantity
+		if p.GetQuantity == 0 || group == 0 {
+			break
+		}
+		for _, i := range eligible {
+			free := items[i].Quantity / group * p.GetQuantity
+			amounts[i] = unitPrice(&items[i]) * int64(free)
+		}
+	case KindNthItem:
+		if p.Nth == 0 {
+			break
+		}
+		for _, i := range eligible {
+			units := items[i].Quantity / p.Nth
+			amounts[i] = money.New(unitPrice(&items[i])*int64(units), "").Percent(p.Percent).Amount
+		}
+	case KindBundle:
+		bundleDiscounts(p, items, eligible, amounts)
+	}
+	return amounts
+}
+
+// bundleDiscounts calculates the discount of a bundle promotion, which sells one of each of its skus for the bundle price
+// note that the number of bundles is limited by the sku with the lowest quantity, and the discount is split over the items in proportion to their unit prices
+func bundleDiscounts(p *models.Promotion, items []models.Item, eligible []int, amounts map[int]int64) {
+	if len(p.SKUs) == 0 {
+		return
+	}
+	members := make([]int, 0, len(p.SKUs))
+	for _, sku := range p.SKUs {
+		found := -1
+		for _, i := range eligible {
+			if strings.EqualFold(sku, items[i].Product.Stock.SKU) {
+				found = i
+				break
+			}
+		}
+		if found < 0 {
+			return
+		}
+		members = append(members, found)
+	}
+
+	bundles := items[members[0]].Quantity
+	var regular int64
+	for _, i := range members {
+		if items[i].Quantity < bundles {
+			bundles = items[i].Quantity
+		}
+		regular += unitPrice(&items[i])
+	}
+	saving := regular - p.BundlePrice.Amount
+	if bundles == 0 || saving <= 0 || regular == 0 {
+		return
+	}
+
+	total := saving * int64(bundles)
+	remaining := total
+	for n, i := range members {
+		share := remaining
+		if n < len(members)-1 {
+			share = total * unitPrice(&items[i]) / regular
+		}
+		amounts[i] = share
+		remaining -= share
+	}
+}
+
+// inScope checks if a promotion applies to an item by the category and the sku of its product
+// note that the skus of a bundle are its members, so they are matched when the bundle is priced
+func inScope(p *models.Promotion, i *models.Item) bool {
+	if p.Kind == KindBundle || (len(p.Categories) == 0 && len(p.SKUs) == 0) {
+		return true
+	}
+	for _, sku := range p.SKUs {
+		if strings.EqualFold(sku, i.Product.Stock.SKU) {
+			return true
+		}
+	}
+	if i.Product.CategoryName != nil {
+		for _, category := range p.Categories {
+			if strings.EqualFold(category, *i.Product.CategoryName) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// unitPrice returns the price of a single unit of an item
+func unitPrice(i *models.Item) int64 {
+	if i.Quantity == 0 {
+		return 0
+	}
+	return i.TotalPrice.Amount / int64(i.Quantity)
+}
+
+// ordinal formats a number as an ordinal such as "2nd"
+func ordinal(n uint) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
@@ -0,0 +1,154 @@
+package promotion
+
+import (
+	"testing"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testItem(sku, category string, quantity uint, unit int64) models.Item {
+	return models.Item{
+		ProductID:  uuid.New(),
+		Product:    models.Product{CategoryName: &category, Stock: models.Stock{SKU: sku}},
+		Quantity:   quantity,
+		TotalPrice: money.New(unit*int64(quantity), "USD"),
+	}
+}
+
+func TestEvaluate_Percentage(t *testing.T) {
+	items := []models.Item{testItem("BOOK-1", "Books", 1, 1999), testItem("TOY-1", "Toys", 1, 1000)}
+	p := models.Promotion{Name: "Book week", Kind: KindPercentage, Percent: 1500, Categories: []string{"books"}}
+
+	applied, err := Evaluate([]models.Promotion{p}, items)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, money.New(300, "USD"), applied[0].Lines[items[0].ProductID])
+	assert.NotContains(t, applied[0].Lines, items[1].ProductID)
+	assert.Equal(t, "Book week: 15% off", applied[0].OrderLine(applied[0].Total).Description)
+}
+
+func TestEvaluate_BuyXGetY(t *testing.T) {
+	items := []models.Item{testItem("SKU-1", "Toys", 7, 500)}
+	p := models.Promotion{Name: "3 for 2", Kind: KindBuyXGetY, BuyQuantity: 2, GetQuantity: 1}
+
+	applied, err := Evaluate([]models.Promotion{p}, items)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	// 7 units make 2 groups of 3, so 2 units are free
+	assert.Equal(t, money.New(1000, "USD"), applied[0].Total)
+	assert.Equal(t, "Buy 2 get 1 free", Description(&p))
+}
+
+func TestEvaluate_NthItem(t *testing.T) {
+	items := []models.Item{testItem("SKU-1", "Toys", 5, 1000)}
+	p := models.Promotion{Name: "Second half", Kind: KindNthItem, Nth: 2, Percent: 5000}
+
+	applied, err := Evaluate([]models.Promotion{p}, items)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, money.New(1000, "USD"), applied[0].Total)
+	assert.Equal(t, "50% off every 2nd item", Description(&p))
+}
+
+func TestEvaluate_Bundle(t *testing.T) {
+	items := []models.Item{testItem("PHONE", "Phones", 2, 3000), testItem("CASE", "Cases", 1, 1000), testItem("TOY-1", "Toys", 1, 1000)}
+	p := models.Promotion{Name: "Phone kit", Kind: KindBundle, SKUs: []string{"phone", "case"}, BundlePrice: money.New(3500, "USD")}
+
+	applied, err := Evaluate([]models.Promotion{p}, items)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	// a single bundle saves 5.00, split 3:1 by the unit prices
+	assert.Equal(t, money.New(375, "USD"), applied[0].Lines[items[0].ProductID])
+	assert.Equal(t, money.New(125, "USD"), applied[0].Lines[items[1].ProductID])
+	assert.NotContains(t, applied[0].Lines, items[2].ProductID)
+	assert.Equal(t, money.New(500, "USD"), applied[0].Total)
+}
+
+func TestEvaluate_BundleMissingMember(t *testing.T) {
+	items := []models.Item{testItem("PHONE", "Phones", 1, 3000)}
+	p := models.Promotion{Name: "Phone kit", Kind: KindBundle, SKUs: []string{"PHONE", "CASE"}, BundlePrice: money.New(3500, "USD")}
+
+	applied, err := Evaluate([]models.Promotion{p}, items)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+}
+
+func TestEvaluate_Stacking(t *testing.T) {
+	items := []models.Item{testItem("SKU-1", "Toys", 1, 1000)}
+	ps := []models.Promotion{
+		{Name: "Toys", Kind: KindPercentage, Percent: 1000, Priority: 1},
+		{Name: "Sale", Kind: KindPercentage, Percent: 5000, Priority: 2},
+	}
+
+	applied, err := Evaluate(ps, items)
+	require.NoError(t, err)
+	require.Len(t, applied, 2)
+	// the promotion with the higher priority applies first, the next one applies to what is left
+	assert.Equal(t, "Sale", applied[0].Promotion.Name)
+	assert.Equal(t, money.New(500, "USD"), applied[0].Total)
+	assert.Equal(t, money.New(50, "USD"), applied[1].Total)
+}
+
+func TestEvaluate_Exclusive(t *testing.T) {
+	items := []models.Item{testItem("SKU-1", "Toys", 1, 1000), testItem("SKU-2", "Toys", 1, 1000)}
+	ps := []models.Promotion{
+		{Name: "Clearance", Kind: KindPercentage, Percent: 3000, Priority: 3, Exclusive: true, SKUs: []string{"SKU-1"}},
+		{Name: "Toys", Kind: KindPercentage, Percent: 1000, Priority: 2},
+		{Name: "Flash", Kind: KindPercentage, Percent: 2000, Priority: 1, Exclusive: true},
+	}
+
+	applied, err := Evaluate(ps, items)
+	require.NoError(t, err)
+	require.Len(t, applied, 2)
+	assert.Equal(t, money.New(300, "USD"), applied[0].Lines[items[0].ProductID])
+	// the exclusive promotion locks its line, and the later exclusive one finds every line discounted
+	assert.NotContains(t, applied[1].Lines, items[0].ProductID)
+	assert.Equal(t, money.New(100, "USD"), applied[1].Lines[items[1].ProductID])
+}
+
+func TestEvaluate_CappedAtPrice(t *testing.T) {
+	items := []models.Item{testItem("SKU-1", "Toys", 2, 1000)}
+	ps := []models.Promotion{
+		{Name: "Free", Kind: KindPercentage, Percent: 10000, Priority: 2},
+		{Name: "1+1", Kind: KindBuyXGetY, BuyQuantity: 1, GetQuantity: 1, Priority: 1},
+	}
+
+	applied, err := Evaluate(ps, items)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, money.New(2000, "USD"), applied[0].Total)
+}
+
+func TestOrdinal(t *testing.T) {
+	for n, want := range map[uint]string{1: "1st", 2: "2nd", 3: "3rd", 4: "4th", 11: "11th", 12: "12th", 13: "13th", 22: "22nd"} {
+		assert.Equal(t, want, ordinal(n))
+	}
+}
+
+func TestValidatePromotion(t *testing.T) {
+	valid := []models.Promotion{
+		{Name: "Sale", Kind: KindPercentage, Percent: 1000, BundlePrice: money.New(0, "")},
+		{Name: "3 for 2", Kind: KindBuyXGetY, BuyQuantity: 2, GetQuantity: 1, BundlePrice: money.New(0, "")},
+		{Name: "Kit", Kind: KindBundle, SKUs: []string{"A", "B"}, BundlePrice: money.New(1000, "")},
+	}
+	for i := range valid {
+		assert.NoError(t, validatePromotion(&valid[i]), valid[i].Name)
+	}
+
+	invalid := []models.Promotion{
+		{Kind: KindPercentage, Percent: 1000},
+		{Name: "Sale", Kind: "unknown"},
+		{Name: "Sale", Kind: KindPercentage, Percent: 10001},
+		{Name: "Free", Kind: KindBuyXGetY, BuyQuantity: 2},
+		{Name: "Every", Kind: KindNthItem, Nth: 1, Percent: 5000},
+		{Name: "Kit", Kind: KindBundle, SKUs: []string{"A"}, BundlePrice: money.New(1000, "")},
+		{Name: "Kit", Kind: KindBundle, SKUs: []string{"A", "B"}, BundlePrice: money.New(1000, "EUR")},
+	}
+	for i := range invalid {
+		assert.Error(t, validatePromotion(&invalid[i]), invalid[i].Name)
+	}
+}
@@ -0,0 +1,76 @@
+package promotion
+
+import (
+	"math"
+	"strings"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/api"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/response"
+	"go.uber.org/zap"
+)
+
+// responseToPromotion converts promotion response model to database model
+// note that the percentage is converted to basis points, so 15 is kept as 1500
+func responseToPromotion(ap *api.Promotion) *models.Promotion {
+	zap.L().Debug("promotion.serializer.responseToPromotion", zap.Reflect("name", ap.Name), zap.Reflect("kind", ap.Kind))
+
+	return &models.Promotion{
+		Name:        strings.TrimSpace(*ap.Name),
+		Kind:        strings.ToLower(strings.TrimSpace(*ap.Kind)),
+		Priority:    int(ap.Priority),
+		Exclusive:   ap.Exclusive,
+		Disabled:    ap.Disabled,
+		Percent:     int64(math.Round(ap.Percent * 100)),
+		BuyQuantity: uint(ap.BuyQuantity),
+		GetQuantity: uint(ap.GetQuantity),
+		Nth:         uint(ap.Nth),
+		BundlePrice: response.ResponseToMoney(ap.BundlePrice),
+		Categories:  trimAll(ap.Categories),
+		SKUs:        trimAll(ap.Skus),
+		StartsAt:    response.ResponseToTime(ap.StartsAt),
+		EndsAt:      response.ResponseToTime(ap.EndsAt),
+	}
+}
+
+// promotionToResponse converts promotion database model to response model
+func promotionToResponse(p *models.Promotion) *api.Promotion {
+	return &api.Promotion{
+		ID:          p.ID.String(),
+		Name:        &p.Name,
+		Kind:        &p.Kind,
+		Priority:    int32(p.Priority),
+		Exclusive:   p.Exclusive,
+		Disabled:    p.Disabled,
+		Percent:     float64(p.Percent) / 100,
+		BuyQuantity: uint32(p.BuyQuantity),
+		GetQuantity: uint32(p.GetQuantity),
+		Nth:         uint32(p.Nth),
+		BundlePrice: response.MoneyToResponse(p.BundlePrice),
+		Categories:  p.Categories,
+		Skus:        p.SKUs,
+		StartsAt:    response.TimeToResponse(p.StartsAt),
+		EndsAt:      response.TimeToResponse(p.EndsAt),
+	}
+}
+
+// promotionsToResponse converts promotion database model to response model as a batch
+func promotionsToResponse(ps *[]models.Promotion) []*api.Promotion {
+	promotions := make([]*api.Promotion, 0)
+	for i := range *ps {
+		psDeref := *ps
+		promotions = append(promotions, promotionToResponse(&psDeref[i]))
+	}
+	return promotions
+}
+
+// trimAll trims the spaces around the values and leaves out the empty ones
+func trimAll(values []string) []string {
+	trimmed := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			trimmed = append(trimmed, v)
+		}
+	}
+	return trimmed
+}
@@ -0,0 +1,114 @@
+package reservation
+
+import (
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReservationRepository struct {
+	db *gorm.DB
+}
+
+func (rr *ReservationRepository) Migration() {
+	rr.db.AutoMigrate(&models.Reservation{})
+}
+
+func NewReservationRepository(db *gorm.DB) *ReservationRepository {
+	return &ReservationRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in the given transaction
+func (rr *ReservationRepository) WithTx(tx *gorm.DB) *ReservationRepository {
+	return &ReservationRepository{db: tx}
+}
+
+// hold holds the given quantity of a product for a cart until the given time
+// note that the quantity and the expiry of a hold that the cart already has are replaced
+func (rr *ReservationRepository) hold(productID, cartID uuid.UUID, quantity uint, expiresAt time.Time) error {
+	r := &models.Reservation{CartID: cartID, ProductID: productID, Quantity: quantity, ExpiresAt: expiresAt}
+	err := rr.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cart_id"}, {Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"quantity", "expires_at", "updated_at"}),
+	}).Create(r).Error
+	if err != nil {
+		zap.L().Error("reservation.repo.hold failed to hold product", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// release deletes the hold of a product for a cart
+func (rr *ReservationRepository) release(productID, cartID uuid.UUID) error {
+	if err := rr.db.Where("cart_id = ? AND product_id = ?", cartID, productID).Delete(&models.Reservation{}).Error; err != nil {
+		zap.L().Error("reservation.repo.release failed to release product", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// releaseCart deletes all the holds of a cart
+func (rr *ReservationRepository) releaseCart(cartID uuid.UUID) error {
+	if err := rr.db.Where("cart_id = ?", cartID).Delete(&models.Reservation{}).Error; err != nil {
+		zap.L().Error("reservation.repo.releaseCart failed to release cart", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// extend moves the expiry of the active holds of a cart to the given time
+// note that the expired holds are not extended, since their stock may already be held by another cart
+func (rr *ReservationRepository) extend(cartID uuid.UUID, now, expiresAt time.Time) error {
+	err := rr.db.Model(&models.Reservation{}).Where("cart_id = ? AND expires_at > ?", cartID, now).
+		Updates(map[string]interface{}{"expires_at": expiresAt, "updated_at": now}).Error
+	if err != nil {
+		zap.L().Error("reservation.repo.extend failed to extend holds", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// heldByOthers returns the quantity of a product that the active holds of the other carts keep
+func (rr *ReservationRepository) heldByOthers(productID, cartID uuid.UUID, now time.Time) (uint, error) {
+	var held uint
+	err := rr.db.Model(&models.Reservation{}).Select("COALESCE(SUM(quantity), 0)").
+		Where("product_id = ? AND cart_id <> ? AND expires_at > ?", productID, cartID, now).Scan(&held).Error
+	if err != nil {
+		zap.L().Error("reservation.repo.heldByOthers failed to sum holds", zap.Error(err))
+		return 0, err
+	}
+	return held, nil
+}
+
+// held returns the quantities of the given products that the active holds keep
+func (rr *ReservationRepository) held(productIDs []uuid.UUID, now time.Time) (map[uuid.UUID]uint, error) {
+	var rows []struct {
+		ProductID uuid.UUID
+		Quantity  uint
+	}
+	err := rr.db.Model(&models.Reservation{}).Select("product_id, SUM(quantity) AS quantity").
+		Where("product_id IN ? AND expires_at > ?", productIDs, now).Group("product_id").Scan(&rows).Error
+	if err != nil {
+		zap.L().Error("reservation.repo.held failed to sum holds", zap.Error(err))
+		return nil, err
+	}
+	held := make(map[uuid.UUID]uint, len(rows))
+	for _, r := range rows {
+		held[r.ProductID] = r.Quantity
+	}
+	return held, nil
+}
+
+// deleteExpired deletes the holds that expire before the given time and returns their number
+func (rr *ReservationRepository) deleteExpired(before time.Time) (int64, error) {
+	result := rr.db.Where("expires_at <= ?", before).Delete(&models.Reservation{})
+	if result.Error != nil {
+		zap.L().Error("reservation.repo.deleteExpired failed to delete holds", zap.Error(result.Error))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,139 @@
+package reservation
+
+import (
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// defaultTTL is used when the reservations are enabled without a duration
+const defaultTTL = 15 * time.Minute
+
+// Reservations holds the stock of the products in the carts for a limited time
+// note that every method does nothing when the reservations are disabled, so the stock is only checked at checkout as before
+type Reservations struct {
+	repo    *ReservationRepository
+	enabled bool
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+func NewReservations(repo *ReservationRepository, cfg config.ReservationConfig) *Reservations {
+	r := &Reservations{repo: repo,
+		enabled: cfg.Enabled,
+		ttl:     time.Duration(cfg.TTLMins) * time.Minute,
+		now:     time.Now}
+	if r.ttl <= 0 {
+		r.ttl = defaultTTL
+	}
+	return r
+}
+
+// WithTx returns a copy of the reservations that runs its queries in the given transaction
+func (r *Reservations) WithTx(tx *gorm.DB) *Reservations {
+	if !r.Enabled() {
+		return r
+	}
+	return &Reservations{repo: r.repo.WithTx(tx),
+		enabled: r.enabled,
+		ttl:     r.ttl,
+		now:     r.now}
+}
+
+// Enabled reports whether the stock is held for the carts
+func (r *Reservations) Enabled() bool {
+	return r != nil && r.enabled
+}
+
+// Available returns the stock of a product that a cart can have
+// note that the holds of the cart itself count as available to it
+func (r *Reservations) Available(p *models.Product, cartID uuid.UUID) (uint, error) {
+	if !r.Enabled() {
+		return p.Stock.Number, nil
+	}
+	held, err := r.repo.heldByOthers(p.ID, cartID, r.now())
+	if err != nil {
+		return 0, err
+	}
+	return subtract(p.Stock.Number, held), nil
+}
+
+// Hold holds the given quantity of a product for a cart
+func (r *Reservations) Hold(productID, cartID uuid.UUID, quantity uint) error {
+	if !r.Enabled() {
+		return nil
+	}
+	return r.repo.hold(productID, cartID, quantity, r.now().Add(r.ttl))
+}
+
+// Release releases the hold of a product for a cart
+func (r *Reservations) Release(productID, cartID uuid.UUID) error {
+	if !r.Enabled() {
+		return nil
+	}
+	return r.repo.release(productID, cartID)
+}
+
+// ReleaseCart releases all the holds of a cart
+func (r *Reservations) ReleaseCart(cartID uuid.UUID) error {
+	if !r.Enabled() {
+		return nil
+	}
+	return r.repo.releaseCart(cartID)
+}
+
+// Extend keeps the active holds of a cart for another duration
+func (r *Reservations) Extend(cartID uuid.UUID) error {
+	if !r.Enabled() {
+		return nil
+	}
+	now := r.now()
+	return r.repo.extend(cartID, now, now.Add(r.ttl))
+}
+
+// SetAvailable sets the stock of the products that is not held by the carts
+// note that the available stock is left unset when the reservations are disabled
+func (r *Reservations) SetAvailable(ps ...*models.Product) error {
+	if !r.Enabled() || len(ps) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(ps))
+	for _, p := range ps {
+		ids = append(ids, p.ID)
+	}
+	held, err := r.repo.held(ids, r.now())
+	if err != nil {
+		return err
+	}
+	for _, p := range ps {
+		available := subtract(p.Stock.Number, held[p.ID])
+		p.Available = &available
+	}
+	return nil
+}
+
+// ReleaseExpired deletes the expired holds and returns their number
+// note that the expired holds are already ignored, so deleting them only keeps the table small
+func (r *Reservations) ReleaseExpired() (int64, error) {
+	if !r.Enabled() {
+		return 0, nil
+	}
+	n, err := r.repo.deleteExpired(r.now())
+	if err != nil {
+		return 0, err
+	}
+	zap.L().Debug("reservation.ReleaseExpired", zap.Int64("released", n))
+	return n, nil
+}
+
+// subtract returns the stock that is left after the held quantity
+func subtract(stock, held uint) uint {
+	if held >= stock {
+		return 0
+	}
+	return stock - held
+}
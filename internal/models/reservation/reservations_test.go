@@ -0,0 +1,108 @@
+package reservation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var now = time.Date(2022, 4, 10, 10, 0, 0, 0, time.UTC)
+
+func newTestReservations(t *testing.T, cfg config.ReservationConfig) (*Reservations, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db, PreferSimpleProtocol: true}), &gorm.Config{})
+	require.NoError(t, err)
+
+	r := NewReservations(NewReservationRepository(gdb), cfg)
+	r.now = func() time.Time { return now }
+	return r, mock
+}
+
+func TestAvailable(t *testing.T) {
+	r, mock := newTestReservations(t, config.ReservationConfig{Enabled: true, TTLMins: 15})
+	p := &models.Product{ID: uuid.New(), Stock: models.Stock{SKU: "SKU-1", Number: 10}}
+	cartID := uuid.New()
+
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(quantity\), 0\) FROM "reservations" WHERE product_id = .* AND cart_id <> .* AND expires_at >`).
+		WithArgs(p.ID, cartID, now).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(7))
+
+	available, err := r.Available(p, cartID)
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), available)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAvailable_Overheld(t *testing.T) {
+	r, mock := newTestReservations(t, config.ReservationConfig{Enabled: true, TTLMins: 15})
+	p := &models.Product{ID: uuid.New(), Stock: models.Stock{SKU: "SKU-1", Number: 2}}
+
+	// the stock can drop below the holds when an admin updates it
+	mock.ExpectQuery(`SELECT COALESCE`).WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(5))
+
+	available, err := r.Available(p, uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, uint(0), available)
+}
+
+func TestHold(t *testing.T) {
+	r, mock := newTestReservations(t, config.ReservationConfig{Enabled: true, TTLMins: 15})
+	productID, cartID := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO "reservations" .* ON CONFLICT \("cart_id","product_id"\) DO UPDATE SET`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), cartID, productID, 2, now.Add(15*time.Minute)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, r.Hold(productID, cartID, 2))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetAvailable(t *testing.T) {
+	r, mock := newTestReservations(t, config.ReservationConfig{Enabled: true})
+	p1 := &models.Product{ID: uuid.New(), Stock: models.Stock{Number: 10}}
+	p2 := &models.Product{ID: uuid.New(), Stock: models.Stock{Number: 4}}
+
+	mock.ExpectQuery(`SELECT product_id, SUM\(quantity\) AS quantity FROM "reservations" .* GROUP BY "product_id"`).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "quantity"}).AddRow(p1.ID, 6))
+
+	require.NoError(t, r.SetAvailable(p1, p2))
+	require.NotNil(t, p1.Available)
+	require.NotNil(t, p2.Available)
+	assert.Equal(t, uint(4), *p1.Available)
+	assert.Equal(t, uint(4), *p2.Available)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDisabled(t *testing.T) {
+	r, mock := newTestReservations(t, config.ReservationConfig{Enabled: false})
+	p := &models.Product{ID: uuid.New(), Stock: models.Stock{Number: 10}}
+	cartID := uuid.New()
+
+	available, err := r.Available(p, cartID)
+	require.NoError(t, err)
+	assert.Equal(t, uint(10), available)
+	require.NoError(t, r.Hold(p.ID, cartID, 3))
+	require.NoError(t, r.Release(p.ID, cartID))
+	require.NoError(t, r.Extend(cartID))
+	require.NoError(t, r.SetAvailable(p))
+	assert.Nil(t, p.Available)
+
+	// no query runs when the reservations are disabled
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNewReservations_DefaultTTL(t *testing.T) {
+	r := NewReservations(nil, config.ReservationConfig{Enabled: true})
+	assert.Equal(t, defaultTTL, r.ttl)
+}
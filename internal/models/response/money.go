@@ -0,0 +1,27 @@
+package response
+
+import (
+	"github.com/cagrikilicoglu/shopping-basket/internal/api"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+)
+
+// MoneyToResponse converts an amount to response model
+func MoneyToResponse(m money.Money) *api.Money {
+	return &api.Money{
+		Amount:   &m.Amount,
+		Currency: &m.Currency,
+	}
+}
+
+// ResponseToMoney converts an amount response model to money
+// note that the amount is expected to be validated, and a missing currency falls back to the default one
+func ResponseToMoney(am *api.Money) money.Money {
+	if am == nil || am.Amount == nil {
+		return money.New(0, "")
+	}
+	currency := ""
+	if am.Currency != nil {
+		currency = *am.Currency
+	}
+	return money.New(*am.Amount, currency)
+}
@@ -0,0 +1,25 @@
+package response
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// TimeToResponse converts an optional time to response model
+func TimeToResponse(t *time.Time) *strfmt.DateTime {
+	if t == nil {
+		return nil
+	}
+	converted := strfmt.DateTime(*t)
+	return &converted
+}
+
+// ResponseToTime converts an optional time response model to time
+func ResponseToTime(t *strfmt.DateTime) *time.Time {
+	if t == nil {
+		return nil
+	}
+	converted := time.Time(*t)
+	return &converted
+}
@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/api"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/response"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/middleware"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/pagination"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type schedulerHandler struct {
+	repo      *SchedulerRepository
+	scheduler *Scheduler
+}
+
+func NewSchedulerHandler(r *gin.RouterGroup, scheduler *Scheduler, cfg *config.Config) {
+	h := &schedulerHandler{repo: scheduler.repo,
+		scheduler: scheduler}
+
+	r.GET("/admin/jobs", middleware.AdminAuthMiddleware(cfg.JWTConfig.SecretKey), h.getJobs)
+	r.GET("/admin/jobs/runs", middleware.AdminAuthMiddleware(cfg.JWTConfig.SecretKey), h.getRuns)
+}
+
+// getJobs fetches the enabled jobs with their next run times and their last runs
+func (sh *schedulerHandler) getJobs(c *gin.Context) {
+	zap.L().Debug("scheduler.handler.getJobs")
+
+	now := time.Now()
+	jobs := make([]*api.ScheduledJob, 0, len(sh.scheduler.jobs))
+	for _, j := range sh.scheduler.sortedJobs() {
+		last, err := sh.repo.getLastRun(j.name)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			response.RespondWithError(c, err)
+			return
+		}
+		jobs = append(jobs, jobToResponse(j, j.schedule.Next(now), last))
+	}
+	response.RespondWithJson(c, http.StatusOK, jobs)
+}
+
+// getRuns fetches the run history of the jobs, or of the job given in the query, with pagination parameters
+func (sh *schedulerHandler) getRuns(c *gin.Context) {
+	job := c.Query("job")
+	pageIndex, pageSize := pagination.GetPaginationParametersFromRequest(c)
+	zap.L().Debug("scheduler.handler.getRuns", zap.Reflect("job", job), zap.Reflect("pageIndex", pageIndex), zap.Reflect("pageSize", pageSize))
+
+	runs, count, err := sh.repo.getRuns(job, pageIndex, pagination.ClampPageSize(pageSize))
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	response.RespondWithJson(c, http.StatusOK, pagination.NewFromGinRequest(c, count, runsToResponse(runs)))
+}
@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SchedulerRepository struct {
+	db *gorm.DB
+}
+
+func (sr *SchedulerRepository) Migration() {
+	sr.db.AutoMigrate(&models.JobRun{})
+}
+
+func NewSchedulerRepository(db *gorm.DB) *SchedulerRepository {
+	return &SchedulerRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in the given transaction
+func (sr *SchedulerRepository) WithTx(tx *gorm.DB) *SchedulerRepository {
+	return &SchedulerRepository{db: tx}
+}
+
+// tryLock takes the advisory lock of a job for the surrounding transaction and reports whether it is taken
+// note that it does not wait for the lock, so the instance that finds the job locked skips it while another instance runs it
+func (sr *SchedulerRepository) tryLock(job string) (bool, error) {
+	var locked bool
+	if err := sr.db.Raw("SELECT pg_try_advisory_xact_lock(hashtext(?))", "scheduler."+job).Scan(&locked).Error; err != nil {
+		zap.L().Error("scheduler.repo.tryLock failed to take the lock", zap.Error(err))
+		return false, err
+	}
+	return locked, nil
+}
+
+// claim records the start of a run and reports whether the run is recorded
+// note that a run that is already recorded for the same job and scheduled time is not recorded again, so it is not run twice
+func (sr *SchedulerRepository) claim(r *models.JobRun) (bool, error) {
+	result := sr.db.Clauses(clause.OnConflict{DoNothing: true}).Create(r)
+	if result.Error != nil {
+		zap.L().Error("scheduler.repo.claim failed to record run", zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// finish records the result of a run
+func (sr *SchedulerRepository) finish(r *models.JobRun) error {
+	finishedAt := time.Now()
+	r.FinishedAt = &finishedAt
+	if err := sr.db.Model(r).Select("finished_at", "succeeded", "result", "error").Updates(r).Error; err != nil {
+		zap.L().Error("scheduler.repo.finish failed to record result", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// getRuns fetches the runs of all jobs or of the given job with pagination parameters, latest first
+func (sr *SchedulerRepository) getRuns(job string, pageIndex, pageSize int) (*[]models.JobRun, int, error) {
+	var runs *[]models.JobRun
+	var count int64
+
+	query := sr.db.Model(&models.JobRun{})
+	if job != "" {
+		query = query.Where("job = ?", job)
+	}
+	query = query.Session(&gorm.Session{})
+	if err := query.Count(&count).Error; err != nil {
+		zap.L().Error("scheduler.repo.getRuns failed to count runs", zap.Error(err))
+		return nil, -1, err
+	}
+	if err := query.Order("scheduled_at desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&runs).Error; err != nil {
+		zap.L().Error("scheduler.repo.getRuns failed to get runs", zap.Error(err))
+		return nil, -1, err
+	}
+	return runs, int(count), nil
+}
+
+// getLastRun fetches the latest run of a job
+func (sr *SchedulerRepository) getLastRun(job string) (*models.JobRun, error) {
+	var run *models.JobRun
+	if err := sr.db.Where("job = ?", job).Order("scheduled_at desc").First(&run).Error; err != nil {
+		return nil, err
+	}
+	return run, nil
+}
@@ -0,0 +1,182 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/cron"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// JobFunc runs a scheduled job and returns a summary of what it did
+// note that the context is canceled when the scheduler stops, so long jobs should check it between their steps
+type JobFunc func(ctx context.Context) (string, error)
+
+type job struct {
+	name     string
+	schedule *cron.Schedule
+	run      JobFunc
+	next     time.Time
+}
+
+// Scheduler runs the registered jobs by their cron schedules in the background
+// note that every instance of the service runs a scheduler, and a job runs on one instance only for each scheduled time
+type Scheduler struct {
+	repo      *SchedulerRepository
+	schedules map[string]string
+	jobs      []*job
+	instance  string
+	ctx       context.Context
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+func NewScheduler(repo *SchedulerRepository, cfg config.SchedulerConfig) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{repo: repo,
+		schedules: make(map[string]string, len(cfg.Jobs)),
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{})}
+	for _, j := range cfg.Jobs {
+		s.schedules[j.Name] = j.Schedule
+	}
+	s.instance, _ = os.Hostname()
+	return s
+}
+
+// Register adds a job that runs by the schedule configured for its name
+// note that a job without a schedule in the configuration is disabled, and jobs should be registered before the scheduler is started
+func (s *Scheduler) Register(name string, run JobFunc) error {
+	expr, ok := s.schedules[name]
+	if !ok || expr == "" {
+		zap.L().Info("scheduler.Register job is disabled", zap.String("job", name))
+		return nil
+	}
+	schedule, err := cron.Parse(expr)
+	if err != nil {
+		return fmt.Errorf("schedule of job %s cannot be parsed, %v", name, err)
+	}
+	s.jobs = append(s.jobs, &job{name: name, schedule: schedule, run: run})
+	return nil
+}
+
+// Start starts running the jobs in the background until the scheduler is stopped
+func (s *Scheduler) Start() {
+	now := time.Now()
+	for _, j := range s.jobs {
+		j.next = j.schedule.Next(now)
+	}
+	go s.loop()
+}
+
+// Stop cancels the running job and waits for the scheduler to record its result
+// note that waiting is abandoned when the given context is done
+func (s *Scheduler) Stop(ctx context.Context) {
+	s.cancel()
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		zap.L().Error("scheduler.Stop could not stop the running job in time", zap.Error(ctx.Err()))
+	}
+}
+
+func (s *Scheduler) loop() {
+	defer close(s.done)
+
+	for {
+		j := s.nextJob()
+		if j == nil {
+			<-s.ctx.Done()
+			return
+		}
+
+		timer := time.NewTimer(time.Until(j.next))
+		select {
+		case <-timer.C:
+			s.run(j, j.next)
+			j.next = j.schedule.Next(time.Now())
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// nextJob returns the job that is due first, or nil when no job will run again
+func (s *Scheduler) nextJob() *job {
+	var due []*job
+	for _, j := range s.jobs {
+		if !j.next.IsZero() {
+			due = append(due, j)
+		}
+	}
+	if len(due) == 0 {
+		return nil
+	}
+	sort.SliceStable(due, func(a, b int) bool { return due[a].next.Before(due[b].next) })
+	return due[0]
+}
+
+// run runs a job for its scheduled time unless another instance runs it or has already run it
+// note that the advisory lock of the job is held by a transaction until the run is recorded, and the job runs its own queries outside of it
+func (s *Scheduler) run(j *job, scheduledAt time.Time) {
+	err := s.repo.db.Transaction(func(tx *gorm.DB) error {
+		repo := s.repo.WithTx(tx)
+
+		locked, err := repo.tryLock(j.name)
+		if err != nil || !locked {
+			zap.L().Debug("scheduler.run job is running on another instance", zap.String("job", j.name))
+			return err
+		}
+
+		r := &models.JobRun{Job: j.name, ScheduledAt: scheduledAt, Instance: s.instance}
+		claimed, err := repo.claim(r)
+		if err != nil || !claimed {
+			zap.L().Debug("scheduler.run job has already run", zap.String("job", j.name), zap.Time("scheduledAt", scheduledAt))
+			return err
+		}
+
+		r.Result, r.Succeeded = s.execute(j)
+		if !r.Succeeded {
+			r.Error, r.Result = r.Result, ""
+		}
+		return repo.finish(r)
+	})
+	if err != nil {
+		zap.L().Error("scheduler.run failed to run job", zap.String("job", j.name), zap.Error(err))
+	}
+}
+
+// execute runs a job and returns its summary, or its error when it fails
+// note that a panicking job is reported as failed instead of stopping the scheduler
+func (s *Scheduler) execute(j *job) (result string, succeeded bool) {
+	defer func() {
+		if p := recover(); p != nil {
+			zap.L().Error("scheduler.execute job panicked", zap.String("job", j.name), zap.Reflect("panic", p))
+			result, succeeded = fmt.Sprintf("job panicked: %v", p), false
+		}
+	}()
+
+	zap.L().Info("scheduler.execute job started", zap.String("job", j.name))
+	summary, err := j.run(s.ctx)
+	if err != nil {
+		zap.L().Error("scheduler.execute job failed", zap.String("job", j.name), zap.Error(err))
+		return err.Error(), false
+	}
+	zap.L().Info("scheduler.execute job finished", zap.String("job", j.name), zap.String("result", summary))
+	return summary, true
+}
+
+// sortedJobs returns the registered jobs sorted by name
+func (s *Scheduler) sortedJobs() []*job {
+	jobs := append([]*job(nil), s.jobs...)
+	sort.Slice(jobs, func(a, b int) bool { return jobs[a].name < jobs[b].name })
+	return jobs
+}
@@ -0,0 +1,115 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func newTestScheduler(t *testing.T, run JobFunc) (*Scheduler, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db, PreferSimpleProtocol: true}), &gorm.Config{})
+	require.NoError(t, err)
+
+	s := NewScheduler(NewSchedulerRepository(gdb), config.SchedulerConfig{Jobs: []config.ScheduledJob{{Name: "test", Schedule: "@hourly"}}})
+	require.NoError(t, s.Register("test", run))
+	return s, mock
+}
+
+func TestRun(t *testing.T) {
+	runs := 0
+	s, mock := newTestScheduler(t, func(ctx context.Context) (string, error) {
+		runs++
+		return "done", nil
+	})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT pg_try_advisory_xact_lock`).WithArgs("scheduler.test").
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(true))
+	mock.ExpectExec(`INSERT INTO "job_runs" .* ON CONFLICT DO NOTHING`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "job_runs" SET`).WithArgs(sqlmock.AnyArg(), true, "done", "", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	s.run(s.jobs[0], time.Now())
+
+	assert.Equal(t, 1, runs)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRun_Failed(t *testing.T) {
+	s, mock := newTestScheduler(t, func(ctx context.Context) (string, error) {
+		return "", errors.New("failed")
+	})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT pg_try_advisory_xact_lock`).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(true))
+	mock.ExpectExec(`INSERT INTO "job_runs"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "job_runs" SET`).WithArgs(sqlmock.AnyArg(), false, "", "failed", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	s.run(s.jobs[0], time.Now())
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRun_Locked(t *testing.T) {
+	s, mock := newTestScheduler(t, func(ctx context.Context) (string, error) {
+		t.Fatal("job should not run while another instance runs it")
+		return "", nil
+	})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT pg_try_advisory_xact_lock`).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(false))
+	mock.ExpectCommit()
+
+	s.run(s.jobs[0], time.Now())
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRun_AlreadyRun(t *testing.T) {
+	s, mock := newTestScheduler(t, func(ctx context.Context) (string, error) {
+		t.Fatal("job should not run twice for the same scheduled time")
+		return "", nil
+	})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT pg_try_advisory_xact_lock`).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(true))
+	mock.ExpectExec(`INSERT INTO "job_runs"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	s.run(s.jobs[0], time.Now())
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecute_Panic(t *testing.T) {
+	s, _ := newTestScheduler(t, func(ctx context.Context) (string, error) {
+		panic("broken")
+	})
+
+	result, succeeded := s.execute(s.jobs[0])
+
+	assert.False(t, succeeded)
+	assert.Equal(t, "job panicked: broken", result)
+}
+
+func TestRegister(t *testing.T) {
+	s := NewScheduler(nil, config.SchedulerConfig{Jobs: []config.ScheduledJob{{Name: "broken", Schedule: "every minute"}}})
+
+	require.NoError(t, s.Register("disabled", func(ctx context.Context) (string, error) { return "", nil }))
+	require.Error(t, s.Register("broken", func(ctx context.Context) (string, error) { return "", nil }))
+	assert.Empty(t, s.jobs)
+}
@@ -0,0 +1,48 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/api"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/go-openapi/strfmt"
+)
+
+// jobToResponse converts a scheduled job with its next run time and its last run to response model
+func jobToResponse(j *job, next time.Time, last *models.JobRun) *api.ScheduledJob {
+	response := &api.ScheduledJob{
+		Name:      j.name,
+		Schedule:  j.schedule.String(),
+		NextRunAt: strfmt.DateTime(next),
+	}
+	if last != nil {
+		response.LastRun = runToResponse(last)
+	}
+	return response
+}
+
+// runToResponse converts job run database model to response model
+func runToResponse(r *models.JobRun) *api.JobRun {
+	response := &api.JobRun{
+		ID:          r.ID.String(),
+		Job:         r.Job,
+		ScheduledAt: strfmt.DateTime(r.ScheduledAt),
+		Instance:    r.Instance,
+		Succeeded:   r.Succeeded,
+		Result:      r.Result,
+		Error:       r.Error,
+	}
+	if r.FinishedAt != nil {
+		response.FinishedAt = strfmt.DateTime(*r.FinishedAt)
+	}
+	return response
+}
+
+// runsToResponse converts job run database model to response model as a batch
+func runsToResponse(rs *[]models.JobRun) []*api.JobRun {
+	runs := make([]*api.JobRun, 0, len(*rs))
+	for i := range *rs {
+		runs = append(runs, runToResponse(&(*rs)[i]))
+	}
+	return runs
+}
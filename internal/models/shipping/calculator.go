@@ -0,0 +1,132 @@
+package shipping
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/httpErrors"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"go.uber.org/zap"
+)
+
+const (
+	gramsPerKg               = 1000
+	defaultVolumetricDivisor = 5000
+)
+
+type Calculator struct {
+	zones             []config.ShippingZone
+	fallback          *config.ShippingZone
+	freeAbove         float64
+	volumetricDivisor uint
+}
+
+// NewCalculator creates a shipping calculator from the zones and rules in the configuration
+// note that a zone without zip prefixes is the fallback for the zip codes that no other zone matches
+func NewCalculator(cfg config.ShippingConfig) (*Calculator, error) {
+	c := &Calculator{
+		freeAbove:         cfg.FreeAbove,
+		volumetricDivisor: defaultVolumetricDivisor,
+	}
+	if cfg.VolumetricDivisor > 0 {
+		c.volumetricDivisor = uint(cfg.VolumetricDivisor)
+	}
+
+	for i := range cfg.Zones {
+		zone := cfg.Zones[i]
+		if zone.Name == "" {
+			return nil, errors.New("Shipping zones should have a name")
+		}
+		if zone.BaseFee < 0 || zone.PerKgFee < 0 {
+			return nil, fmt.Errorf("Fees of shipping zone %s cannot be negative", zone.Name)
+		}
+		if len(zone.ZipPrefixes) == 0 {
+			if c.fallback != nil {
+				return nil, errors.New("Only one shipping zone can be without zip prefixes")
+			}
+			c.fallback = &zone
+			continue
+		}
+		c.zones = append(c.zones, zone)
+	}
+	return c, nil
+}
+
+// Quote prices the delivery of the items to the given zip code
+// the cost is the base fee of the zone plus its fee for every started kilogram of the chargeable weight,
+// and it is free when the subtotal of the items reaches the free shipping threshold
+func (c *Calculator) Quote(items []models.Item, subtotal money.Money, zipCode string) (models.ShippingQuote, error) {
+	zap.L().Debug("shipping.calculator.Quote", zap.Reflect("zipCode", zipCode), zap.Reflect("subtotal", subtotal))
+
+	zone, err := c.zoneOf(zipCode)
+	if err != nil {
+		return models.ShippingQuote{}, err
+	}
+
+	weight := c.chargeableWeight(items)
+	quote := models.ShippingQuote{
+		Zone:   zone.Name,
+		Weight: weight,
+		Cost:   money.New(0, money.DefaultCurrency),
+	}
+
+	if c.freeAbove > 0 {
+		belowFree, err := subtotal.LessThan(money.FromMajor(c.freeAbove, money.DefaultCurrency))
+		if err != nil {
+			return models.ShippingQuote{}, err
+		}
+		if !belowFree {
+			return quote, nil
+		}
+	}
+
+	kgs := int64((weight + gramsPerKg - 1) / gramsPerKg)
+	quote.Cost, err = money.FromMajor(zone.BaseFee, money.DefaultCurrency).Add(money.FromMajor(zone.PerKgFee, money.DefaultCurrency).Mul(kgs))
+	if err != nil {
+		return models.ShippingQuote{}, err
+	}
+	return quote, nil
+}
+
+// zoneOf finds the zone of a zip code by its longest matching prefix
+func (c *Calculator) zoneOf(zipCode string) (*config.ShippingZone, error) {
+	zipCode = strings.ToUpper(strings.ReplaceAll(zipCode, " ", ""))
+
+	var matched *config.ShippingZone
+	matchedLength := 0
+	for i := range c.zones {
+		for _, prefix := range c.zones[i].ZipPrefixes {
+			prefix = strings.ToUpper(strings.ReplaceAll(prefix, " ", ""))
+			if len(prefix) > matchedLength && strings.HasPrefix(zipCode, prefix) {
+				matched = &c.zones[i]
+				matchedLength = len(prefix)
+			}
+		}
+	}
+	if matched != nil {
+		return matched, nil
+	}
+	if c.fallback != nil {
+		return c.fallback, nil
+	}
+	return nil, httpErrors.NewApiError(http.StatusBadRequest, fmt.Sprintf("Shipping is not available to zip code %s", zipCode), nil)
+}
+
+// chargeableWeight sums the greater of the actual and the volumetric weight of the items in grams
+func (c *Calculator) chargeableWeight(items []models.Item) uint {
+	var total uint
+	for i := range items {
+		p := &items[i].Product
+		weight := p.Weight
+		volumetric := p.Dimensions.Length * p.Dimensions.Width * p.Dimensions.Height * gramsPerKg / c.volumetricDivisor
+		if volumetric > weight {
+			weight = volumetric
+		}
+		total += weight * items[i].Quantity
+	}
+	return total
+}
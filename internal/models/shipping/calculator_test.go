@@ -0,0 +1,84 @@
+package shipping
+
+import (
+	"testing"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = config.ShippingConfig{
+	FreeAbove:         150,
+	VolumetricDivisor: 5000,
+	Zones: []config.ShippingZone{
+		{Name: "local", ZipPrefixes: []string{"34"}, BaseFee: 4.99, PerKgFee: 0.5},
+		{Name: "district", ZipPrefixes: []string{"340"}, BaseFee: 2.99, PerKgFee: 0.25},
+		{Name: "domestic", BaseFee: 9.99, PerKgFee: 1.25},
+	},
+}
+
+func newTestCalculator(t *testing.T) *Calculator {
+	c, err := NewCalculator(testConfig)
+	require.NoError(t, err)
+	return c
+}
+
+func item(weight uint, dimensions models.Dimensions, quantity uint) models.Item {
+	return models.Item{Product: models.Product{Weight: weight, Dimensions: dimensions}, Quantity: quantity}
+}
+
+func TestQuote_LongestPrefixAndStartedKilograms(t *testing.T) {
+	c := newTestCalculator(t)
+	items := []models.Item{item(600, models.Dimensions{}, 2)}
+
+	quote, err := c.Quote(items, money.New(2000, "USD"), "34010")
+	require.NoError(t, err)
+	assert.Equal(t, "district", quote.Zone)
+	assert.Equal(t, uint(1200), quote.Weight)
+	// base fee plus two started kilograms
+	assert.Equal(t, money.New(349, "USD"), quote.Cost)
+
+	quote, err = c.Quote(items, money.New(2000, "USD"), "34500")
+	require.NoError(t, err)
+	assert.Equal(t, "local", quote.Zone)
+	assert.Equal(t, money.New(599, "USD"), quote.Cost)
+}
+
+func TestQuote_FallbackZoneAndVolumetricWeight(t *testing.T) {
+	c := newTestCalculator(t)
+	// a light but bulky product is charged by its volumetric weight of 2400 grams
+	items := []models.Item{item(300, models.Dimensions{Length: 40, Width: 30, Height: 10}, 1)}
+
+	quote, err := c.Quote(items, money.New(2000, "USD"), "06100")
+	require.NoError(t, err)
+	assert.Equal(t, "domestic", quote.Zone)
+	assert.Equal(t, uint(2400), quote.Weight)
+	assert.Equal(t, money.New(1374, "USD"), quote.Cost)
+}
+
+func TestQuote_FreeAboveThreshold(t *testing.T) {
+	c := newTestCalculator(t)
+
+	quote, err := c.Quote([]models.Item{item(5000, models.Dimensions{}, 1)}, money.New(15000, "USD"), "34500")
+	require.NoError(t, err)
+	assert.True(t, quote.Cost.IsZero())
+}
+
+func TestQuote_NoMatchingZone(t *testing.T) {
+	c, err := NewCalculator(config.ShippingConfig{Zones: []config.ShippingZone{{Name: "local", ZipPrefixes: []string{"34"}, BaseFee: 4.99}}})
+	require.NoError(t, err)
+
+	_, err = c.Quote(nil, money.New(2000, "USD"), "06100")
+	assert.Error(t, err)
+}
+
+func TestNewCalculator_InvalidZones(t *testing.T) {
+	_, err := NewCalculator(config.ShippingConfig{Zones: []config.ShippingZone{{Name: "a"}, {Name: "b"}}})
+	assert.Error(t, err)
+
+	_, err = NewCalculator(config.ShippingConfig{Zones: []config.ShippingZone{{Name: "a", BaseFee: -1}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,22 @@
+package shipping
+
+import (
+	"github.com/cagrikilicoglu/shopping-basket/internal/api"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/response"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+)
+
+// QuoteToResponse converts shipping quote database model to response model
+// note that it returns nil for an empty quote, such as the shipping of an order placed before shipping costs are introduced
+func QuoteToResponse(q *models.ShippingQuote, rate money.Rate) *api.ShippingQuote {
+	if q == nil || q.Zone == "" {
+		return nil
+	}
+	zone := q.Zone
+	return &api.ShippingQuote{
+		Zone:   &zone,
+		Weight: uint32(q.Weight),
+		Cost:   response.MoneyToResponse(rate.Apply(q.Cost)),
+	}
+}
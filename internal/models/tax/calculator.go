@@ -0,0 +1,201 @@
+package tax
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+)
+
+// Display modes
+const (
+	DisplayExclusive = "exclusive"
+	DisplayInclusive = "inclusive"
+)
+
+// basisPoints is the scale of tax rates, so a rate of 18% is kept as 1800
+const basisPoints = 10000
+
+type rule struct {
+	category string
+	region   string
+	rate     int64
+}
+
+type Calculator struct {
+	rules         []rule
+	defaultRate   int64
+	defaultRegion string
+	inclusive     bool
+}
+
+// NewCalculator creates a tax calculator from the rates in the configuration
+func NewCalculator(cfg config.TaxConfig) (*Calculator, error) {
+	c := &Calculator{defaultRegion: strings.ToUpper(cfg.DefaultRegion)}
+
+	switch strings.ToLower(cfg.DisplayMode) {
+	case "", DisplayExclusive:
+	case DisplayInclusive:
+		c.inclusive = true
+	default:
+		return nil, fmt.Errorf("Tax display mode should be %s or %s", DisplayExclusive, DisplayInclusive)
+	}
+
+	rate, err := toBasisPoints(cfg.DefaultRate)
+	if err != nil {
+		return nil, err
+	}
+	c.defaultRate = rate
+
+	for _, r := range cfg.Rules {
+		if r.Category == "" && r.Region == "" {
+			return nil, errors.New("Tax rules should have a category or a region")
+		}
+		rate, err := toBasisPoints(r.Rate)
+		if err != nil {
+			return nil, err
+		}
+		c.rules = append(c.rules, rule{category: r.Category, region: strings.ToUpper(r.Region), rate: rate})
+	}
+	return c, nil
+}
+
+// Inclusive checks if the prices are displayed with their tax included
+func (c *Calculator) Inclusive() bool {
+	return c != nil && c.inclusive
+}
+
+// RateFor finds the tax rate of a category in a region in basis points
+// a rule for both the category and the region wins over a rule for the category, which wins over a rule for the region
+// note that the default region is used when the region is not known
+func (c *Calculator) RateFor(category, region string) int64 {
+	if region == "" {
+		region = c.defaultRegion
+	}
+	region = strings.ToUpper(region)
+
+	rate, best := c.defaultRate, 0
+	for _, r := range c.rules {
+		score := 0
+		switch {
+		case r.category != "" && !strings.EqualFold(r.category, category):
+			continue
+		case r.region != "" && r.region != region:
+			continue
+		case r.category != "" && r.region != "":
+			score = 3
+		case r.category != "":
+			score = 2
+		default:
+			score = 1
+		}
+		if score > best {
+			rate, best = r.rate, score
+		}
+	}
+	return rate
+}
+
+// Of calculates the tax of a net amount with a rate in basis points
+// the result is rounded half away from zero to the minor unit of the currency
+func Of(net money.Money, rate int64) money.Money {
+	num := new(big.Int).Mul(big.NewInt(net.Amount), big.NewInt(rate))
+	den := big.NewInt(basisPoints)
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(den) >= 0 {
+		if num.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	return money.New(quo.Int64(), net.Currency)
+}
+
+// Display returns a net amount as it is displayed, with its tax added in the inclusive mode
+func (c *Calculator) Display(net money.Money, rate int64) money.Money {
+	if !c.Inclusive() {
+		return net
+	}
+	gross, err := net.Add(Of(net, rate))
+	if err != nil {
+		return net
+	}
+	return gross
+}
+
+// DisplayLine returns the net total of a line as it is displayed, with its calculated tax added in the inclusive mode
+func (c *Calculator) DisplayLine(net, tax money.Money) money.Money {
+	if !c.Inclusive() {
+		return net
+	}
+	gross, err := net.Add(tax)
+	if err != nil {
+		return net
+	}
+	return gross
+}
+
+// DisplayProduct returns the price of a product as it is displayed in the catalog
+// note that the catalog uses the tax rate of the default region
+func (c *Calculator) DisplayProduct(p *models.Product) money.Money {
+	if !c.Inclusive() {
+		return p.Price
+	}
+	category := ""
+	if p.CategoryName != nil {
+		category = *p.CategoryName
+	}
+	return c.Display(p.Price, c.RateFor(category, ""))
+}
+
+// Breakdown groups the tax of the items by their rates, ordered by rate
+// note that the net amounts are the prices of the items after their discounts
+func Breakdown(items []models.Item) ([]models.OrderTaxLine, money.Money, error) {
+	var total money.Money
+	byRate := map[int64]*models.OrderTaxLine{}
+	for i := range items {
+		line, ok := byRate[items[i].TaxRate]
+		if !ok {
+			line = &models.OrderTaxLine{Rate: items[i].TaxRate}
+			byRate[items[i].TaxRate] = line
+		}
+		var err error
+		if line.Net, err = line.Net.Add(items[i].Net()); err != nil {
+			return nil, money.Money{}, err
+		}
+		if line.Tax, err = line.Tax.Add(items[i].Tax); err != nil {
+			return nil, money.Money{}, err
+		}
+		if total, err = total.Add(items[i].Tax); err != nil {
+			return nil, money.Money{}, err
+		}
+	}
+
+	lines := make([]models.OrderTaxLine, 0, len(byRate))
+	for _, line := range byRate {
+		lines = append(lines, *line)
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i].Rate < lines[j].Rate })
+	return lines, total, nil
+}
+
+// FormatRate formats a rate in basis points as a percentage such as "18" or "8.5"
+func FormatRate(rate int64) string {
+	return strconv.FormatFloat(float64(rate)/100, 'f', -1, 64)
+}
+
+// toBasisPoints converts a percentage in the configuration to basis points
+func toBasisPoints(percent float64) (int64, error) {
+	if percent < 0 || percent > 100 {
+		return 0, fmt.Errorf("Tax rate %v should be between 0 and 100", percent)
+	}
+	return int64(math.Round(percent * 100)), nil
+}
@@ -0,0 +1,91 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = config.TaxConfig{
+	DefaultRegion: "TR",
+	DefaultRate:   18,
+	Rules: []config.TaxRule{
+		{Category: "Books", Rate: 8},
+		{Region: "DE", Rate: 19},
+		{Category: "Books", Region: "de", Rate: 7},
+	},
+}
+
+func TestRateFor(t *testing.T) {
+	c, err := NewCalculator(testConfig)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1800), c.RateFor("Toys", "TR"))
+	assert.Equal(t, int64(1800), c.RateFor("Toys", ""))
+	assert.Equal(t, int64(800), c.RateFor("books", "TR"))
+	assert.Equal(t, int64(1900), c.RateFor("Toys", "DE"))
+	assert.Equal(t, int64(700), c.RateFor("Books", "de"))
+}
+
+func TestOf(t *testing.T) {
+	assert.Equal(t, money.New(180, "USD"), Of(money.New(1000, "USD"), 1800))
+	// 8.5% of 9.99 is 0.84915
+	assert.Equal(t, money.New(85, "USD"), Of(money.New(999, "USD"), 850))
+	assert.Equal(t, money.New(0, "USD"), Of(money.New(1000, "USD"), 0))
+}
+
+func TestDisplay(t *testing.T) {
+	exclusive, err := NewCalculator(testConfig)
+	require.NoError(t, err)
+	assert.Equal(t, money.New(1000, "USD"), exclusive.Display(money.New(1000, "USD"), 1800))
+
+	cfg := testConfig
+	cfg.DisplayMode = DisplayInclusive
+	inclusive, err := NewCalculator(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, money.New(1180, "USD"), inclusive.Display(money.New(1000, "USD"), 1800))
+
+	category := "Books"
+	assert.Equal(t, money.New(1080, "USD"), inclusive.DisplayProduct(&models.Product{Price: money.New(1000, "USD"), CategoryName: &category}))
+
+	var none *Calculator
+	assert.Equal(t, money.New(1000, "USD"), none.Display(money.New(1000, "USD"), 1800))
+}
+
+func TestBreakdown(t *testing.T) {
+	items := []models.Item{
+		{TotalPrice: money.New(1000, "USD"), TaxRate: 1800, Tax: money.New(180, "USD")},
+		{TotalPrice: money.New(500, "USD"), TaxRate: 800, Tax: money.New(40, "USD")},
+		{TotalPrice: money.New(2000, "USD"), TaxRate: 1800, Tax: money.New(360, "USD")},
+	}
+
+	lines, total, err := Breakdown(items)
+	require.NoError(t, err)
+	assert.Equal(t, money.New(580, "USD"), total)
+	require.Len(t, lines, 2)
+	assert.Equal(t, int64(800), lines[0].Rate)
+	assert.Equal(t, money.New(500, "USD"), lines[0].Net)
+	assert.Equal(t, int64(1800), lines[1].Rate)
+	assert.Equal(t, money.New(3000, "USD"), lines[1].Net)
+	assert.Equal(t, money.New(540, "USD"), lines[1].Tax)
+}
+
+func TestNewCalculator_Invalid(t *testing.T) {
+	_, err := NewCalculator(config.TaxConfig{DisplayMode: "gross"})
+	assert.Error(t, err)
+
+	_, err = NewCalculator(config.TaxConfig{Rules: []config.TaxRule{{Rate: 5}}})
+	assert.Error(t, err)
+
+	_, err = NewCalculator(config.TaxConfig{DefaultRate: 120})
+	assert.Error(t, err)
+}
+
+func TestFormatRate(t *testing.T) {
+	assert.Equal(t, "18", FormatRate(1800))
+	assert.Equal(t, "8.5", FormatRate(850))
+}
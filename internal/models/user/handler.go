@@ -2,13 +2,17 @@ package user
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"net/mail"
 	"unicode/utf8"
 
 	"github.com/cagrikilicoglu/shopping-basket/internal/api"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/cart"
 	"github.com/cagrikilicoglu/shopping-basket/internal/models/response"
 	"github.com/cagrikilicoglu/shopping-basket/pkg/auth"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
 	"github.com/cagrikilicoglu/shopping-basket/pkg/middleware"
 	"github.com/gin-gonic/gin"
 	"github.com/go-openapi/strfmt"
@@ -17,17 +21,23 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-var minPasswordLength = 8
-var maxPasswordLength = 64
+// GuestCartMerger merges a guest cart into the cart of a user
+type GuestCartMerger interface {
+	Merge(token string, userID uuid.UUID) error
+}
 
 type userHandler struct {
-	repo *UserRepository
-	auth *auth.Authenticator
+	repo   *UserRepository
+	auth   *auth.Authenticator
+	rules  *config.Rules
+	guests GuestCartMerger
 }
 
-func NewUserHandler(r *gin.RouterGroup, repo *UserRepository, auth *auth.Authenticator) {
+func NewUserHandler(r *gin.RouterGroup, repo *UserRepository, auth *auth.Authenticator, rules *config.Rules, guests GuestCartMerger) {
 	h := &userHandler{repo: repo,
-		auth: auth}
+		auth:   auth,
+		rules:  rules,
+		guests: guests}
 
 	r.POST("/signup", h.create)
 	r.POST("/login", h.login)
@@ -53,7 +63,7 @@ func (u *userHandler) create(c *gin.Context) {
 	}
 
 	zap.L().Debug("User.handler.create.validateCredentials")
-	err := validateCredentials(*userBody.Email, *userBody.Password)
+	err := validateCredentials(*userBody.Email, *userBody.Password, u.rules.Get().MinPasswordLength)
 	if err != nil {
 		response.RespondWithError(c, err)
 		return
@@ -77,6 +87,7 @@ func (u *userHandler) create(c *gin.Context) {
 		return
 	}
 
+	u.mergeGuestCart(c, user.ID, tokens)
 	response.RespondWithJson(c, http.StatusCreated, *tokens)
 }
 
@@ -114,10 +125,28 @@ func (u *userHandler) login(c *gin.Context) {
 		response.RespondWithError(c, errors.New("User cannot be authenticated"))
 		return
 	}
+
+	u.mergeGuestCart(c, user.ID, tokens)
 	response.RespondWithJson(c, http.StatusOK, *tokens)
 
 }
 
+// guestCartNotMerged is the warning given with the tokens when the guest cart cannot be merged
+const guestCartNotMerged = "The items of your guest cart cannot be added to your cart"
+
+// mergeGuestCart merges the guest cart of the request into the cart of the user
+// note that a guest cart that cannot be merged does not fail the login, so the user keeps the cart as it was and the tokens carry a warning about it
+func (u *userHandler) mergeGuestCart(c *gin.Context, userID uuid.UUID, tokens *models.Tokens) {
+	token := c.GetHeader(cart.HeaderCartToken)
+	if token == "" {
+		return
+	}
+	if err := u.guests.Merge(token, userID); err != nil {
+		zap.L().Error("User.handler.mergeGuestCart failed to merge guest cart", zap.Error(err))
+		tokens.Warnings = append(tokens.Warnings, guestCartNotMerged)
+	}
+}
+
 // refresh refreshes a user's access token by his/her refresh token input
 func (u *userHandler) Refresh(c *gin.Context) {
 
@@ -143,16 +172,16 @@ func (u *userHandler) Refresh(c *gin.Context) {
 }
 
 // validateCredentials validates user email and password to obey the rule set
-func validateCredentials(email, password string) error {
+func validateCredentials(email, password string, minPasswordLength int) error {
 	ok := validateEmail(email)
 	if !ok {
 		zap.L().Error("User.handler.validateEmail invalid email", zap.Reflect("email", email))
 		return errors.New("Email is not valid")
 	}
-	ok = validatePassword(password)
+	ok = validatePassword(password, minPasswordLength)
 	if !ok {
 		zap.L().Error("User.handler.validateEmail invalid email", zap.Reflect("email", email))
-		return errors.New("Password should be between 8 and 64 characters")
+		return fmt.Errorf("Password should be between %d and %d characters", minPasswordLength, config.MaxPasswordLength)
 	}
 	return nil
 }
@@ -164,9 +193,9 @@ func validateEmail(email string) bool {
 }
 
 // validatePassword validates user password by checking length
-func validatePassword(password string) bool {
+func validatePassword(password string, minPasswordLength int) bool {
 	passwordLength := utf8.RuneCountInString(password)
-	if passwordLength >= minPasswordLength && passwordLength <= maxPasswordLength {
+	if passwordLength >= minPasswordLength && passwordLength <= config.MaxPasswordLength {
 		return true
 	}
 	return false
@@ -2,12 +2,14 @@ package user
 
 import (
 	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/outbox"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 type UserRepository struct {
-	db *gorm.DB
+	db     *gorm.DB
+	events *outbox.OutboxRepository
 }
 
 func (ur *UserRepository) Migration() {
@@ -15,14 +17,30 @@ func (ur *UserRepository) Migration() {
 }
 
 func NewUserRepository(db *gorm.DB) *UserRepository {
-	return &UserRepository{db: db}
+	return &UserRepository{db: db, events: outbox.NewOutboxRepository(db)}
 }
 
 // Create creates a new user in the database
+// note that a UserSignedUp event is recorded in the same transaction
 func (ur *UserRepository) Create(u *models.User) (*models.User, error) {
 	zap.L().Debug("User.repo.create", zap.Reflect("User", u))
 
-	if err := ur.db.Create(u).Error; err != nil {
+	err := ur.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(u).Error; err != nil {
+			return err
+		}
+		event := outbox.UserSignedUpEvent{
+			UserID:    u.ID,
+			FirstName: u.FirstName,
+			LastName:  u.LastName,
+			Role:      u.Role,
+		}
+		if u.Email != nil {
+			event.Email = *u.Email
+		}
+		return ur.events.WithTx(tx).Add(outbox.UserSignedUp, u.ID.String(), event)
+	})
+	if err != nil {
 		zap.L().Error("User.repo.Create failed to create User", zap.Error(err))
 		return nil, err
 	}
@@ -0,0 +1,197 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/api"
+	"github.com/cagrikilicoglu/shopping-basket/internal/httpErrors"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/outbox"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/response"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/middleware"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/pagination"
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/strfmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type webhookHandler struct {
+	repo     *WebhookRepository
+	notifier *Notifier
+}
+
+func NewWebhookHandler(r *gin.RouterGroup, repo *WebhookRepository, notifier *Notifier, cfg *config.Config) {
+	h := &webhookHandler{repo: repo,
+		notifier: notifier}
+
+	r.GET("/admin/webhooks", middleware.AdminAuthMiddleware(cfg.JWTConfig.SecretKey), h.getAll)
+	r.POST("/admin/webhooks", middleware.AdminAuthMiddleware(cfg.JWTConfig.SecretKey), h.create)
+	r.PUT("/admin/webhooks/id/:id", middleware.AdminAuthMiddleware(cfg.JWTConfig.SecretKey), h.update)
+	r.DELETE("/admin/webhooks/id/:id", middleware.AdminAuthMiddleware(cfg.JWTConfig.SecretKey), h.delete)
+	r.GET("/admin/webhooks/id/:id/deliveries", middleware.AdminAuthMiddleware(cfg.JWTConfig.SecretKey), h.getDeliveries)
+	r.POST("/admin/webhooks/deliveries/id/:id/redeliver", middleware.AdminAuthMiddleware(cfg.JWTConfig.SecretKey), h.redeliver)
+}
+
+// getAll fetches all the registered webhooks
+func (wh *webhookHandler) getAll(c *gin.Context) {
+	zap.L().Debug("webhook.handler.getAll")
+
+	webhooks, err := wh.repo.getAll()
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	response.RespondWithJson(c, http.StatusOK, webhooksToResponse(webhooks))
+}
+
+// create registers a webhook by the input in request body
+// note that a secret is generated when it is not given, and the response is the only place it is shown
+func (wh *webhookHandler) create(c *gin.Context) {
+	zap.L().Debug("webhook.handler.create")
+
+	webhook, err := bindWebhook(c)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	if webhook.Secret == "" {
+		if webhook.Secret, err = newSecret(); err != nil {
+			response.RespondWithError(c, err)
+			return
+		}
+	}
+
+	created, err := wh.repo.create(webhook)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	webhookResponse := webhookToResponse(created)
+	webhookResponse.Secret = created.Secret
+	response.RespondWithJson(c, http.StatusCreated, webhookResponse)
+}
+
+// update updates a webhook by the input in request body
+func (wh *webhookHandler) update(c *gin.Context) {
+	id := c.Param("id")
+	zap.L().Debug("webhook.handler.update", zap.Reflect("id", id))
+
+	idParsed, err := uuid.Parse(id)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	webhook, err := bindWebhook(c)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	webhook.ID = idParsed
+
+	updated, err := wh.repo.update(webhook)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	response.RespondWithJson(c, http.StatusOK, webhookToResponse(updated))
+}
+
+// delete deletes a webhook
+func (wh *webhookHandler) delete(c *gin.Context) {
+	id := c.Param("id")
+	zap.L().Debug("webhook.handler.delete", zap.Reflect("id", id))
+
+	idParsed, err := uuid.Parse(id)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	if err := wh.repo.delete(idParsed); err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	response.RespondWithJson(c, http.StatusOK, "Webhook successfully deleted")
+}
+
+// getDeliveries fetches the delivery log of a webhook and paginates the results
+func (wh *webhookHandler) getDeliveries(c *gin.Context) {
+	id := c.Param("id")
+	pageIndex, pageSize := pagination.GetPaginationParametersFromRequest(c)
+	zap.L().Debug("webhook.handler.getDeliveries", zap.Reflect("id", id), zap.Reflect("pageIndex", pageIndex), zap.Reflect("pageSize", pageSize))
+
+	idParsed, err := uuid.Parse(id)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	deliveries, count, err := wh.repo.getDeliveries(idParsed, pageIndex, pagination.ClampPageSize(pageSize))
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	paginatedResult := pagination.NewFromGinRequest(c, count, deliveriesToResponse(deliveries))
+	response.RespondWithJson(c, http.StatusOK, paginatedResult)
+}
+
+// redeliver posts the event of a delivery to its webhook again
+func (wh *webhookHandler) redeliver(c *gin.Context) {
+	id := c.Param("id")
+	zap.L().Debug("webhook.handler.redeliver", zap.Reflect("id", id))
+
+	idParsed, err := uuid.Parse(id)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+
+	delivery, err := wh.notifier.Redeliver(c.Request.Context(), idParsed)
+	if err != nil {
+		response.RespondWithError(c, err)
+		return
+	}
+	response.RespondWithJson(c, http.StatusOK, deliveryToResponse(delivery))
+}
+
+// bindWebhook binds and validates the webhook in the request body
+func bindWebhook(c *gin.Context) (*models.Webhook, error) {
+	webhookBody := &api.Webhook{}
+	if err := c.Bind(&webhookBody); err != nil {
+		return nil, err
+	}
+	if err := webhookBody.Validate(strfmt.NewFormats()); err != nil {
+		return nil, err
+	}
+
+	webhook := responseToWebhook(webhookBody)
+	if err := validateWebhook(webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// validateWebhook checks that the events of a webhook can be posted to its url
+func validateWebhook(w *models.Webhook) error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return httpErrors.NewApiError(http.StatusBadRequest, "Webhook url should be an absolute http or https url", nil)
+	}
+	if !outbox.IsEventType(w.EventType) {
+		return httpErrors.NewApiError(http.StatusBadRequest, "Event type should be one of OrderPlaced, OrderCanceled, StockChanged, ProductUpdated, UserSignedUp", nil)
+	}
+	return nil
+}
+
+// newSecret generates a random secret to sign the deliveries of a webhook
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,150 @@
+package webhook
+
+import (
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+func (wr *WebhookRepository) Migration() {
+	wr.db.AutoMigrate(&models.Webhook{}, &models.WebhookDelivery{})
+}
+
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// getAll fetches all the registered webhooks
+func (wr *WebhookRepository) getAll() (*[]models.Webhook, error) {
+	zap.L().Debug("webhook.repo.getAll")
+
+	var webhooks []models.Webhook
+	if err := wr.db.Order("created_at").Find(&webhooks).Error; err != nil {
+		zap.L().Error("webhook.repo.getAll failed to get webhooks", zap.Error(err))
+		return nil, err
+	}
+	return &webhooks, nil
+}
+
+// getEnabledForEventType fetches the webhooks that the events of the given type are posted to
+func (wr *WebhookRepository) getEnabledForEventType(eventType string) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	if err := wr.db.Where("event_type = ? AND disabled = ?", eventType, false).Order("created_at").Find(&webhooks).Error; err != nil {
+		zap.L().Error("webhook.repo.getEnabledForEventType failed to get webhooks", zap.Error(err))
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// getByID fetches a webhook by its id
+// note that a deleted webhook is also found, so that its deliveries can be redelivered
+func (wr *WebhookRepository) getByID(id uuid.UUID) (*models.Webhook, error) {
+	zap.L().Debug("webhook.repo.getByID", zap.Reflect("id", id))
+
+	var webhook models.Webhook
+	if err := wr.db.Unscoped().Where("id = ?", id).First(&webhook).Error; err != nil {
+		zap.L().Error("webhook.repo.getByID failed to get webhook", zap.Error(err))
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// create registers a webhook
+func (wr *WebhookRepository) create(w *models.Webhook) (*models.Webhook, error) {
+	zap.L().Debug("webhook.repo.create", zap.Reflect("url", w.URL), zap.Reflect("eventType", w.EventType))
+
+	if err := wr.db.Create(w).Error; err != nil {
+		zap.L().Error("webhook.repo.create failed to create webhook", zap.Error(err))
+		return nil, err
+	}
+	return w, nil
+}
+
+// update updates the url, the event type and the state of a webhook, and its secret when a new one is given
+func (wr *WebhookRepository) update(w *models.Webhook) (*models.Webhook, error) {
+	zap.L().Debug("webhook.repo.update", zap.Reflect("id", w.ID))
+
+	columns := []string{"url", "event_type", "disabled"}
+	if w.Secret != "" {
+		columns = append(columns, "secret")
+	}
+	result := wr.db.Model(w).Select(columns).Updates(w)
+	if result.Error != nil {
+		zap.L().Error("webhook.repo.update failed to update webhook", zap.Error(result.Error))
+		return nil, result.Error
+	}
+	if result.RowsAffected < 1 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return wr.getByID(w.ID)
+}
+
+// delete deletes a webhook
+// note that its delivery log is kept
+func (wr *WebhookRepository) delete(id uuid.UUID) error {
+	zap.L().Debug("webhook.repo.delete", zap.Reflect("id", id))
+
+	result := wr.db.Where("id = ?", id).Delete(&models.Webhook{})
+	if result.Error != nil {
+		zap.L().Error("webhook.repo.delete failed to delete webhook", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// createDelivery records a delivery attempt in the delivery log
+func (wr *WebhookRepository) createDelivery(d *models.WebhookDelivery) error {
+	if err := wr.db.Create(d).Error; err != nil {
+		zap.L().Error("webhook.repo.createDelivery failed to record delivery", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// hasSucceeded checks if an event is already delivered to a webhook
+func (wr *WebhookRepository) hasSucceeded(webhookID, eventID uuid.UUID) (bool, error) {
+	var count int64
+	if err := wr.db.Model(&models.WebhookDelivery{}).Where("webhook_id = ? AND event_id = ? AND succeeded = ?", webhookID, eventID, true).Count(&count).Error; err != nil {
+		zap.L().Error("webhook.repo.hasSucceeded failed to count deliveries", zap.Error(err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// getDeliveries fetches the delivery log of a webhook, latest first, and paginates it
+func (wr *WebhookRepository) getDeliveries(webhookID uuid.UUID, pageIndex, pageSize int) (*[]models.WebhookDelivery, int, error) {
+	zap.L().Debug("webhook.repo.getDeliveries", zap.Reflect("webhookID", webhookID), zap.Reflect("pageIndex", pageIndex), zap.Reflect("pageSize", pageSize))
+
+	var deliveries []models.WebhookDelivery
+	var count int64
+	query := wr.db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookID).Session(&gorm.Session{})
+	if err := query.Count(&count).Error; err != nil {
+		zap.L().Error("webhook.repo.getDeliveries failed to count deliveries", zap.Error(err))
+		return nil, 0, err
+	}
+	if err := query.Order("created_at desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&deliveries).Error; err != nil {
+		zap.L().Error("webhook.repo.getDeliveries failed to get deliveries", zap.Error(err))
+		return nil, 0, err
+	}
+	return &deliveries, int(count), nil
+}
+
+// getDelivery fetches a delivery attempt by its id
+func (wr *WebhookRepository) getDelivery(id uuid.UUID) (*models.WebhookDelivery, error) {
+	zap.L().Debug("webhook.repo.getDelivery", zap.Reflect("id", id))
+
+	var delivery models.WebhookDelivery
+	if err := wr.db.Where("id = ?", id).First(&delivery).Error; err != nil {
+		zap.L().Error("webhook.repo.getDelivery failed to get delivery", zap.Error(err))
+		return nil, err
+	}
+	return &delivery, nil
+}
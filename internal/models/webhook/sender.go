@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Headers of a webhook delivery
+const (
+	HeaderEventID   = "X-Webhook-Event-Id"
+	HeaderEventType = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// envelope is the body of a webhook delivery
+type envelope struct {
+	ID   uuid.UUID       `json:"id"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Sender posts signed events to webhooks
+type Sender struct {
+	client *http.Client
+}
+
+func NewSender(cfg config.WebhookConfig) *Sender {
+	timeout := time.Duration(cfg.TimeoutSecs) * time.Second
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Sender{client: &http.Client{Timeout: timeout}}
+}
+
+// Sign returns the signature of a delivery body sent at the given unix time
+// note that the timestamp is signed with the body, so that a captured delivery cannot be replayed later with a new timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send posts an event to a webhook and returns the attempt to be recorded in the delivery log
+// note that only 2xx responses count as delivered
+func (s *Sender) Send(ctx context.Context, w *models.Webhook, eventID uuid.UUID, eventType, payload string) *models.WebhookDelivery {
+	zap.L().Debug("webhook.sender.Send", zap.Reflect("webhookID", w.ID), zap.Reflect("eventID", eventID))
+
+	delivery := &models.WebhookDelivery{
+		WebhookID: w.ID,
+		EventID:   eventID,
+		EventType: eventType,
+		Payload:   payload,
+	}
+
+	body, err := json.Marshal(envelope{ID: eventID, Type: eventType, Data: json.RawMessage(payload)})
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, eventID.String())
+	req.Header.Set(HeaderEventType, eventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(w.Secret, timestamp, body))
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	delivery.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		zap.L().Error("webhook.sender.Send failed to post event", zap.Reflect("webhookID", w.ID), zap.Error(err))
+		delivery.Error = err.Error()
+		return delivery
+	}
+	defer resp.Body.Close()
+	// the body is read so that the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	delivery.StatusCode = resp.StatusCode
+	delivery.Succeeded = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !delivery.Succeeded {
+		delivery.Error = fmt.Sprintf("webhook responded with %d", resp.StatusCode)
+	}
+	return delivery
+}
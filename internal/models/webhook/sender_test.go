@@ -0,0 +1,114 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models/outbox"
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const testSecret = "s3cret"
+
+// receiver is a local webhook endpoint that checks the signature of the deliveries
+func receiver(t *testing.T, status int, received *[]envelope) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		require.NoError(t, err)
+		assert.Equal(t, Sign(testSecret, timestamp, body), r.Header.Get(HeaderSignature))
+
+		var e envelope
+		require.NoError(t, json.Unmarshal(body, &e))
+		assert.Equal(t, e.ID.String(), r.Header.Get(HeaderEventID))
+		assert.Equal(t, e.Type, r.Header.Get(HeaderEventType))
+		*received = append(*received, e)
+		w.WriteHeader(status)
+	}))
+}
+
+func TestSign(t *testing.T) {
+	signature := Sign(testSecret, 1650000000, []byte(`{"id":"1"}`))
+
+	assert.Equal(t, signature, Sign(testSecret, 1650000000, []byte(`{"id":"1"}`)))
+	assert.NotEqual(t, signature, Sign(testSecret, 1650000001, []byte(`{"id":"1"}`)))
+	assert.NotEqual(t, signature, Sign("other", 1650000000, []byte(`{"id":"1"}`)))
+	assert.Len(t, signature, len("sha256=")+64)
+}
+
+func TestSender_Send(t *testing.T) {
+	var received []envelope
+	srv := receiver(t, http.StatusNoContent, &received)
+	defer srv.Close()
+
+	w := &models.Webhook{ID: uuid.New(), URL: srv.URL, Secret: testSecret}
+	eventID := uuid.New()
+	delivery := NewSender(config.WebhookConfig{}).Send(context.Background(), w, eventID, outbox.OrderPlaced, `{"orderId":"1"}`)
+
+	assert.True(t, delivery.Succeeded)
+	assert.Equal(t, http.StatusNoContent, delivery.StatusCode)
+	assert.Empty(t, delivery.Error)
+	require.Len(t, received, 1)
+	assert.Equal(t, eventID, received[0].ID)
+	assert.JSONEq(t, `{"orderId":"1"}`, string(received[0].Data))
+}
+
+func TestSender_SendFailure(t *testing.T) {
+	var received []envelope
+	srv := receiver(t, http.StatusInternalServerError, &received)
+	defer srv.Close()
+
+	w := &models.Webhook{ID: uuid.New(), URL: srv.URL, Secret: testSecret}
+	delivery := NewSender(config.WebhookConfig{}).Send(context.Background(), w, uuid.New(), outbox.StockChanged, `{}`)
+
+	assert.False(t, delivery.Succeeded)
+	assert.Equal(t, http.StatusInternalServerError, delivery.StatusCode)
+	assert.Equal(t, "webhook responded with 500", delivery.Error)
+}
+
+func TestNotifier_ConsumeSkipsDeliveredWebhooks(t *testing.T) {
+	var received []envelope
+	srv := receiver(t, http.StatusOK, &received)
+	defer srv.Close()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db, PreferSimpleProtocol: true}), &gorm.Config{})
+	require.NoError(t, err)
+	notifier := NewNotifier(NewWebhookRepository(gdb), NewSender(config.WebhookConfig{}))
+
+	delivered, pending := uuid.New(), uuid.New()
+	event := &models.OutboxEvent{ID: uuid.New(), Type: outbox.OrderPlaced, Payload: `{"orderId":"1"}`}
+
+	mock.ExpectQuery(`SELECT \* FROM "webhooks" WHERE \(event_type = \$1 AND disabled = \$2\)`).
+		WithArgs(outbox.OrderPlaced, false).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url", "event_type", "secret"}).
+			AddRow(delivered.String(), srv.URL, outbox.OrderPlaced, testSecret).
+			AddRow(pending.String(), srv.URL, outbox.OrderPlaced, testSecret))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "webhook_deliveries"`).
+		WithArgs(delivered, event.ID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "webhook_deliveries"`).
+		WithArgs(pending, event.ID, true).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO "webhook_deliveries"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, notifier.Consume(context.Background(), event))
+	assert.Len(t, received, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
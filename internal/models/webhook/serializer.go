@@ -0,0 +1,69 @@
+package webhook
+
+import (
+	"strings"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/api"
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/go-openapi/strfmt"
+	"go.uber.org/zap"
+)
+
+// responseToWebhook converts webhook response model to database model
+func responseToWebhook(w *api.Webhook) *models.Webhook {
+	zap.L().Debug("webhook.serializer.responseToWebhook", zap.Reflect("url", w.URL), zap.Reflect("eventType", w.EventType))
+
+	return &models.Webhook{
+		URL:       strings.TrimSpace(*w.URL),
+		EventType: strings.TrimSpace(*w.EventType),
+		Secret:    w.Secret,
+		Disabled:  w.Disabled,
+	}
+}
+
+// webhookToResponse converts webhook database model to response model
+// note that the secret is left out, since it is only shown when the webhook is registered
+func webhookToResponse(w *models.Webhook) *api.Webhook {
+	return &api.Webhook{
+		ID:        w.ID.String(),
+		URL:       &w.URL,
+		EventType: &w.EventType,
+		Disabled:  w.Disabled,
+	}
+}
+
+// webhooksToResponse converts webhook database model to response model as a batch
+func webhooksToResponse(ws *[]models.Webhook) []*api.Webhook {
+	webhooks := make([]*api.Webhook, 0)
+	for i := range *ws {
+		wsDeref := *ws
+		webhooks = append(webhooks, webhookToResponse(&wsDeref[i]))
+	}
+	return webhooks
+}
+
+// deliveryToResponse converts webhook delivery database model to response model
+func deliveryToResponse(d *models.WebhookDelivery) *api.WebhookDelivery {
+	return &api.WebhookDelivery{
+		ID:         d.ID.String(),
+		WebhookID:  d.WebhookID.String(),
+		EventID:    d.EventID.String(),
+		EventType:  d.EventType,
+		StatusCode: int32(d.StatusCode),
+		Error:      d.Error,
+		Succeeded:  d.Succeeded,
+		DurationMs: d.DurationMs,
+		Redelivery: d.Redelivery,
+		Date:       strfmt.DateTime(d.CreatedAt),
+	}
+}
+
+// deliveriesToResponse converts webhook delivery database model to response model as a batch
+func deliveriesToResponse(ds *[]models.WebhookDelivery) []*api.WebhookDelivery {
+	deliveries := make([]*api.WebhookDelivery, 0)
+	for i := range *ds {
+		dsDeref := *ds
+		deliveries = append(deliveries, deliveryToResponse(&dsDeref[i]))
+	}
+	return deliveries
+}
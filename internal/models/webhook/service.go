@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cagrikilicoglu/shopping-basket/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Notifier delivers the outbox events to the webhooks registered for their types
+// note that a failed delivery makes the event fail, so the outbox retries it with a growing delay
+// the webhooks that already received the event are skipped on the retries, and the events are posted outside of any transaction since the dispatcher claims them beforehand
+type Notifier struct {
+	repo   *WebhookRepository
+	sender *Sender
+}
+
+func NewNotifier(repo *WebhookRepository, sender *Sender) *Notifier {
+	return &Notifier{repo: repo, sender: sender}
+}
+
+// Consume posts an outbox event to the webhooks of its type and records every attempt in the delivery log
+func (n *Notifier) Consume(ctx context.Context, e *models.OutboxEvent) error {
+	zap.L().Debug("webhook.service.Consume", zap.Reflect("eventID", e.ID), zap.Reflect("type", e.Type))
+
+	webhooks, err := n.repo.getEnabledForEventType(e.Type)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for i := range webhooks {
+		// the remaining webhooks are left for the retry once the lease of the event is over
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		delivered, err := n.repo.hasSucceeded(webhooks[i].ID, e.ID)
+		if err != nil {
+			return err
+		}
+		if delivered {
+			continue
+		}
+
+		delivery := n.sender.Send(ctx, &webhooks[i], e.ID, e.Type, e.Payload)
+		if err := n.repo.createDelivery(delivery); err != nil {
+			return err
+		}
+		if !delivery.Succeeded {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d webhooks failed", failed, len(webhooks))
+	}
+	return nil
+}
+
+// Redeliver posts the event of a delivery to its webhook again and returns the new attempt
+func (n *Notifier) Redeliver(ctx context.Context, deliveryID uuid.UUID) (*models.WebhookDelivery, error) {
+	zap.L().Debug("webhook.service.Redeliver", zap.Reflect("deliveryID", deliveryID))
+
+	previous, err := n.repo.getDelivery(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	webhook, err := n.repo.getByID(previous.WebhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := n.sender.Send(ctx, webhook, previous.EventID, previous.EventType, previous.Payload)
+	delivery.Redelivery = true
+	if err := n.repo.createDelivery(delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
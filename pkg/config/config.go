@@ -2,16 +2,31 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"os"
 
 	"github.com/spf13/viper"
 )
 
 // Config
 type Config struct {
-	ServerConfig ServerConfig `yaml:"ServerConfig"`
-	JWTConfig    JWTConfig    `yaml:"JWTConfig"`
-	DBConfig     DBConfig     `yaml:"DBConfig"`
-	Logger       Logger       `yaml:"Logger"`
+	ServerConfig        ServerConfig        `yaml:"ServerConfig"`
+	JWTConfig           JWTConfig           `yaml:"JWTConfig"`
+	DBConfig            DBConfig            `yaml:"DBConfig"`
+	Logger              Logger              `yaml:"Logger"`
+	PaymentConfig       PaymentConfig       `yaml:"PaymentConfig"`
+	IdempotencyConfig   IdempotencyConfig   `yaml:"IdempotencyConfig"`
+	CurrencyConfig      CurrencyConfig      `yaml:"CurrencyConfig"`
+	ShippingConfig      ShippingConfig      `yaml:"ShippingConfig"`
+	TaxConfig           TaxConfig           `yaml:"TaxConfig"`
+	InvoiceConfig       InvoiceConfig       `yaml:"InvoiceConfig"`
+	OutboxConfig        OutboxConfig        `yaml:"OutboxConfig"`
+	WebhookConfig       WebhookConfig       `yaml:"WebhookConfig"`
+	MailerConfig        MailerConfig        `yaml:"MailerConfig"`
+	SchedulerConfig     SchedulerConfig     `yaml:"SchedulerConfig"`
+	BusinessRulesConfig BusinessRulesConfig `yaml:"BusinessRulesConfig"`
+	GuestCartConfig     GuestCartConfig     `yaml:"GuestCartConfig"`
+	ReservationConfig   ReservationConfig   `yaml:"ReservationConfig"`
 }
 
 // ServerConfig
@@ -53,8 +68,150 @@ type Logger struct {
 	Level       string `yaml:"Level"`
 }
 
+// PaymentConfig
+type PaymentConfig struct {
+	Provider         string  `yaml:"Provider"`
+	AllowFake        bool    `yaml:"AllowFake"`
+	FakeDeclineAbove float64 `yaml:"FakeDeclineAbove"`
+	FakeFailCapture  bool    `yaml:"FakeFailCapture"`
+	FakeFailRefund   bool    `yaml:"FakeFailRefund"`
+}
+
+// IdempotencyConfig
+type IdempotencyConfig struct {
+	WindowMins int `yaml:"WindowMins"`
+}
+
+// CurrencyConfig
+type CurrencyConfig struct {
+	Base string `yaml:"Base"`
+}
+
+// ShippingConfig
+type ShippingConfig struct {
+	FreeAbove         float64        `yaml:"FreeAbove"`
+	VolumetricDivisor int            `yaml:"VolumetricDivisor"`
+	Zones             []ShippingZone `yaml:"Zones"`
+}
+
+// ShippingZone
+type ShippingZone struct {
+	Name        string   `yaml:"Name"`
+	ZipPrefixes []string `yaml:"ZipPrefixes"`
+	BaseFee     float64  `yaml:"BaseFee"`
+	PerKgFee    float64  `yaml:"PerKgFee"`
+}
+
+// TaxConfig
+type TaxConfig struct {
+	DisplayMode   string    `yaml:"DisplayMode"`
+	DefaultRegion string    `yaml:"DefaultRegion"`
+	DefaultRate   float64   `yaml:"DefaultRate"`
+	Rules         []TaxRule `yaml:"Rules"`
+}
+
+// TaxRule
+type TaxRule struct {
+	Category string  `yaml:"Category"`
+	Region   string  `yaml:"Region"`
+	Rate     float64 `yaml:"Rate"`
+}
+
+// InvoiceConfig
+type InvoiceConfig struct {
+	NumberPrefix    string   `yaml:"NumberPrefix"`
+	SellerName      string   `yaml:"SellerName"`
+	SellerAddress   []string `yaml:"SellerAddress"`
+	SellerTaxNumber string   `yaml:"SellerTaxNumber"`
+}
+
+// OutboxConfig
+type OutboxConfig struct {
+	PollIntervalMs int `yaml:"PollIntervalMs"`
+	BatchSize      int `yaml:"BatchSize"`
+	MaxBackoffSecs int `yaml:"MaxBackoffSecs"`
+	LeaseSecs      int `yaml:"LeaseSecs"`
+}
+
+// WebhookConfig
+type WebhookConfig struct {
+	TimeoutSecs int `yaml:"TimeoutSecs"`
+}
+
+// MailerConfig
+type MailerConfig struct {
+	Driver      string `yaml:"Driver"`
+	From        string `yaml:"From"`
+	Host        string `yaml:"Host"`
+	Port        int    `yaml:"Port"`
+	Username    string `yaml:"Username"`
+	Password    string `yaml:"Password"`
+	Directory   string `yaml:"Directory"`
+	TimeoutSecs int    `yaml:"TimeoutSecs"`
+}
+
+// SchedulerConfig
+type SchedulerConfig struct {
+	Jobs                   []ScheduledJob `yaml:"Jobs"`
+	UnpaidOrderTimeoutMins int            `yaml:"UnpaidOrderTimeoutMins"`
+	StaleCartDays          int            `yaml:"StaleCartDays"`
+}
+
+// ScheduledJob
+type ScheduledJob struct {
+	Name     string `yaml:"Name"`
+	Schedule string `yaml:"Schedule"`
+}
+
+// GuestCartConfig
+type GuestCartConfig struct {
+	SecretKey          string `yaml:"SecretKey"`
+	TokenDurationHours int    `yaml:"TokenDurationHours"`
+}
+
+// GuestCartSecretEnv is the environment variable that sets the key signing the guest cart tokens
+const GuestCartSecretEnv = "GUEST_CART_SECRET_KEY"
+
+// loadSecret takes the signing key of the guest cart tokens from the environment when it is set there
+// note that the key is required, since a token signed by an empty key could be forged by anyone
+func (g *GuestCartConfig) loadSecret() error {
+	if secret := os.Getenv(GuestCartSecretEnv); secret != "" {
+		g.SecretKey = secret
+	}
+	if g.SecretKey == "" {
+		return fmt.Errorf("guest cart secret key is not configured, set %s", GuestCartSecretEnv)
+	}
+	return nil
+}
+
+// ReservationConfig
+type ReservationConfig struct {
+	Enabled bool `yaml:"Enabled"`
+	TTLMins int  `yaml:"TTLMins"`
+}
+
 // LoadConfig reads configuration from a file
 func LoadConfig(fileName string) (*Config, error) {
+	v, err := newViper(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	err = v.Unmarshal(&c)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.BusinessRulesConfig.Validate(); err != nil {
+		return nil, err
+	}
+	if err := c.GuestCartConfig.loadSecret(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// newViper reads the configuration file with the given name
+func newViper(fileName string) (*viper.Viper, error) {
 	v := viper.New()
 
 	v.SetConfigName(fileName)
@@ -68,10 +225,5 @@ func LoadConfig(fileName string) (*Config, error) {
 		}
 		return nil, err
 	}
-	var c Config
-	err := v.Unmarshal(&c)
-	if err != nil {
-		return nil, err
-	}
-	return &c, nil
+	return v, nil
 }
@@ -0,0 +1,21 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuestCartConfig_LoadSecret(t *testing.T) {
+	t.Setenv(GuestCartSecretEnv, "")
+	assert.Error(t, (&GuestCartConfig{}).loadSecret())
+
+	g := GuestCartConfig{SecretKey: "fromFile"}
+	require.NoError(t, g.loadSecret())
+	assert.Equal(t, "fromFile", g.SecretKey)
+
+	t.Setenv(GuestCartSecretEnv, "fromEnv")
+	require.NoError(t, g.loadSecret())
+	assert.Equal(t, "fromEnv", g.SecretKey)
+}
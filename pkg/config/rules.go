@@ -0,0 +1,108 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// MaxPasswordLength is the longest password that is accepted, since bcrypt ignores the bytes beyond 72
+const MaxPasswordLength = 64
+
+// BusinessRulesConfig
+type BusinessRulesConfig struct {
+	MaxItemsForCart      int     `yaml:"MaxItemsForCart"`
+	MaxAllowedCancelDays int     `yaml:"MaxAllowedCancelDays"`
+	MinOrderPrice        float64 `yaml:"MinOrderPrice"`
+	MinPasswordLength    int     `yaml:"MinPasswordLength"`
+	MaxPageSize          int     `yaml:"MaxPageSize"`
+}
+
+// Validate checks that the business rules are in their allowed ranges
+// note that the minimum order price is in major units of the base currency and can be zero to allow any order
+func (b BusinessRulesConfig) Validate() error {
+	if b.MaxItemsForCart <= 0 {
+		return errors.New("BusinessRulesConfig.MaxItemsForCart should be positive")
+	}
+	if b.MaxAllowedCancelDays < 0 {
+		return errors.New("BusinessRulesConfig.MaxAllowedCancelDays should not be negative")
+	}
+	if b.MinOrderPrice < 0 {
+		return errors.New("BusinessRulesConfig.MinOrderPrice should not be negative")
+	}
+	if b.MinPasswordLength <= 0 || b.MinPasswordLength > MaxPasswordLength {
+		return fmt.Errorf("BusinessRulesConfig.MinPasswordLength should be between 1 and %d", MaxPasswordLength)
+	}
+	if b.MaxPageSize <= 0 {
+		return errors.New("BusinessRulesConfig.MaxPageSize should be positive")
+	}
+	return nil
+}
+
+// Rules holds the current business rules, which are replaced when the configuration file changes
+type Rules struct {
+	current   atomic.Value
+	mu        sync.Mutex
+	listeners []func(BusinessRulesConfig)
+}
+
+func NewRules(b BusinessRulesConfig) *Rules {
+	r := &Rules{}
+	r.current.Store(b)
+	return r
+}
+
+// Get returns the current business rules
+func (r *Rules) Get() BusinessRulesConfig {
+	return r.current.Load().(BusinessRulesConfig)
+}
+
+// Set validates and replaces the business rules and notifies the listeners
+// note that invalid rules are rejected and the current rules are kept
+func (r *Rules) Set(b BusinessRulesConfig) error {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current.Store(b)
+	for _, fn := range r.listeners {
+		fn(b)
+	}
+	return nil
+}
+
+// OnChange registers a function that is called with the current rules and again every time they are replaced
+func (r *Rules) OnChange(fn func(BusinessRulesConfig)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, fn)
+	fn(r.Get())
+}
+
+// Watch reloads the business rules whenever the given configuration file changes
+// note that the other sections of the file are only read at startup, so changing them still needs a restart
+func (r *Rules) Watch(fileName string) error {
+	v, err := newViper(fileName)
+	if err != nil {
+		return err
+	}
+	v.OnConfigChange(func(e fsnotify.Event) {
+		var c Config
+		if err := v.Unmarshal(&c); err != nil {
+			zap.L().Error("config.rules.Watch failed to read the changed configuration", zap.Error(err))
+			return
+		}
+		if err := r.Set(c.BusinessRulesConfig); err != nil {
+			zap.L().Error("config.rules.Watch rejected the changed business rules", zap.Error(err))
+			return
+		}
+		zap.L().Info("config.rules.Watch reloaded the business rules", zap.Reflect("rules", c.BusinessRulesConfig))
+	})
+	v.WatchConfig()
+	return nil
+}
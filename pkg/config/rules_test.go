@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRules = BusinessRulesConfig{
+	MaxItemsForCart:      20,
+	MaxAllowedCancelDays: 14,
+	MinOrderPrice:        50,
+	MinPasswordLength:    8,
+	MaxPageSize:          100,
+}
+
+func TestBusinessRulesConfig_Validate(t *testing.T) {
+	require.NoError(t, testRules.Validate())
+
+	invalid := []func(b *BusinessRulesConfig){
+		func(b *BusinessRulesConfig) { b.MaxItemsForCart = 0 },
+		func(b *BusinessRulesConfig) { b.MaxAllowedCancelDays = -1 },
+		func(b *BusinessRulesConfig) { b.MinOrderPrice = -5 },
+		func(b *BusinessRulesConfig) { b.MinPasswordLength = 0 },
+		func(b *BusinessRulesConfig) { b.MinPasswordLength = MaxPasswordLength + 1 },
+		func(b *BusinessRulesConfig) { b.MaxPageSize = 0 },
+	}
+	for i, change := range invalid {
+		b := testRules
+		change(&b)
+		assert.Error(t, b.Validate(), i)
+	}
+}
+
+func TestRules_Set(t *testing.T) {
+	r := NewRules(testRules)
+	var notified []int
+	r.OnChange(func(b BusinessRulesConfig) { notified = append(notified, b.MaxItemsForCart) })
+
+	changed := testRules
+	changed.MaxItemsForCart = 5
+	require.NoError(t, r.Set(changed))
+
+	invalid := testRules
+	invalid.MaxItemsForCart = 0
+	require.Error(t, r.Set(invalid))
+
+	assert.Equal(t, 5, r.Get().MaxItemsForCart)
+	assert.Equal(t, []int{20, 5}, notified)
+}
+
+func TestRules_Watch(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer os.Chdir(wd)
+
+	write := func(maxItems string) {
+		content := "BusinessRulesConfig:\n  MaxItemsForCart: " + maxItems + "\n  MaxAllowedCancelDays: 14\n  MinOrderPrice: 50\n  MinPasswordLength: 8\n  MaxPageSize: 100\n"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "test.yaml"), []byte(content), 0o644))
+	}
+	write("20")
+
+	r := NewRules(testRules)
+	require.NoError(t, r.Watch("test"))
+
+	write("5")
+	assert.Eventually(t, func() bool { return r.Get().MaxItemsForCart == 5 }, 5*time.Second, 10*time.Millisecond)
+
+	// invalid rules keep the current ones
+	write("0")
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, 5, r.Get().MaxItemsForCart)
+}
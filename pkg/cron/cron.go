@@ -0,0 +1,166 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// descriptors are the shorthands accepted in place of the five fields
+var descriptors = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// bounds are the allowed values of the minute, hour, day of month, month and day of week fields
+var bounds = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+
+// maxLookahead limits the search of the next activation of a schedule that never matches, e.g. February 30th
+const maxLookahead = 5 * 366 * 24 * time.Hour
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	expr   string
+	fields [5]uint64
+	// wildcard day fields change how the day of month and the day of week fields combine
+	anyDayOfMonth bool
+	anyDayOfWeek  bool
+}
+
+// Parse parses a cron expression with the minute, hour, day of month, month and day of week fields
+// note that every field accepts *, numbers, ranges, lists and steps such as */15 or 1-5, and the @hourly, @daily, @weekly and @monthly shorthands are accepted as well
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	spec := expr
+	if d, ok := descriptors[spec]; ok {
+		spec = d
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("cron expression %q should have 5 fields", expr)
+	}
+
+	s := &Schedule{expr: expr}
+	for i, part := range parts {
+		bits, err := parseField(part, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("cron expression %q: %v", expr, err)
+		}
+		s.fields[i] = bits
+	}
+	s.anyDayOfMonth = parts[2] == "*"
+	s.anyDayOfWeek = parts[4] == "*"
+	return s, nil
+}
+
+// String returns the expression that the schedule is parsed from
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// Next returns the first activation of the schedule after the given time
+// note that the zero time is returned when the schedule does not activate within five years
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxLookahead)
+
+	for t.Before(limit) {
+		if !has(s.fields[3], int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(s.fields[1], t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(s.fields[0], t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchesDay checks the day of month and the day of week fields
+// note that a day matches either of them when both are restricted, as in the standard cron
+func (s *Schedule) matchesDay(t time.Time) bool {
+	dom := has(s.fields[2], t.Day())
+	dow := has(s.fields[4], int(t.Weekday()))
+	if s.anyDayOfMonth || s.anyDayOfWeek {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// parseField parses a comma separated list of values, ranges and steps into a bit set
+func parseField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("step of %q is not valid", part)
+			}
+			rangePart, step = part[:i], n
+		}
+
+		from, to := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			ends := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if from, err = parseValue(ends[0], min, max); err != nil {
+				return 0, err
+			}
+			if to, err = parseValue(ends[1], min, max); err != nil {
+				return 0, err
+			}
+			if from > to {
+				return 0, fmt.Errorf("range %q is not valid", rangePart)
+			}
+		default:
+			n, err := parseValue(rangePart, min, max)
+			if err != nil {
+				return 0, err
+			}
+			from = n
+			// a single value with a step runs from the value to the end of the field, e.g. 5/15
+			if step == 1 {
+				to = n
+			}
+		}
+
+		for v := from; v <= to; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// parseValue parses a number of a field and checks its bounds
+func parseValue(s string, min, max int) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	if n < min || n > max {
+		return 0, fmt.Errorf("%d is out of range %d-%d", n, min, max)
+	}
+	return n, nil
+}
+
+// has checks if the value is in the bit set
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
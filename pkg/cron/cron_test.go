@@ -0,0 +1,53 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func at(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestNext(t *testing.T) {
+	cases := []struct {
+		expr string
+		from string
+		next string
+	}{
+		{expr: "*/15 * * * *", from: "2022-04-10 10:07", next: "2022-04-10 10:15"},
+		{expr: "*/15 * * * *", from: "2022-04-10 10:15", next: "2022-04-10 10:30"},
+		{expr: "30 2 * * *", from: "2022-04-10 10:07", next: "2022-04-11 02:30"},
+		{expr: "@hourly", from: "2022-04-10 23:59", next: "2022-04-11 00:00"},
+		{expr: "0 9 * * 1-5", from: "2022-04-08 10:00", next: "2022-04-11 09:00"},
+		{expr: "0 0 1 */3 *", from: "2022-04-10 10:00", next: "2022-07-01 00:00"},
+		{expr: "0 0 13 * 5", from: "2022-04-10 10:00", next: "2022-04-13 00:00"},
+		{expr: "5/20 8,20 * * *", from: "2022-04-10 08:25", next: "2022-04-10 08:45"},
+	}
+	for _, tc := range cases {
+		s, err := Parse(tc.expr)
+		require.NoError(t, err, tc.expr)
+		assert.Equal(t, at(tc.next), s.Next(at(tc.from)), tc.expr)
+	}
+}
+
+func TestNext_Never(t *testing.T) {
+	s, err := Parse("0 0 30 2 *")
+	require.NoError(t, err)
+
+	assert.True(t, s.Next(at("2022-04-10 10:00")).IsZero())
+}
+
+func TestParse_Error(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@yearly"} {
+		_, err := Parse(expr)
+		assert.Error(t, err, expr)
+	}
+}
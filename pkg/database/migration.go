@@ -0,0 +1,35 @@
+package database
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cagrikilicoglu/shopping-basket/pkg/money"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// MigrateMoneyColumn moves the values of a legacy floating point price column into the money columns with the given prefix
+// the legacy column is dropped afterwards, so the migration runs only once
+// note that a failed migration keeps the legacy column and leaves the money columns empty, so the callers should not start the service on an error
+func MigrateMoneyColumn(db *gorm.DB, table, column, prefix string) error {
+	if !db.Migrator().HasColumn(table, column) {
+		return nil
+	}
+	zap.L().Info("database.MigrateMoneyColumn", zap.String("table", table), zap.String("column", column))
+
+	currency := money.DefaultCurrency
+	scale := math.Pow10(money.Exponent(currency))
+	return db.Transaction(func(tx *gorm.DB) error {
+		update := fmt.Sprintf(`UPDATE %q SET %q = ROUND(COALESCE(%q, 0) * ?), %q = ?`, table, prefix+"amount", column, prefix+"currency")
+		if err := tx.Exec(update, scale, currency).Error; err != nil {
+			zap.L().Error("database.MigrateMoneyColumn failed to copy amounts", zap.Error(err))
+			return err
+		}
+		if err := tx.Migrator().DropColumn(table, column); err != nil {
+			zap.L().Error("database.MigrateMoneyColumn failed to drop legacy column", zap.Error(err))
+			return err
+		}
+		return nil
+	})
+}
@@ -10,7 +10,8 @@ import (
 )
 
 // Shutdown allows the server to shutdown gracefully
-func Shutdown(srv *http.Server, timeout time.Duration) {
+// note that the given drain functions run after the server stops accepting requests, within the same timeout
+func Shutdown(srv *http.Server, timeout time.Duration, drains ...func(ctx context.Context)) {
 	c := make(chan os.Signal, 1)
 
 	// when there is a interrupt signal, relay it to the channel
@@ -25,6 +26,11 @@ func Shutdown(srv *http.Server, timeout time.Duration) {
 	// wait until the timeout deadline and shutdown the server if there is no connections. if there is no connection shutdown immediately
 	srv.Shutdown(ctx)
 
+	// finish the background work that the handled requests left behind
+	for _, drain := range drains {
+		drain(ctx)
+	}
+
 	log.Println("shutting down the server")
 	os.Exit(0)
 }
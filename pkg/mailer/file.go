@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9@._-]`)
+
+// FileMailer writes emails as .eml files into a directory instead of sending them
+// note that it is meant for development, so that the emails can be opened with a mail client
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(cfg config.MailerConfig) *FileMailer {
+	dir := cfg.Directory
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "mails")
+	}
+	return &FileMailer{dir: dir, from: cfg.From}
+}
+
+// Send writes an email into the directory of the mailer
+func (fm *FileMailer) Send(ctx context.Context, m *Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	now := time.Now()
+	msg, err := build(fm.from, m, now)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(fm.dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102T150405.000000000"), unsafeFileChars.ReplaceAllString(m.To[0], "_"))
+	return os.WriteFile(filepath.Join(fm.dir, name), msg, 0o644)
+}
@@ -0,0 +1,143 @@
+package mailer
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+	msg, err := build("Shop <shop@example.com>", &Message{
+		To:      []string{"jane@example.com"},
+		Subject: "Your order is placed",
+		Text:    "Hello Jane",
+		HTML:    "<p>Hello Jane</p>",
+	}, time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	s := string(msg)
+	assert.True(t, strings.HasPrefix(s, "From: Shop <shop@example.com>\r\nTo: jane@example.com\r\n"))
+	assert.Contains(t, s, "Subject: Your order is placed\r\n")
+	assert.Contains(t, s, "Content-Type: multipart/alternative; boundary=")
+	assert.Contains(t, s, "Content-Type: text/plain; charset=utf-8")
+	assert.Contains(t, s, "<p>Hello Jane</p>")
+}
+
+func TestBuild_NoRecipient(t *testing.T) {
+	_, err := build("shop@example.com", &Message{Subject: "Hello"}, time.Now())
+	assert.Error(t, err)
+}
+
+func TestFileMailer_Send(t *testing.T) {
+	dir := t.TempDir()
+	m, err := New(config.MailerConfig{Driver: DriverFile, From: "shop@example.com", Directory: dir})
+	require.NoError(t, err)
+
+	require.NoError(t, m.Send(context.Background(), &Message{To: []string{"jane@example.com"}, Subject: "Welcome", Text: "Hello"}))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*-jane@example.com.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	content, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "Subject: Welcome")
+}
+
+func TestNew_UnknownDriver(t *testing.T) {
+	_, err := New(config.MailerConfig{Driver: "pigeon"})
+	assert.Error(t, err)
+}
+
+// serveSMTP answers a single smtp conversation on a local listener and records the commands it receives
+func serveSMTP(t *testing.T, hang bool) (string, <-chan []string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	commands := make(chan []string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if hang {
+			time.Sleep(3 * time.Second)
+			return
+		}
+		tp := textproto.NewConn(conn)
+		received := make([]string, 0)
+		tp.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				commands <- received
+				return
+			}
+			received = append(received, line)
+			switch {
+			case strings.HasPrefix(line, "EHLO"):
+				tp.PrintfLine("250 localhost")
+			case line == "DATA":
+				tp.PrintfLine("354 go ahead")
+				if _, err := tp.ReadDotLines(); err != nil {
+					commands <- received
+					return
+				}
+				tp.PrintfLine("250 queued")
+			case line == "QUIT":
+				tp.PrintfLine("221 bye")
+				commands <- received
+				return
+			default:
+				tp.PrintfLine("250 ok")
+			}
+		}
+	}()
+	return l.Addr().String(), commands
+}
+
+func smtpConfig(t *testing.T, addr string) config.MailerConfig {
+	host, port, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return config.MailerConfig{Driver: DriverSMTP, From: "Shop <shop@example.com>", Host: host, Port: portNumber, TimeoutSecs: 1}
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	addr, commands := serveSMTP(t, false)
+	m, err := New(smtpConfig(t, addr))
+	require.NoError(t, err)
+
+	require.NoError(t, m.Send(context.Background(), &Message{To: []string{"jane@example.com"}, Subject: "Welcome", Text: "Hello"}))
+
+	received := <-commands
+	assert.Contains(t, received, "MAIL FROM:<shop@example.com>")
+	assert.Contains(t, received, "RCPT TO:<jane@example.com>")
+}
+
+func TestSMTPMailer_Timeout(t *testing.T) {
+	addr, _ := serveSMTP(t, true)
+	m, err := New(smtpConfig(t, addr))
+	require.NoError(t, err)
+
+	start := time.Now()
+	assert.Error(t, m.Send(context.Background(), &Message{To: []string{"jane@example.com"}, Subject: "Welcome", Text: "Hello"}))
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestNewSMTPMailer_InvalidSender(t *testing.T) {
+	_, err := NewSMTPMailer(config.MailerConfig{From: "Shop <not an address"})
+	assert.Error(t, err)
+}
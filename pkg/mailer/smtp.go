@@ -0,0 +1,124 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+
+	"github.com/cagrikilicoglu/shopping-basket/pkg/config"
+)
+
+// defaultTimeout is used when the timeout of the smtp server is not configured
+const defaultTimeout = 10 * time.Second
+
+// SMTPMailer sends emails through an smtp server
+type SMTPMailer struct {
+	addr    string
+	host    string
+	from    string
+	sender  string
+	auth    smtp.Auth
+	timeout time.Duration
+}
+
+// NewSMTPMailer creates a mailer for the configured smtp server
+// note that the sender may be given with a name such as "Shop <shop@example.com>", which is only kept in the header while the server is given its address
+func NewSMTPMailer(cfg config.MailerConfig) (*SMTPMailer, error) {
+	sender, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("mailer sender %q is not valid: %w", cfg.From, err)
+	}
+	timeout := time.Duration(cfg.TimeoutSecs) * time.Second
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	m := &SMTPMailer{addr: fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		host:    cfg.Host,
+		from:    cfg.From,
+		sender:  sender.Address,
+		timeout: timeout}
+	if cfg.Username != "" {
+		m.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return m, nil
+}
+
+// Send sends an email through the smtp server
+// note that the whole conversation with the server is bounded by the timeout and the deadline of the context, so a hung server cannot block the caller
+func (sm *SMTPMailer) Send(ctx context.Context, m *Message) error {
+	msg, err := build(sm.from, m, time.Now())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sm.timeout)
+	defer cancel()
+	dialer := net.Dialer{Timeout: sm.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", sm.addr)
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+	// closing the connection unblocks the client when the context is canceled before the deadline
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	c, err := smtp.NewClient(conn, sm.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	return sm.send(c, m.To, msg)
+}
+
+// send runs the smtp conversation of a message as smtp.SendMail does
+func (sm *SMTPMailer) send(c *smtp.Client, to []string, msg []byte) error {
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: sm.host}); err != nil {
+			return err
+		}
+	}
+	if sm.auth != nil {
+		if ok, _ := c.Extension("AUTH"); ok {
+			if err := c.Auth(sm.auth); err != nil {
+				return err
+			}
+		}
+	}
+	if err := c.Mail(sm.sender); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
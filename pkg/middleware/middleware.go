@@ -17,6 +17,7 @@ func AdminAuthMiddleware(secretKey string) gin.HandlerFunc {
 				if string(decodedClaims.Roles) == "admin" {
 					userID := decodedClaims.UserId
 					c.Set("userID", userID)
+					c.Set("role", decodedClaims.Roles)
 					c.Next()
 					c.Abort()
 					return
@@ -43,6 +44,7 @@ func UserAuthMiddleware(secretKey string) gin.HandlerFunc {
 				if string(decodedClaims.Roles) == "user" || string(decodedClaims.Roles) == "admin" {
 					userID := decodedClaims.UserId
 					c.Set("userID", userID)
+					c.Set("role", decodedClaims.Roles)
 					c.Next()
 					c.Abort()
 					return
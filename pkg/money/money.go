@@ -0,0 +1,159 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DefaultCurrency is the currency of the amounts that are given without a currency
+var DefaultCurrency = "USD"
+
+// exponents lists the number of minor unit digits of the currencies that do not have two
+var exponents = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+	"BHD": 3,
+	"KWD": 3,
+}
+
+var (
+	ErrCurrencyMismatch = errors.New("Amounts in different currencies cannot be combined")
+	ErrInvalidAmount    = errors.New("Amount is not a valid decimal number")
+)
+
+// Money is an exact amount in the minor units of its currency, such as cents for USD
+type Money struct {
+	Amount   int64  `json:"amount" gorm:"column:amount"`
+	Currency string `json:"currency" gorm:"column:currency;size:3"`
+}
+
+// New creates an amount from minor units
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: normalize(currency)}
+}
+
+// Parse creates an amount from a decimal string in major units such as "12.50"
+// note that more fractional digits than the currency has are rejected instead of being rounded
+func Parse(s, currency string) (Money, error) {
+	currency = normalize(currency)
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, fraction = s[:i], s[i+1:]
+	}
+	exp := Exponent(currency)
+	if whole == "" || len(fraction) > exp || !isDigits(whole) || !isDigits(fraction) {
+		return Money{}, ErrInvalidAmount
+	}
+	fraction += strings.Repeat("0", exp-len(fraction))
+
+	var amount int64
+	for _, r := range whole + fraction {
+		if amount > (math.MaxInt64-9)/10 {
+			return Money{}, ErrInvalidAmount
+		}
+		amount = amount*10 + int64(r-'0')
+	}
+	if negative {
+		amount = -amount
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// FromMajor creates an amount from a floating point number in major units by rounding to the nearest minor unit
+// note that it is only meant for converting legacy or configuration values, calculations must use minor units
+func FromMajor(f float64, currency string) Money {
+	currency = normalize(currency)
+	return Money{Amount: int64(math.Round(f * math.Pow10(Exponent(currency)))), Currency: currency}
+}
+
+// Exponent returns the number of minor unit digits of a currency
+func Exponent(currency string) int {
+	if exp, ok := exponents[currency]; ok {
+		return exp
+	}
+	return 2
+}
+
+// Add returns the sum of two amounts in the same currency
+// note that a zero amount without a currency takes the currency of the other amount
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency == "" && m.Amount == 0 {
+		return o, nil
+	}
+	if o.Currency == "" && o.Amount == 0 {
+		return m, nil
+	}
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns the difference of two amounts in the same currency
+func (m Money) Sub(o Money) (Money, error) {
+	return m.Add(Money{Amount: -o.Amount, Currency: o.Currency})
+}
+
+// Mul returns the amount multiplied by a quantity
+func (m Money) Mul(quantity int64) Money {
+	return Money{Amount: m.Amount * quantity, Currency: m.Currency}
+}
+
+// Percent returns a percentage of the amount given in basis points, so 1500 is 15%
+// the result is rounded half up to the minor unit of the currency
+func (m Money) Percent(basisPoints int64) Money {
+	return Money{Amount: (m.Amount*basisPoints + 5000) / 10000, Currency: m.Currency}
+}
+
+// LessThan checks if the amount is less than another amount in the same currency
+func (m Money) LessThan(o Money) (bool, error) {
+	diff, err := m.Sub(o)
+	if err != nil {
+		return false, err
+	}
+	return diff.Amount < 0, nil
+}
+
+// IsZero checks if the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// String formats the amount in major units with its currency such as "12.50 USD"
+func (m Money) String() string {
+	exp := Exponent(m.Currency)
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	if exp == 0 {
+		return fmt.Sprintf("%s%d %s", sign, amount, m.Currency)
+	}
+	unit := int64(math.Pow10(exp))
+	return fmt.Sprintf("%s%d.%0*d %s", sign, amount/unit, exp, amount%unit, m.Currency)
+}
+
+// normalize returns the upper case currency code or the default currency when it is empty
+func normalize(currency string) string {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return DefaultCurrency
+	}
+	return currency
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
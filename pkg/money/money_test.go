@@ -0,0 +1,95 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in       string
+		currency string
+		want     Money
+		wantErr  bool
+	}{
+		{in: "12.50", currency: "usd", want: Money{Amount: 1250, Currency: "USD"}},
+		{in: "76", currency: "", want: Money{Amount: 7600, Currency: DefaultCurrency}},
+		{in: "0.1", currency: "EUR", want: Money{Amount: 10, Currency: "EUR"}},
+		{in: "-3.05", currency: "EUR", want: Money{Amount: -305, Currency: "EUR"}},
+		{in: "1500", currency: "JPY", want: Money{Amount: 1500, Currency: "JPY"}},
+		{in: "1.005", currency: "USD", wantErr: true},
+		{in: "abc", currency: "USD", wantErr: true},
+		{in: ".5", currency: "USD", wantErr: true},
+		{in: "99999999999999999999", currency: "USD", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in, tt.currency)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFromMajor(t *testing.T) {
+	assert.Equal(t, Money{Amount: 1999, Currency: "USD"}, FromMajor(19.99, "USD"))
+	assert.Equal(t, Money{Amount: 30, Currency: "USD"}, FromMajor(0.1+0.2, "USD"))
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	// summing ten cents ten times is exact, unlike float32
+	var total Money
+	for i := 0; i < 10; i++ {
+		var err error
+		total, err = total.Add(New(10, "USD"))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, New(100, "USD"), total)
+
+	assert.Equal(t, New(3750, "USD"), New(1250, "USD").Mul(3))
+	// 15% of 19.99 is 2.9985
+	assert.Equal(t, New(300, "USD"), New(1999, "USD").Percent(1500))
+
+	_, err := New(100, "USD").Add(New(100, "EUR"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	less, err := New(4999, "USD").LessThan(New(5000, "USD"))
+	require.NoError(t, err)
+	assert.True(t, less)
+}
+
+func TestMoney_String(t *testing.T) {
+	assert.Equal(t, "12.05 USD", New(1205, "USD").String())
+	assert.Equal(t, "-0.50 EUR", New(-50, "EUR").String())
+	assert.Equal(t, "1500 JPY", New(1500, "JPY").String())
+}
+
+func TestParseRate(t *testing.T) {
+	value, err := ParseRate("1.0825")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1082500), value)
+	assert.Equal(t, "1.0825", FormatRate(value))
+	assert.Equal(t, "150", FormatRate(150*RateScale))
+	assert.Equal(t, "0.000001", FormatRate(1))
+	assert.Equal(t, "1.1", FormatRate(1100000))
+
+	for _, in := range []string{"0", "-1", "1.0000001", "abc", ""} {
+		_, err := ParseRate(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestRate_Apply(t *testing.T) {
+	price := New(1999, DefaultCurrency)
+
+	assert.Equal(t, New(2164, "EUR"), NewRate("EUR", 1082500).Apply(price))
+	assert.Equal(t, New(2999, "JPY"), NewRate("JPY", 150*RateScale).Apply(price))
+	assert.Equal(t, New(-2164, "EUR"), NewRate("EUR", 1082500).Apply(New(-1999, DefaultCurrency)))
+	assert.Equal(t, price, Rate{}.Apply(price))
+}
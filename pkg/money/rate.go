@@ -0,0 +1,87 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// RateScale is the fixed point scale of exchange rates, so a rate of 1.5 is kept as 1500000
+const RateScale = 1000000
+
+const rateDigits = 6
+
+var ErrInvalidRate = errors.New("Exchange rate should be a positive decimal number with at most 6 fractional digits")
+
+// Rate converts the amounts in the default currency to another currency
+// Value is the fixed point amount of the currency for one unit of the default currency
+// note that the zero rate leaves the amounts unchanged
+type Rate struct {
+	Currency string
+	Value    int64
+}
+
+// NewRate creates an exchange rate to the given currency
+func NewRate(currency string, value int64) Rate {
+	return Rate{Currency: normalize(currency), Value: value}
+}
+
+// ParseRate parses a decimal exchange rate such as "1.0825" into its fixed point value
+func ParseRate(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	whole, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, fraction = s[:i], s[i+1:]
+	}
+	if whole == "" || len(whole) > 12 || len(fraction) > rateDigits || !isDigits(whole) || !isDigits(fraction) {
+		return 0, ErrInvalidRate
+	}
+	fraction += strings.Repeat("0", rateDigits-len(fraction))
+
+	var value int64
+	for _, r := range whole + fraction {
+		value = value*10 + int64(r-'0')
+	}
+	if value <= 0 {
+		return 0, ErrInvalidRate
+	}
+	return value, nil
+}
+
+// FormatRate formats a fixed point exchange rate as a decimal string
+func FormatRate(value int64) string {
+	fraction := strings.TrimRight(fmt.Sprintf("%0*d", rateDigits, value%RateScale), "0")
+	if fraction == "" {
+		return fmt.Sprintf("%d", value/RateScale)
+	}
+	return fmt.Sprintf("%d.%s", value/RateScale, fraction)
+}
+
+// IsIdentity checks if the rate leaves the amounts unchanged
+func (r Rate) IsIdentity() bool {
+	return r.Value == 0 || r.Currency == "" || r.Currency == DefaultCurrency
+}
+
+// Apply converts an amount in the default currency with the rate
+// the result is rounded half away from zero to the minor unit of the target currency
+func (r Rate) Apply(m Money) Money {
+	if r.IsIdentity() || m.Currency != DefaultCurrency {
+		return m
+	}
+
+	// amount * rate * 10^targetExponent / (RateScale * 10^sourceExponent)
+	num := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(r.Value))
+	num.Mul(num, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(Exponent(r.Currency))), nil))
+	den := new(big.Int).Mul(big.NewInt(RateScale), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(Exponent(m.Currency))), nil))
+
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(den) >= 0 {
+		if num.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	return Money{Amount: quo.Int64(), Currency: r.Currency}
+}
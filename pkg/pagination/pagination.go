@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -11,11 +12,23 @@ import (
 
 var (
 	DefaultPageSize = 10
-	MaxPageSize     = 100
 	PageVar         = "page"
 	PageSizeVar     = "pageSize"
 )
 
+// maxPageSize is read by every paginated request and replaced when the business rules change, so it is accessed atomically
+var maxPageSize int64 = 100
+
+// MaxPageSize returns the largest page size that can be requested
+func MaxPageSize() int {
+	return int(atomic.LoadInt64(&maxPageSize))
+}
+
+// SetMaxPageSize sets the largest page size that can be requested
+func SetMaxPageSize(n int) {
+	atomic.StoreInt64(&maxPageSize, int64(n))
+}
+
 // Pages represents a paginated list of data items.
 type Pages struct {
 	Page       int         `json:"page"`
@@ -29,12 +42,7 @@ type Pages struct {
 func NewFromGinRequest(c *gin.Context, total int, items interface{}) *Pages {
 	pageIndex, pageSize := GetPaginationParametersFromRequest(c)
 
-	if pageSize <= 0 {
-		pageSize = DefaultPageSize
-	}
-	if pageSize > MaxPageSize {
-		pageSize = MaxPageSize
-	}
+	pageSize = ClampPageSize(pageSize)
 	pageCount := -1
 	if total >= 0 {
 		pageCount = (total + pageSize - 1) / pageSize
@@ -50,6 +58,17 @@ func NewFromGinRequest(c *gin.Context, total int, items interface{}) *Pages {
 	return paginatedResult
 }
 
+// ClampPageSize keeps a requested page size between one and the maximum page size
+func ClampPageSize(pageSize int) int {
+	if pageSize <= 0 {
+		return DefaultPageSize
+	}
+	if max := MaxPageSize(); pageSize > max {
+		return max
+	}
+	return pageSize
+}
+
 // GetPaginationParametersFromRequest parses pagination parameters from query
 func GetPaginationParametersFromRequest(c *gin.Context) (pageIndex, pageSize int) {
 	pageIndex = parseInt(c.Query(PageVar), 1)
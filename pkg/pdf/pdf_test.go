@@ -0,0 +1,45 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTo(t *testing.T) {
+	d := New()
+	d.Text(50, 800, 12, true, "Invoice (copy)")
+	d.Line(50, 790, 545, 790)
+	d.AddPage()
+	d.Text(50, 800, 10, false, "Total: 12.50 €")
+
+	var out bytes.Buffer
+	_, err := d.WriteTo(&out)
+	require.NoError(t, err)
+	content := out.String()
+
+	assert.True(t, strings.HasPrefix(content, "%PDF-1.4\n"))
+	assert.True(t, strings.HasSuffix(content, "%%EOF\n"))
+	assert.Contains(t, content, "/Count 2")
+	assert.Contains(t, content, `(Invoice \(copy\)) Tj`)
+	assert.Contains(t, content, "(Total: 12.50 ?) Tj")
+
+	// every entry of the cross reference table points at the start of its object
+	xref := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(content, -1)
+	require.Len(t, xref, 8)
+	for i, entry := range xref {
+		offset, err := strconv.Atoi(entry[1])
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(content[offset:], fmt.Sprintf("%d 0 obj", i+1)))
+	}
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `a\\b \(c\) \374`, escape("a\\b (c) ü"))
+}